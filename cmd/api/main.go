@@ -15,7 +15,6 @@ import (
 	"trade-bot/internal/pkg/service"
 	"trade-bot/internal/pkg/tradeAlgorithm"
 	"trade-bot/internal/pkg/web"
-	"trade-bot/pkg/krakenFuturesSDK"
 	"trade-bot/pkg/krakenFuturesWSSDK"
 
 	"github.com/go-playground/validator/v10"
@@ -27,20 +26,16 @@ import (
 )
 
 var (
-	ErrUnableToInitConfig           = errors.New("unable to init config files")
-	ErrReadConfig                   = errors.New("read config")
-	ErrRunServer                    = errors.New("run server")
-	ErrUnableToConnectToDB          = errors.New("unable to connect to database")
-	ErrUnableToConnectToJWTDB       = errors.New("unable to connect to jwt databased")
-	ErrUnableToLoadEnvVariables     = errors.New("unable to load enviroment variables")
-	ErrCouldNotShutdownServer       = errors.New("could not shut down server normally")
-	ErrCouldNotCloseDBConnection    = errors.New("could not close db connection normally")
-	ErrCouldNotCloseRedisConnection = errors.New("could not close redis connection normally")
-)
-
-const (
-	publicAPIKey  = "PUBLIC_API_KEY"
-	privateAPIKey = "PRIVATE_API_KEY"
+	ErrUnableToInitConfig            = errors.New("unable to init config files")
+	ErrReadConfig                    = errors.New("read config")
+	ErrRunServer                     = errors.New("run server")
+	ErrUnableToConnectToDB           = errors.New("unable to connect to database")
+	ErrUnableToConnectToJWTDB        = errors.New("unable to connect to jwt databased")
+	ErrUnableToLoadEnvVariables      = errors.New("unable to load enviroment variables")
+	ErrCouldNotShutdownServer        = errors.New("could not shut down server normally")
+	ErrCouldNotCloseDBConnection     = errors.New("could not close db connection normally")
+	ErrCouldNotCloseRedisConnection  = errors.New("could not close redis connection normally")
+	ErrUnableToResumeTradingSessions = errors.New("unable to resume trading sessions")
 )
 
 // @title Trade-bot API
@@ -80,11 +75,10 @@ func main() {
 		}
 	}()
 
-	krakenAPI := krakenFuturesSDK.NewAPI(os.Getenv(publicAPIKey), os.Getenv(privateAPIKey), config.Kraken.APIURL)
 	krakenWSAPI := krakenFuturesWSSDK.NewWSAPI(config.KrakenWS)
 
 	repo := repository.NewRepository(db, redisClient)
-	newWeb := web.NewWeb(krakenAPI, krakenWSAPI)
+	newWeb := web.NewWeb(config.Kraken, config.Paper, krakenWSAPI)
 	newTrader := tradeAlgorithm.NewTradeAlgorithm(newWeb)
 
 	validate := validator.New()
@@ -96,9 +90,15 @@ func main() {
 		},
 	}
 
-	services := service.NewService(repo, newWeb, newTrader)
+	services := service.NewService(repo, newWeb, newTrader, config.DeadMansSwitch)
 	handlers := handler.NewHandler(services, validate, &upgrader)
 
+	newWeb.KrakenInstruments.Start()
+	if err := services.TradingSessions.ResumeSessions(); err != nil {
+		log.Errorf("%s: %s", ErrUnableToResumeTradingSessions, err)
+	}
+	services.DeadMansSwitch.Start()
+
 	interrupt := make(chan os.Signal, 1)
 	signal.Notify(interrupt, os.Interrupt)
 
@@ -120,6 +120,11 @@ func main() {
 		log.Panicf("%s: %s", ErrCouldNotShutdownServer, err)
 	}
 
+	// switch is stopped first, so timers of sessions left to be resumed expire and cancel their resting orders
+	services.DeadMansSwitch.Shutdown()
+	services.TradingSessions.Shutdown()
+	newWeb.KrakenInstruments.Shutdown()
+
 	log.Info("Trade bot server shut down")
 }
 
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+
+	"trade-bot/internal/pkg/backtest"
+	"trade-bot/internal/pkg/tradeAlgorithm/types"
+	"trade-bot/pkg/krakenFuturesSDK"
+	"trade-bot/pkg/krakenFuturesWSSDK"
+)
+
+var (
+	ErrUnableToParseParams = errors.New("unable to parse strategy params")
+	ErrUnableToRunBacktest = errors.New("unable to run backtest")
+	ErrUnableToPrintReport = errors.New("unable to print report")
+	ErrUnableToParseTime   = errors.New("unable to parse time of candles range")
+)
+
+const defaultKrakenAPIURL = "https://futures.kraken.com"
+
+func main() {
+	var (
+		dataPath   = flag.String("data", "", "path to .csv or .json file with candles")
+		from       = flag.String("from", "", "RFC3339 start of candles loaded from kraken charts API if -data is not set")
+		to         = flag.String("to", "", "RFC3339 end of candles loaded from kraken, now by default")
+		resolution = flag.String("resolution", "1m", "resolution of candles loaded from kraken")
+		tickType   = flag.String("tick-type", krakenFuturesWSSDK.TradePriceSource, "price of candles loaded from kraken: trade, mark or spot")
+		krakenURL  = flag.String("kraken-url", defaultKrakenAPIURL, "url of kraken futures API")
+		strategy   = flag.String("strategy", "stop_loss_take_profit", "name of registered strategy")
+		params     = flag.String("params", "{}", "strategy params as json object")
+		symbol     = flag.String("symbol", "PI_XBTUSD", "symbol of traded instrument")
+		side       = flag.String("side", "buy", "side of entry orders: buy or sell")
+		jsonOutput = flag.Bool("json", false, "print report as json")
+
+		size     = decimalFlag{value: decimal.NewFromInt(1)}
+		tickSize decimalFlag
+		feeRate  = decimalFlag{value: decimal.RequireFromString("0.0005")}
+		slippage decimalFlag
+		balance  decimalFlag
+	)
+	flag.Var(&size, "size", "size of every order, can be fractional")
+	flag.Var(&tickSize, "tick-size", "tick size of instrument, required for borders in ticks")
+	flag.Var(&feeRate, "fee", "fee rate paid on every fill, fraction of notional")
+	flag.Var(&slippage, "slippage", "slippage of every fill, fraction of price")
+	flag.Var(&balance, "balance", "initial balance used for drawdown percent")
+	flag.Parse()
+
+	if *dataPath == "" && *from == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var strategyParams types.StrategyParams
+	if err := json.Unmarshal([]byte(*params), &strategyParams); err != nil {
+		log.Fatalf("%s: %s", ErrUnableToParseParams, err)
+	}
+
+	var (
+		candles []krakenFuturesWSSDK.Candle
+		err     error
+	)
+	if *dataPath != "" {
+		candles, err = backtest.LoadCandles(*dataPath)
+	} else {
+		candles, err = loadKrakenCandles(*krakenURL, krakenFuturesSDK.CandlesArguments{
+			TickType:   *tickType,
+			Symbol:     *symbol,
+			Resolution: *resolution,
+		}, *from, *to)
+	}
+	if err != nil {
+		log.Fatalf("%s: %s", ErrUnableToRunBacktest, err)
+	}
+
+	report, err := backtest.NewDefaultBacktester().Run(context.Background(), candles, backtest.Config{
+		Strategy:       *strategy,
+		Params:         strategyParams,
+		Symbol:         *symbol,
+		Side:           *side,
+		Size:           size.value,
+		TickSize:       tickSize.value,
+		FeeRate:        feeRate.value,
+		Slippage:       slippage.value,
+		InitialBalance: balance.value,
+	})
+	if err != nil {
+		log.Fatalf("%s: %s", ErrUnableToRunBacktest, err)
+	}
+
+	if *jsonOutput {
+		err = printJSON(report)
+	} else {
+		err = printText(report)
+	}
+	if err != nil {
+		log.Fatalf("%s: %s", ErrUnableToPrintReport, err)
+	}
+}
+
+// decimalFlag is flag of exact decimal value, so prices and sizes aren't rounded by parsing them as floats
+type decimalFlag struct {
+	value decimal.Decimal
+}
+
+func (f *decimalFlag) String() string {
+	return f.value.String()
+}
+
+func (f *decimalFlag) Set(s string) error {
+	value, err := decimal.NewFromString(s)
+	if err != nil {
+		return err
+	}
+	f.value = value
+	return nil
+}
+
+// loadKrakenCandles loads candles of range from kraken charts API
+func loadKrakenCandles(krakenURL string, args krakenFuturesSDK.CandlesArguments, from, to string) (
+	[]krakenFuturesWSSDK.Candle, error) {
+	var err error
+	if args.From, err = time.Parse(time.RFC3339, from); err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrUnableToParseTime, err)
+	}
+	if to != "" {
+		if args.To, err = time.Parse(time.RFC3339, to); err != nil {
+			return nil, fmt.Errorf("%s: %w", ErrUnableToParseTime, err)
+		}
+	}
+
+	return krakenFuturesSDK.NewAPI("", "", krakenURL).Candles(context.Background(), args)
+}
+
+func printJSON(report *backtest.Report) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+func printText(report *backtest.Report) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "ENTRY TIME\tSIDE\tSIZE\tENTRY\tEXIT TIME\tEXIT\tREASON\tFEE\tPNL\t")
+	for _, trade := range report.Trades {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t\n",
+			trade.EntryTime.Format(time.RFC3339), trade.Side, trade.Size, trade.EntryPrice,
+			trade.ExitTime.Format(time.RFC3339), trade.ExitPrice, trade.ExitReason, trade.Fee.StringFixed(4),
+			trade.PnL.StringFixed(4))
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintf(w, "Strategy:\t%s\n", report.Strategy)
+	fmt.Fprintf(w, "Symbol:\t%s\n", report.Symbol)
+	fmt.Fprintf(w, "Trades:\t%d (won %d, lost %d)\n", report.TotalTrades, report.WinningTrades, report.LosingTrades)
+	fmt.Fprintf(w, "Win rate:\t%.2f%%\n", report.WinRate*100)
+	fmt.Fprintf(w, "Fees:\t%s\n", report.TotalFees.StringFixed(4))
+	fmt.Fprintf(w, "PnL:\t%s\n", report.PnL.StringFixed(4))
+	fmt.Fprintf(w, "Final balance:\t%s\n", report.FinalBalance.StringFixed(4))
+	fmt.Fprintf(w, "Max drawdown:\t%s (%.2f%%)\n", report.MaxDrawdown.StringFixed(4), report.MaxDrawdownPercent)
+	fmt.Fprintf(w, "Sharpe (per trade):\t%.4f\n", report.Sharpe)
+
+	return w.Flush()
+}
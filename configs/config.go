@@ -8,6 +8,8 @@ type Configuration struct {
 	RedisDatabase   RedisDatabaseConfiguration
 	Kraken          KrakenConfiguration
 	KrakenWS        KrakenWSConfiguration
+	Paper           PaperTradingConfiguration
+	DeadMansSwitch  DeadMansSwitchConfiguration
 }
 
 type ServerConfiguration struct {
@@ -46,6 +48,19 @@ type RedisDatabaseConfiguration struct {
 
 type KrakenConfiguration struct {
 	APIURL string
+	// InstrumentsRefreshIntervalInSeconds is how often cached catalog of instruments is reloaded
+	InstrumentsRefreshIntervalInSeconds uint
+}
+
+type PaperTradingConfiguration struct {
+	InitialBalance float64
+	FeeRate        float64
+}
+
+// DeadMansSwitchConfiguration sets kraken timer which cancels all orders of user when bot stops refreshing it
+type DeadMansSwitchConfiguration struct {
+	TimeoutInSeconds         uint
+	RefreshIntervalInSeconds uint
 }
 
 type KrakenWSConfiguration struct {
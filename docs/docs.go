@@ -185,6 +185,157 @@ var doc = `{
                 }
             }
         },
+        "/orderManager/batch": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "send, edit and cancel several orders of kraken futures API in one request",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orderManager"
+                ],
+                "summary": "BatchOrder",
+                "operationId": "batchOrder",
+                "parameters": [
+                    {
+                        "description": "batch instructions",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/krakenFuturesSDK.BatchOrderArguments"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orderManager/dead-mans-switch": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "get status of timer which cancels all user's orders on kraken if bot stops refreshing it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orderManager"
+                ],
+                "summary": "DeadMansSwitch",
+                "operationId": "deadMansSwitch",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.DeadMansSwitchStatus"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/orderManager/my-orders": {
             "get": {
                 "security": [
@@ -238,47 +389,585 @@ var doc = `{
                 }
             }
         },
-        "/orderManager/send-order": {
+        "/orderManager/paper/account": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "get virtual balance and positions of user's paper trading account",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orderManager"
+                ],
+                "summary": "PaperAccount",
+                "operationId": "paperAccount",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PaperAccount"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orderManager/paper/send-order": {
             "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "sendOrder to kraken futures API",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "execute order on user's virtual paper trading account at the last market price",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orderManager"
+                ],
+                "summary": "SendPaperOrder",
+                "operationId": "sendPaperOrder",
+                "parameters": [
+                    {
+                        "description": "send order info",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/krakenFuturesSDK.SendOrderArguments"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Order"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orderManager/rate-limit": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "get budget of kraken futures private endpoints which is left for user's api key",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orderManager"
+                ],
+                "summary": "RateLimit",
+                "operationId": "rateLimit",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/krakenFuturesSDK.RateLimitUsage"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orderManager/send-order": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "sendOrder to kraken futures API",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orderManager"
+                ],
+                "summary": "SendOrder",
+                "operationId": "sendOrder",
+                "parameters": [
+                    {
+                        "description": "send order info",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/krakenFuturesSDK.SendOrderArguments"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "order_id",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orderManager/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "get all trading sessions of user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tradingSessions"
+                ],
+                "summary": "MySessions",
+                "operationId": "mySessions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.TradingSession"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "start trading session running in background",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tradingSessions"
+                ],
+                "summary": "StartSession",
+                "operationId": "startSession",
+                "parameters": [
+                    {
+                        "description": "trading details",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.TradingDetails"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.TradingSession"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orderManager/sessions/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "get trading session of user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tradingSessions"
+                ],
+                "summary": "GetSession",
+                "operationId": "getSession",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "session id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.TradingSession"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "stop trading session and close its position",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tradingSessions"
+                ],
+                "summary": "StopSession",
+                "operationId": "stopSession",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "session id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.TradingSession"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    },
+                    "default": {
+                        "description": "",
+                        "schema": {
+                            "$ref": "#/definitions/handler.errResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orderManager/strategies": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "get all strategies available for trading and entry strategies which open position with their params schema",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "orderManager"
-                ],
-                "summary": "SendOrder",
-                "operationId": "sendOrder",
-                "parameters": [
-                    {
-                        "description": "send order info",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/krakenFuturesSDK.SendOrderArguments"
-                        }
-                    }
+                    "strategies"
                 ],
+                "summary": "Strategies",
+                "operationId": "strategies",
                 "responses": {
                     "200": {
-                        "description": "order_id",
-                        "schema": {
-                            "type": "string"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handler.errResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/tradeAlgorithm.Strategy"
+                            }
                         }
                     },
                     "401": {
@@ -293,12 +982,6 @@ var doc = `{
                             "$ref": "#/definitions/handler.errResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/handler.errResponse"
-                        }
-                    },
                     "default": {
                         "description": "",
                         "schema": {
@@ -313,6 +996,13 @@ var doc = `{
         "handler.errResponse": {
             "type": "object",
             "properties": {
+                "code": {
+                    "description": "Code is kraken error code or order status, Kind is its group. Both are set only for errors reported by kraken\nand for orders rejected by local validation",
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string"
+                },
                 "message": {
                     "type": "string"
                 }
@@ -333,6 +1023,85 @@ var doc = `{
                 }
             }
         },
+        "krakenFuturesSDK.BatchInstruction": {
+            "type": "object",
+            "required": [
+                "order"
+            ],
+            "properties": {
+                "cli_order_id": {
+                    "type": "string"
+                },
+                "limit_price": {
+                    "type": "string"
+                },
+                "order": {
+                    "type": "string",
+                    "enum": [
+                        "send",
+                        "edit",
+                        "cancel"
+                    ]
+                },
+                "order_id": {
+                    "type": "string"
+                },
+                "order_tag": {
+                    "type": "string"
+                },
+                "order_type": {
+                    "type": "string"
+                },
+                "reduce_only": {
+                    "type": "boolean"
+                },
+                "side": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "string"
+                },
+                "stop_price": {
+                    "type": "string"
+                },
+                "symbol": {
+                    "type": "string"
+                },
+                "trigger_signal": {
+                    "type": "string"
+                }
+            }
+        },
+        "krakenFuturesSDK.BatchOrderArguments": {
+            "type": "object",
+            "required": [
+                "instructions"
+            ],
+            "properties": {
+                "instructions": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/krakenFuturesSDK.BatchInstruction"
+                    }
+                }
+            }
+        },
+        "krakenFuturesSDK.RateLimitUsage": {
+            "type": "object",
+            "properties": {
+                "available": {
+                    "description": "Available tokens, negative value is debt of queued requests",
+                    "type": "number"
+                },
+                "capacity": {
+                    "type": "number"
+                },
+                "waiting": {
+                    "type": "integer"
+                }
+            }
+        },
         "krakenFuturesSDK.SendOrderArguments": {
             "type": "object",
             "required": [
@@ -346,7 +1115,7 @@ var doc = `{
                     "type": "string"
                 },
                 "limit_price": {
-                    "type": "number"
+                    "type": "string"
                 },
                 "order_type": {
                     "type": "string"
@@ -358,10 +1127,11 @@ var doc = `{
                     "type": "string"
                 },
                 "size": {
-                    "type": "integer"
+                    "description": "Size is number of contracts, it may be fractional if instrument allows it",
+                    "type": "string"
                 },
                 "stop_price": {
-                    "type": "number"
+                    "type": "string"
                 },
                 "symbol": {
                     "type": "string"
@@ -371,14 +1141,45 @@ var doc = `{
                 }
             }
         },
+        "models.DeadMansSwitchStatus": {
+            "type": "object",
+            "properties": {
+                "armed": {
+                    "description": "Armed is true while bot keeps refreshing timer for live trading sessions of user",
+                    "type": "boolean"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "healthy": {
+                    "type": "boolean"
+                },
+                "last_refresh": {
+                    "type": "string"
+                },
+                "timeout_seconds": {
+                    "type": "integer"
+                },
+                "trigger_time": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
         "models.Order": {
             "type": "object",
             "properties": {
                 "client_order_id": {
                     "type": "string"
                 },
+                "exit_reason": {
+                    "description": "ExitReason is set only on order which closed position of trading session",
+                    "type": "string"
+                },
                 "filled": {
-                    "type": "number"
+                    "type": "string"
                 },
                 "id": {
                     "type": "string"
@@ -386,11 +1187,14 @@ var doc = `{
                 "last_update_timestamp": {
                     "type": "string"
                 },
+                "paper": {
+                    "type": "boolean"
+                },
                 "price": {
-                    "type": "number"
+                    "type": "string"
                 },
                 "quantity": {
-                    "type": "number"
+                    "type": "string"
                 },
                 "side": {
                     "type": "string"
@@ -409,6 +1213,92 @@ var doc = `{
                 }
             }
         },
+        "models.PaperAccount": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "type": "string"
+                },
+                "fees": {
+                    "type": "string"
+                },
+                "positions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PaperPosition"
+                    }
+                },
+                "realized_pnl": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.PaperPosition": {
+            "type": "object",
+            "properties": {
+                "entry_price": {
+                    "type": "string"
+                },
+                "mark_price": {
+                    "type": "string"
+                },
+                "size": {
+                    "description": "Size is positive for long and negative for short position",
+                    "type": "string"
+                },
+                "symbol": {
+                    "type": "string"
+                },
+                "unrealized_pnl": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.TradingSession": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "entry_order_id": {
+                    "type": "string"
+                },
+                "entry_price": {
+                    "type": "string"
+                },
+                "entry_time": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "exit_order_id": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "round_trips": {
+                    "description": "RoundTrips is number of positions which were opened and closed by session",
+                    "type": "integer"
+                },
+                "state": {
+                    "type": "string"
+                },
+                "trading_details": {
+                    "$ref": "#/definitions/types.TradingDetails"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
         "models.User": {
             "type": "object",
             "required": [
@@ -435,6 +1325,102 @@ var doc = `{
                     "type": "string"
                 }
             }
+        },
+        "tradeAlgorithm.Strategy": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "params": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.ParamSpec"
+                    }
+                }
+            }
+        },
+        "types.ParamSpec": {
+            "type": "object",
+            "properties": {
+                "default": {},
+                "description": {
+                    "type": "string"
+                },
+                "enum": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "max": {
+                    "type": "number"
+                },
+                "min": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "required": {
+                    "type": "boolean"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "types.StrategyParams": {
+            "type": "object",
+            "additionalProperties": true
+        },
+        "types.TradingDetails": {
+            "type": "object",
+            "required": [
+                "order_type",
+                "side",
+                "size",
+                "strategy",
+                "symbol"
+            ],
+            "properties": {
+                "buyPrice": {
+                    "type": "number"
+                },
+                "entry_params": {
+                    "$ref": "#/definitions/types.StrategyParams"
+                },
+                "entry_strategy": {
+                    "description": "EntryStrategy makes session wait for its signal before every entry and trade round trips until stopped,\nwithout it session enters at once and finishes after the first exit",
+                    "type": "string"
+                },
+                "order_type": {
+                    "type": "string"
+                },
+                "paper": {
+                    "description": "Paper makes session trade on simulated venue instead of kraken",
+                    "type": "boolean"
+                },
+                "params": {
+                    "$ref": "#/definitions/types.StrategyParams"
+                },
+                "side": {
+                    "type": "string"
+                },
+                "size": {
+                    "description": "Size is number of contracts, it may be fractional if instrument allows it",
+                    "type": "string"
+                },
+                "strategy": {
+                    "type": "string"
+                },
+                "symbol": {
+                    "type": "string"
+                }
+            }
         }
     },
     "securityDefinitions": {
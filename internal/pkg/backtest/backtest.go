@@ -0,0 +1,195 @@
+package backtest
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+
+	"trade-bot/internal/pkg/tradeAlgorithm"
+	"trade-bot/internal/pkg/tradeAlgorithm/types"
+	"trade-bot/internal/pkg/web"
+	"trade-bot/pkg/krakenFuturesSDK"
+	"trade-bot/pkg/krakenFuturesWSSDK"
+)
+
+var (
+	ErrRunBacktest     = errors.New("run backtest")
+	ErrInvalidConfig   = errors.New("invalid backtest config")
+	ErrStrategyFailure = errors.New("strategy failure")
+)
+
+const marketOrderType = "mkt"
+
+// ExitReasonEndOfData is reason of position which is closed because candles are over
+const ExitReasonEndOfData types.ExitReason = "end_of_data"
+
+// RegistryBuilder builds strategies registry bound to the given analyzer
+type RegistryBuilder func(analyzer web.KrakenAnalyzer) tradeAlgorithm.Strategies
+
+// Config describes single backtest run
+type Config struct {
+	Strategy string
+	Params   types.StrategyParams
+	Symbol   string
+	Side     string
+	Size     decimal.Decimal
+	// TickSize of the instrument, it is needed only by strategies with borders in ticks
+	TickSize decimal.Decimal
+	// FeeRate is a fraction of fill notional paid on every fill, e.g. 0.0005 for 0.05%
+	FeeRate decimal.Decimal
+	// Slippage is a fraction of price by which every market fill is worse than candle close
+	Slippage decimal.Decimal
+	// InitialBalance is used as starting equity for drawdown and returns calculation
+	InitialBalance decimal.Decimal
+}
+
+func (c Config) validate() error {
+	if c.Strategy == "" {
+		return fmt.Errorf("%s: strategy is required", ErrInvalidConfig)
+	}
+	if c.Side != krakenFuturesSDK.BuySide && c.Side != krakenFuturesSDK.SellSide {
+		return fmt.Errorf("%s: side must be %s or %s", ErrInvalidConfig, krakenFuturesSDK.BuySide, krakenFuturesSDK.SellSide)
+	}
+	if !c.Size.IsPositive() {
+		return fmt.Errorf("%s: size must be positive", ErrInvalidConfig)
+	}
+	if c.FeeRate.IsNegative() || c.Slippage.IsNegative() || c.InitialBalance.IsNegative() {
+		return fmt.Errorf("%s: fee rate, slippage and initial balance can't be negative", ErrInvalidConfig)
+	}
+	return nil
+}
+
+// Backtester replays historical candles through strategies registered in trade algorithm registry
+type Backtester struct {
+	newRegistry RegistryBuilder
+}
+
+func NewBacktester(newRegistry RegistryBuilder) *Backtester {
+	return &Backtester{newRegistry: newRegistry}
+}
+
+// NewDefaultBacktester returns backtester for every strategy supported by trade-bot
+func NewDefaultBacktester() *Backtester {
+	return NewBacktester(func(analyzer web.KrakenAnalyzer) tradeAlgorithm.Strategies {
+		return tradeAlgorithm.NewDefaultRegistry(analyzer)
+	})
+}
+
+// Run opens position by market on candle close, lets strategy decide when to close it
+// and repeats it on the next candle until candles are over. Position which is still open
+// when candles are over is closed on the last candle
+func (b *Backtester) Run(ctx context.Context, candles []krakenFuturesWSSDK.Candle, config Config) (*Report, error) {
+	if err := config.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrRunBacktest, err)
+	}
+	if len(candles) < 2 {
+		return nil, fmt.Errorf("%s: %w", ErrRunBacktest, ErrNotEnoughCandles)
+	}
+
+	analyzer := newReplayAnalyzer(candles)
+	registry := b.newRegistry(analyzer)
+	if err := registry.Validate(config.Strategy, config.Params); err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrRunBacktest, err)
+	}
+
+	var trades []Trade
+	for entry := 0; entry < len(candles)-1; {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("%s: %w", ErrRunBacktest, err)
+		}
+
+		trader, err := registry.NewTrader(config.Strategy, config.Params)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", ErrRunBacktest, err)
+		}
+
+		entryPrice := fillPrice(candles[entry].Close, config.Side, config.Slippage)
+		details := types.TradingDetails{
+			OrderType: marketOrderType,
+			Symbol:    config.Symbol,
+			Side:      config.Side,
+			Size:      config.Size,
+			Strategy:  config.Strategy,
+			Params:    config.Params,
+			BuyPrice:  entryPrice,
+			TickSize:  config.TickSize,
+		}
+
+		analyzer.seek(entry + 1)
+		tradeCtx, cancel := context.WithCancel(ctx)
+		reason, err := trader.StartAnalyzing(tradeCtx, unixTime(candles[entry+1].Time), details)
+		cancel()
+		exit, exhausted := analyzer.wait()
+
+		if err != nil && !exhausted {
+			return nil, fmt.Errorf("%s: %s: %w", ErrRunBacktest, ErrStrategyFailure, err)
+		}
+		if exit <= entry {
+			break
+		}
+
+		if err != nil {
+			reason = ExitReasonEndOfData
+		}
+		trades = append(trades, newTrade(config, candles, entry, exit, entryPrice,
+			fillPrice(candles[exit].Close, oppositeSide(config.Side), config.Slippage), reason))
+
+		if err != nil {
+			break
+		}
+		entry = exit + 1
+	}
+
+	return newReport(config, trades), nil
+}
+
+func newTrade(config Config, candles []krakenFuturesWSSDK.Candle, entry, exit int, entryPrice, exitPrice decimal.Decimal,
+	reason types.ExitReason) Trade {
+	priceChange := exitPrice.Sub(entryPrice)
+	if config.Side == krakenFuturesSDK.SellSide {
+		priceChange = priceChange.Neg()
+	}
+
+	fee := entryPrice.Add(exitPrice).Mul(config.Size).Mul(config.FeeRate)
+	pnl := priceChange.Mul(config.Size).Sub(fee)
+
+	var tradeReturn float64
+	if notional := entryPrice.Mul(config.Size); !notional.IsZero() {
+		tradeReturn = pnl.Div(notional).InexactFloat64()
+	}
+
+	return Trade{
+		Side:       config.Side,
+		Size:       config.Size,
+		EntryTime:  unixTime(candles[entry].Time),
+		EntryPrice: entryPrice,
+		ExitTime:   unixTime(candles[exit].Time),
+		ExitPrice:  exitPrice,
+		Fee:        fee,
+		PnL:        pnl,
+		Return:     tradeReturn,
+		ExitReason: reason,
+	}
+}
+
+// fillPrice returns price of market fill moved against the trader by slippage
+func fillPrice(price decimal.Decimal, side string, slippage decimal.Decimal) decimal.Decimal {
+	if side == krakenFuturesSDK.BuySide {
+		return price.Mul(decimal.NewFromInt(1).Add(slippage))
+	}
+	return price.Mul(decimal.NewFromInt(1).Sub(slippage))
+}
+
+func oppositeSide(side string) string {
+	if side == krakenFuturesSDK.BuySide {
+		return krakenFuturesSDK.SellSide
+	}
+	return krakenFuturesSDK.BuySide
+}
+
+func unixTime(sec int) time.Time {
+	return time.Unix(int64(sec), 0).UTC()
+}
@@ -0,0 +1,157 @@
+package backtest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/internal/pkg/tradeAlgorithm/types"
+	"trade-bot/pkg/krakenFuturesWSSDK"
+)
+
+func testCandles(closes ...float64) []krakenFuturesWSSDK.Candle {
+	candles := make([]krakenFuturesWSSDK.Candle, len(closes))
+	for i, price := range closes {
+		p := decimal.NewFromFloat(price)
+		candles[i] = krakenFuturesWSSDK.Candle{Time: 1650000000 + i*60, Open: p, High: p, Low: p, Close: p, Volume: decimal.NewFromInt(1)}
+	}
+	return candles
+}
+
+func stopLossTakeProfitConfig(side string, stopLoss, takeProfit float64) Config {
+	return Config{
+		Strategy: "stop_loss_take_profit",
+		Params:   types.StrategyParams{"stop_loss_border": stopLoss, "take_profit_border": takeProfit},
+		Symbol:   "PI_XBTUSD",
+		Side:     side,
+		Size:     decimal.NewFromInt(1),
+	}
+}
+
+// assertDecimals compares decimals by value, decimals which are equal can differ by exponent
+func assertDecimals(t *testing.T, want []string, got ...decimal.Decimal) {
+	values := make([]string, len(got))
+	for i, value := range got {
+		values[i] = value.String()
+	}
+	assert.Equal(t, want, values)
+}
+
+func TestBacktester_Run(t *testing.T) {
+	withCosts := stopLossTakeProfitConfig("buy", 3, 5)
+	withCosts.FeeRate = decimal.RequireFromString("0.001")
+	withCosts.Slippage = decimal.RequireFromString("0.01")
+
+	withBalance := stopLossTakeProfitConfig("buy", 3, 5)
+	withBalance.InitialBalance = decimal.NewFromInt(100)
+
+	fractional := stopLossTakeProfitConfig("buy", 3, 5)
+	fractional.Size = decimal.RequireFromString("0.001")
+	fractional.FeeRate = decimal.RequireFromString("0.0005")
+
+	tests := []struct {
+		name    string
+		candles []krakenFuturesWSSDK.Candle
+		config  Config
+		check   func(t *testing.T, report *Report)
+		wantErr bool
+	}{
+		{
+			name:    "Take profit, stop loss and end of data",
+			candles: testCandles(100, 101, 106, 104, 103, 96, 97, 99),
+			config:  withBalance,
+			check: func(t *testing.T, report *Report) {
+				assert.Equal(t, 3, report.TotalTrades)
+				assertDecimals(t, []string{"100", "104", "97"}, report.Trades[0].EntryPrice, report.Trades[1].EntryPrice, report.Trades[2].EntryPrice)
+				assertDecimals(t, []string{"106", "96", "99"}, report.Trades[0].ExitPrice, report.Trades[1].ExitPrice, report.Trades[2].ExitPrice)
+				assert.Equal(t, types.ExitReasonTakeProfit, report.Trades[0].ExitReason)
+				assert.Equal(t, types.ExitReasonStopLoss, report.Trades[1].ExitReason)
+				assert.Equal(t, ExitReasonEndOfData, report.Trades[2].ExitReason)
+				assert.Equal(t, 2, report.WinningTrades)
+				assert.Equal(t, 1, report.LosingTrades)
+				assert.InDelta(t, 2.0/3, report.WinRate, 1e-9)
+				assertDecimals(t, []string{"0", "100", "8"}, report.PnL, report.FinalBalance, report.MaxDrawdown)
+				assert.InDelta(t, 7.547169811, report.MaxDrawdownPercent, 1e-6)
+				assert.InDelta(t, 0.017475150, report.Sharpe, 1e-6)
+			},
+		},
+		{
+			name:    "Fees and slippage",
+			candles: testCandles(100, 120),
+			config:  withCosts,
+			check: func(t *testing.T, report *Report) {
+				assert.Equal(t, 1, report.TotalTrades)
+				assertDecimals(t, []string{"101", "118.8", "0.2198", "17.5802"},
+					report.Trades[0].EntryPrice, report.Trades[0].ExitPrice, report.TotalFees, report.PnL)
+				assert.Equal(t, 0.0, report.Sharpe)
+			},
+		},
+		{
+			name:    "Fractional size",
+			candles: testCandles(40123.5, 40130.25, 42150.75),
+			config:  fractional,
+			check: func(t *testing.T, report *Report) {
+				assert.Equal(t, 1, report.TotalTrades)
+				// take profit is hit on the second candle, fee is (40123.5 + 40130.25) * 0.001 * 0.0005
+				assertDecimals(t, []string{"0.001", "0.040126875", "-0.033376875"},
+					report.Trades[0].Size, report.TotalFees, report.PnL)
+			},
+		},
+		{
+			name:    "Sell side",
+			candles: testCandles(100, 98, 94, 96, 99, 100),
+			config:  stopLossTakeProfitConfig("sell", 3, 5),
+			check: func(t *testing.T, report *Report) {
+				assert.Equal(t, 2, report.TotalTrades)
+				assert.Equal(t, types.ExitReasonTakeProfit, report.Trades[0].ExitReason)
+				assert.Equal(t, types.ExitReasonStopLoss, report.Trades[1].ExitReason)
+				assertDecimals(t, []string{"3"}, report.PnL)
+				assert.Equal(t, 0.5, report.WinRate)
+			},
+		},
+		{
+			name:    "Not enough candles",
+			candles: testCandles(100),
+			config:  stopLossTakeProfitConfig("buy", 3, 5),
+			wantErr: true,
+		},
+		{
+			name:    "Unknown strategy",
+			candles: testCandles(100, 101),
+			config:  Config{Strategy: "unknown", Side: "buy", Size: decimal.NewFromInt(1)},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid params",
+			candles: testCandles(100, 101),
+			config:  stopLossTakeProfitConfig("buy", -1, 5),
+			wantErr: true,
+		},
+		{
+			name:    "Zero size",
+			candles: testCandles(100, 101),
+			config:  Config{Strategy: "stop_loss_take_profit", Side: "buy"},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid side",
+			candles: testCandles(100, 101),
+			config:  stopLossTakeProfitConfig("long", 3, 5),
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			report, err := NewDefaultBacktester().Run(context.Background(), test.candles, test.config)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			test.check(t, report)
+		})
+	}
+}
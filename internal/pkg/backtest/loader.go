@@ -0,0 +1,132 @@
+package backtest
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+
+	"trade-bot/pkg/krakenFuturesWSSDK"
+)
+
+var (
+	ErrLoadCandles         = errors.New("load candles")
+	ErrUnsupportedFormat   = errors.New("unsupported candles file format")
+	ErrInvalidCSVHeader    = errors.New("invalid csv header")
+	ErrInvalidCSVRecord    = errors.New("invalid csv record")
+	ErrNotEnoughCandles    = errors.New("not enough candles")
+	ErrCandlesAreNotSorted = errors.New("candles are not sorted by time")
+)
+
+const unixTimeLen = 10
+
+var csvHeader = []string{"time", "open", "high", "low", "close", "volume"}
+
+// LoadCandles reads candles from .csv or .json file. CSV file must have header
+// time,open,high,low,close,volume and JSON file must contain array of candles
+func LoadCandles(path string) ([]krakenFuturesWSSDK.Candle, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrLoadCandles, err)
+	}
+	defer file.Close()
+
+	var candles []krakenFuturesWSSDK.Candle
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		candles, err = ReadCSVCandles(file)
+	case ".json":
+		candles, err = ReadJSONCandles(file)
+	default:
+		err = fmt.Errorf("%s: %s", ErrUnsupportedFormat, filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrLoadCandles, err)
+	}
+
+	return candles, nil
+}
+
+func ReadCSVCandles(r io.Reader) ([]krakenFuturesWSSDK.Candle, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = len(csvHeader)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	for i, column := range csvHeader {
+		if strings.ToLower(header[i]) != column {
+			return nil, fmt.Errorf("%s: expected %s", ErrInvalidCSVHeader, strings.Join(csvHeader, ","))
+		}
+	}
+
+	var candles []krakenFuturesWSSDK.Candle
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		candleTime, err := strconv.ParseInt(record[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: time: %w", ErrInvalidCSVRecord, err)
+		}
+		volume, err := decimal.NewFromString(record[5])
+		if err != nil {
+			return nil, fmt.Errorf("%s: volume: %w", ErrInvalidCSVRecord, err)
+		}
+		prices := make([]decimal.Decimal, 4)
+		for i, value := range record[1:5] {
+			if prices[i], err = decimal.NewFromString(value); err != nil {
+				return nil, fmt.Errorf("%s: price: %w", ErrInvalidCSVRecord, err)
+			}
+		}
+
+		candles = append(candles, krakenFuturesWSSDK.Candle{
+			Time:   int(candleTime),
+			Open:   prices[0],
+			High:   prices[1],
+			Low:    prices[2],
+			Close:  prices[3],
+			Volume: volume,
+		})
+	}
+
+	return normalizeCandles(candles)
+}
+
+func ReadJSONCandles(r io.Reader) ([]krakenFuturesWSSDK.Candle, error) {
+	var candles []krakenFuturesWSSDK.Candle
+	if err := json.NewDecoder(r).Decode(&candles); err != nil {
+		return nil, err
+	}
+	return normalizeCandles(candles)
+}
+
+// normalizeCandles converts milliseconds timestamps to seconds like live feed does
+// and checks that candles go one after another
+func normalizeCandles(candles []krakenFuturesWSSDK.Candle) ([]krakenFuturesWSSDK.Candle, error) {
+	for i := range candles {
+		for len(strconv.Itoa(candles[i].Time)) > unixTimeLen {
+			candles[i].Time /= 10
+		}
+	}
+
+	if !sort.SliceIsSorted(candles, func(i, j int) bool { return candles[i].Time < candles[j].Time }) {
+		return nil, ErrCandlesAreNotSorted
+	}
+	return candles, nil
+}
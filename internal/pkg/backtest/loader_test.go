@@ -0,0 +1,52 @@
+package backtest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/pkg/krakenFuturesWSSDK"
+)
+
+func TestReadCSVCandles(t *testing.T) {
+	d := decimal.RequireFromString
+	tests := []struct {
+		name    string
+		input   string
+		want    []krakenFuturesWSSDK.Candle
+		wantErr bool
+	}{
+		{
+			name:  "OK",
+			input: "time,open,high,low,close,volume\n1650000000000,100,110,90,105,12\n1650000060,105,106,104,104.5,3\n",
+			want: []krakenFuturesWSSDK.Candle{
+				{Time: 1650000000, Open: d("100"), High: d("110"), Low: d("90"), Close: d("105"), Volume: decimal.RequireFromString("12")},
+				{Time: 1650000060, Open: d("105"), High: d("106"), Low: d("104"), Close: d("104.5"), Volume: decimal.RequireFromString("3")},
+			},
+		},
+		{name: "Invalid header", input: "t,o,h,l,c,v\n1650000000,100,110,90,105,12\n", wantErr: true},
+		{name: "Invalid price", input: "time,open,high,low,close,volume\n1650000000,100,110,90,abc,12\n", wantErr: true},
+		{name: "Not sorted", input: "time,open,high,low,close,volume\n1650000060,1,1,1,1,1\n1650000000,1,1,1,1,1\n", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			candles, err := ReadCSVCandles(strings.NewReader(test.input))
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, candles)
+		})
+	}
+}
+
+func TestReadJSONCandles(t *testing.T) {
+	d := decimal.RequireFromString
+	candles, err := ReadJSONCandles(strings.NewReader(`[{"time":1650000000,"open":"1","high":"2","low":"0.5","close":"1.5","volume":7}]`))
+	assert.NoError(t, err)
+	assert.Equal(t, []krakenFuturesWSSDK.Candle{{Time: 1650000000, Open: d("1"), High: d("2"), Low: d("0.5"), Close: d("1.5"), Volume: decimal.RequireFromString("7")}}, candles)
+}
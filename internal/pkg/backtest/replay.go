@@ -0,0 +1,81 @@
+package backtest
+
+import (
+	"context"
+	"sync"
+
+	"trade-bot/pkg/krakenFuturesWSSDK"
+)
+
+// replayAnalyzer implements web.KrakenAnalyzer by replaying historical candles instead of kraken websocket feed
+type replayAnalyzer struct {
+	candles []krakenFuturesWSSDK.Candle
+
+	mu        sync.Mutex
+	start     int
+	last      int
+	exhausted bool
+	done      chan struct{}
+}
+
+func newReplayAnalyzer(candles []krakenFuturesWSSDK.Candle) *replayAnalyzer {
+	return &replayAnalyzer{candles: candles}
+}
+
+// seek makes next LookForCandles call replay candles beginning from index
+func (r *replayAnalyzer) seek(index int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.start = index
+	r.last = index - 1
+	r.exhausted = false
+	r.done = nil
+}
+
+func (r *replayAnalyzer) LookForCandles(ctx context.Context, _ string, _ []string) (<-chan krakenFuturesWSSDK.Candle, error) {
+	candlesCh := make(chan krakenFuturesWSSDK.Candle)
+
+	r.mu.Lock()
+	start := r.start
+	done := make(chan struct{})
+	r.done = done
+	r.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		defer close(candlesCh)
+
+		for i := start; i < len(r.candles); i++ {
+			select {
+			case candlesCh <- r.candles[i]:
+				r.mu.Lock()
+				r.last = i
+				r.mu.Unlock()
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		r.mu.Lock()
+		r.exhausted = true
+		r.mu.Unlock()
+	}()
+
+	return candlesCh, nil
+}
+
+// wait blocks until replay started by LookForCandles is stopped and returns index of the last delivered candle
+func (r *replayAnalyzer) wait() (last int, exhausted bool) {
+	r.mu.Lock()
+	done := r.done
+	r.mu.Unlock()
+
+	if done != nil {
+		<-done
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.last, r.exhausted
+}
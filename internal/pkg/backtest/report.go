@@ -0,0 +1,114 @@
+package backtest
+
+import (
+	"math"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"trade-bot/internal/pkg/tradeAlgorithm/types"
+)
+
+// Trade is a single closed round trip made by strategy
+type Trade struct {
+	Side       string          `json:"side"`
+	Size       decimal.Decimal `json:"size"`
+	EntryTime  time.Time       `json:"entry_time"`
+	EntryPrice decimal.Decimal `json:"entry_price"`
+	ExitTime   time.Time       `json:"exit_time"`
+	ExitPrice  decimal.Decimal `json:"exit_price"`
+	Fee        decimal.Decimal `json:"fee"`
+	PnL        decimal.Decimal `json:"pnl"`
+	// Return is PnL relative to entry notional, it is float because it is used only for statistics
+	Return     float64          `json:"return"`
+	ExitReason types.ExitReason `json:"exit_reason"`
+}
+
+type Report struct {
+	Strategy       string          `json:"strategy"`
+	Symbol         string          `json:"symbol"`
+	Trades         []Trade         `json:"trades"`
+	TotalTrades    int             `json:"total_trades"`
+	WinningTrades  int             `json:"winning_trades"`
+	LosingTrades   int             `json:"losing_trades"`
+	WinRate        float64         `json:"win_rate"`
+	TotalFees      decimal.Decimal `json:"total_fees"`
+	PnL            decimal.Decimal `json:"pnl"`
+	InitialBalance decimal.Decimal `json:"initial_balance"`
+	FinalBalance   decimal.Decimal `json:"final_balance"`
+	// MaxDrawdown is the largest drop of equity from its peak in quote currency
+	MaxDrawdown decimal.Decimal `json:"max_drawdown"`
+	// MaxDrawdownPercent is MaxDrawdown relative to the peak, it is zero when initial balance isn't set
+	MaxDrawdownPercent float64 `json:"max_drawdown_percent"`
+	// Sharpe is per trade sharpe ratio of trade returns with zero risk free rate, it isn't annualized
+	Sharpe float64 `json:"sharpe"`
+}
+
+func newReport(config Config, trades []Trade) *Report {
+	report := &Report{
+		Strategy:       config.Strategy,
+		Symbol:         config.Symbol,
+		Trades:         trades,
+		TotalTrades:    len(trades),
+		InitialBalance: config.InitialBalance,
+	}
+	if report.Trades == nil {
+		report.Trades = []Trade{}
+	}
+
+	equity, peak := config.InitialBalance, config.InitialBalance
+	returns := make([]float64, 0, len(trades))
+	for _, trade := range trades {
+		switch {
+		case trade.PnL.IsPositive():
+			report.WinningTrades++
+		case trade.PnL.IsNegative():
+			report.LosingTrades++
+		}
+		report.TotalFees = report.TotalFees.Add(trade.Fee)
+		report.PnL = report.PnL.Add(trade.PnL)
+		returns = append(returns, trade.Return)
+
+		equity = equity.Add(trade.PnL)
+		if equity.GreaterThan(peak) {
+			peak = equity
+		}
+		if drawdown := peak.Sub(equity); drawdown.GreaterThan(report.MaxDrawdown) {
+			report.MaxDrawdown = drawdown
+			if peak.IsPositive() {
+				report.MaxDrawdownPercent = drawdown.Div(peak).Mul(decimal.NewFromInt(100)).InexactFloat64()
+			}
+		}
+	}
+
+	report.FinalBalance = equity
+	if report.TotalTrades > 0 {
+		report.WinRate = float64(report.WinningTrades) / float64(report.TotalTrades)
+	}
+	report.Sharpe = sharpe(returns)
+
+	return report
+}
+
+func sharpe(returns []float64) float64 {
+	if len(returns) < 2 {
+		return 0
+	}
+
+	var mean float64
+	for _, r := range returns {
+		mean += r
+	}
+	mean /= float64(len(returns))
+
+	var variance float64
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+	std := math.Sqrt(variance / float64(len(returns)-1))
+	if std == 0 {
+		return 0
+	}
+
+	return mean / std
+}
@@ -1,15 +1,67 @@
 package handler
 
 import (
+	"net/http"
+
 	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
+
+	"trade-bot/pkg/krakenFuturesSDK"
 )
 
 type errResponse struct {
 	Message string `json:"message"`
+	// Code is kraken error code or order status, Kind is its group. Both are set only for errors reported by kraken
+	// and for orders rejected by local validation
+	Code string                     `json:"code,omitempty"`
+	Kind krakenFuturesSDK.ErrorKind `json:"kind,omitempty"`
 }
 
 func newErrorResponse(c *gin.Context, statusCode int, message string) {
 	log.Error(message)
 	c.AbortWithStatusJSON(statusCode, errResponse{Message: message})
 }
+
+var krakenErrorStatusCodes = map[krakenFuturesSDK.ErrorKind]int{
+	krakenFuturesSDK.KindTransient:         http.StatusServiceUnavailable,
+	krakenFuturesSDK.KindRateLimited:       http.StatusTooManyRequests,
+	krakenFuturesSDK.KindAuthentication:    http.StatusForbidden,
+	krakenFuturesSDK.KindInsufficientFunds: http.StatusUnprocessableEntity,
+	krakenFuturesSDK.KindInvalidArgument:   http.StatusBadRequest,
+	krakenFuturesSDK.KindMarketClosed:      http.StatusConflict,
+	krakenFuturesSDK.KindNotFound:          http.StatusNotFound,
+	krakenFuturesSDK.KindRejected:          http.StatusUnprocessableEntity,
+	krakenFuturesSDK.KindUnknown:           http.StatusBadGateway,
+}
+
+// newServiceErrorResponse responds with status code and error code matching kraken error or local order validation
+// error wrapped by err, other errors are responded with fallback status code
+func newServiceErrorResponse(c *gin.Context, fallbackStatusCode int, err error) {
+	statusCode, response := serviceErrResponse(fallbackStatusCode, err)
+	log.Error(err.Error())
+	c.AbortWithStatusJSON(statusCode, response)
+}
+
+func serviceErrResponse(fallbackStatusCode int, err error) (int, errResponse) {
+	var (
+		apiErr        *krakenFuturesSDK.APIError
+		validationErr *krakenFuturesSDK.ValidationError
+		code          string
+		kind          krakenFuturesSDK.ErrorKind
+	)
+	switch {
+	case errors.As(err, &apiErr):
+		code, kind = apiErr.Code, apiErr.Kind()
+	case errors.As(err, &validationErr):
+		code, kind = validationErr.Code, validationErr.Kind()
+	default:
+		return fallbackStatusCode, errResponse{Message: err.Error()}
+	}
+
+	return krakenErrorStatusCodes[kind], errResponse{
+		Message: err.Error(),
+		Code:    code,
+		Kind:    kind,
+	}
+}
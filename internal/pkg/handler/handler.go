@@ -18,6 +18,7 @@ type Handler struct {
 }
 
 func NewHandler(services *service.Service, validate *validator.Validate, wsUpgrader *websocket.Upgrader) *Handler {
+	registerDecimalType(validate)
 	return &Handler{services: services, validate: validate, wsUpgrader: wsUpgrader}
 }
 
@@ -38,8 +39,27 @@ func (h *Handler) InitRoutes() *gin.Engine {
 	orderManager := router.Group("/orderManager", h.userIdentity)
 	{
 		orderManager.POST("send-order", h.sendOrder)
+		orderManager.POST("batch", h.batchOrder)
+		orderManager.GET("dead-mans-switch", h.deadMansSwitch)
+		orderManager.GET("rate-limit", h.rateLimit)
 		orderManager.GET("ws/start-trade", h.startTrade)
 		orderManager.GET("my-orders", h.myOrders)
+		orderManager.GET("strategies", h.strategies)
+
+		paper := orderManager.Group("/paper")
+		{
+			paper.POST("send-order", h.sendPaperOrder)
+			paper.GET("account", h.paperAccount)
+		}
+
+		sessions := orderManager.Group("/sessions")
+		{
+			sessions.POST("", h.startSession)
+			sessions.GET("", h.mySessions)
+			sessions.GET(":id", h.getSession)
+			sessions.DELETE(":id", h.stopSession)
+			sessions.GET(":id/ws", h.watchTrade)
+		}
 	}
 
 	return router
@@ -1,12 +1,10 @@
 package handler
 
 import (
-	"context"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 
-	"trade-bot/internal/pkg/tradeAlgorithm/types"
 	"trade-bot/pkg/krakenFuturesSDK"
 )
 
@@ -19,8 +17,8 @@ import (
 // @Produce  json
 // @Param input body krakenFuturesSDK.SendOrderArguments true "send order info"
 // @Success 200 {string} string "order_id"
-// @Failure 400,401,404 {object} errResponse
-// @Failure 500 {object} errResponse
+// @Failure 400,401,403,404,409,422,429 {object} errResponse
+// @Failure 500,502,503 {object} errResponse
 // @Failure default {object} errResponse
 // @Router /orderManager/send-order [post]
 func (h *Handler) sendOrder(c *gin.Context) {
@@ -37,119 +35,182 @@ func (h *Handler) sendOrder(c *gin.Context) {
 		return
 	}
 
-	order, err := h.services.KrakenOrdersManager.SendOrder(userID, input)
+	order, err := h.services.KrakenOrdersManager.SendOrder(c.Request.Context(), userID, input)
 	if err != nil {
-		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		newServiceErrorResponse(c, http.StatusInternalServerError, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, order)
 }
 
-type tradingDetails struct {
-	Event          string               `json:"event"`
-	TradingDetails types.TradingDetails `json:"trading_details,omitempty"`
-}
+// @Summary BatchOrder
+// @Security ApiKeyAuth
+// @Tags orderManager
+// @Description send, edit and cancel several orders of kraken futures API in one request
+// @ID batchOrder
+// @Accept  json
+// @Produce  json
+// @Param input body krakenFuturesSDK.BatchOrderArguments true "batch instructions"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400,401,403,404,409,422,429 {object} errResponse
+// @Failure 500,502,503 {object} errResponse
+// @Failure default {object} errResponse
+// @Router /orderManager/batch [post]
+func (h *Handler) batchOrder(c *gin.Context) {
+	var input krakenFuturesSDK.BatchOrderArguments
 
-const cancelEvent = "cancel_trading"
-const startTrading = "start_trading"
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
 
-func (h *Handler) startTrade(c *gin.Context) {
-	conn, err := h.wsUpgrader.Upgrade(c.Writer, c.Request, nil)
+	userID, err := getUserID(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	statuses, orders, err := h.services.KrakenOrdersManager.BatchOrder(c.Request.Context(), userID, input)
 	if err != nil {
-		newErrorResponse(c, http.StatusForbidden, err.Error())
+		newServiceErrorResponse(c, http.StatusInternalServerError, err)
 		return
 	}
-	defer conn.Close()
 
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"batch_status": statuses,
+		"orders":       orders,
+	})
+}
+
+// @Summary MyOrders
+// @Security ApiKeyAuth
+// @Tags orderManager
+// @Description get all orders of user
+// @ID myOrders
+// @Produce  json
+// @Success 200 {object} []models.Order
+// @Failure 401,404 {object} errResponse
+// @Failure 500 {object} errResponse
+// @Failure default {object} errResponse
+// @Router /orderManager/my-orders [get]
+func (h *Handler) myOrders(c *gin.Context) {
 	userID, err := getUserID(c)
 	if err != nil {
-		newWebsocketErrResponse(c, http.StatusUnauthorized, conn, err.Error())
+		newErrorResponse(c, http.StatusUnauthorized, err.Error())
 		return
 	}
 
-	var input tradingDetails
-	if err := conn.ReadJSON(&input); err != nil {
-		newWebsocketErrResponse(c, http.StatusInternalServerError, conn, err.Error())
+	orders, err := h.services.KrakenOrdersManager.GetUserOrders(userID)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
 		return
 	}
-	if err := h.validate.Struct(input); err != nil {
-		newWebsocketErrResponse(c, http.StatusBadRequest, conn, err.Error())
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"orders": orders,
+	})
+}
+
+// @Summary SendPaperOrder
+// @Security ApiKeyAuth
+// @Tags orderManager
+// @Description execute order on user's virtual paper trading account at the last market price
+// @ID sendPaperOrder
+// @Accept  json
+// @Produce  json
+// @Param input body krakenFuturesSDK.SendOrderArguments true "send order info"
+// @Success 200 {object} models.Order
+// @Failure 400,401,404,409 {object} errResponse
+// @Failure 500 {object} errResponse
+// @Failure default {object} errResponse
+// @Router /orderManager/paper/send-order [post]
+func (h *Handler) sendPaperOrder(c *gin.Context) {
+	var input krakenFuturesSDK.SendOrderArguments
+
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
 		return
 	}
-	if input.Event != startTrading {
-		newWebsocketErrResponse(c, http.StatusBadRequest, conn, err.Error())
+
+	userID, err := getUserID(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnauthorized, err.Error())
 		return
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
-	var isCancelled bool
-	defer cancel()
-
-	go func() {
-		defer cancel()
-
-		var tradingDetails tradingDetails
-		for {
-			if err := conn.ReadJSON(&tradingDetails); err != nil {
-				return
-			}
-			if tradingDetails.Event == cancelEvent {
-				isCancelled = true
-				return
-			}
-		}
-	}()
-
-	order, err := h.services.KrakenOrdersManager.StartTrading(ctx, userID, input.TradingDetails)
-	if err != nil && !isCancelled {
-		newWebsocketErrResponse(c, http.StatusInternalServerError, conn, err.Error())
+	order, err := h.services.KrakenOrdersManager.SendPaperOrder(c.Request.Context(), userID, input)
+	if err != nil {
+		newServiceErrorResponse(c, http.StatusInternalServerError, err)
 		return
 	}
 
-	if isCancelled {
-		err := conn.WriteJSON(struct {
-			Message string `json:"message"`
-		}{Message: "trading have been canceled"})
+	c.JSON(http.StatusOK, order)
+}
 
-		if err != nil {
-			newWebsocketErrResponse(c, http.StatusInternalServerError, conn, err.Error())
-			return
-		}
+// @Summary PaperAccount
+// @Security ApiKeyAuth
+// @Tags orderManager
+// @Description get virtual balance and positions of user's paper trading account
+// @ID paperAccount
+// @Produce  json
+// @Success 200 {object} models.PaperAccount
+// @Failure 401,404 {object} errResponse
+// @Failure default {object} errResponse
+// @Router /orderManager/paper/account [get]
+func (h *Handler) paperAccount(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnauthorized, err.Error())
 		return
 	}
 
-	if err := conn.WriteJSON(order); err != nil {
-		newWebsocketErrResponse(c, http.StatusInternalServerError, conn, err.Error())
+	c.JSON(http.StatusOK, h.services.KrakenOrdersManager.GetPaperAccount(userID))
+}
+
+// @Summary DeadMansSwitch
+// @Security ApiKeyAuth
+// @Tags orderManager
+// @Description get status of timer which cancels all user's orders on kraken if bot stops refreshing it
+// @ID deadMansSwitch
+// @Produce  json
+// @Success 200 {object} models.DeadMansSwitchStatus
+// @Failure 401,404 {object} errResponse
+// @Failure default {object} errResponse
+// @Router /orderManager/dead-mans-switch [get]
+func (h *Handler) deadMansSwitch(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnauthorized, err.Error())
 		return
 	}
+
+	c.JSON(http.StatusOK, h.services.DeadMansSwitch.GetDeadMansSwitchStatus(userID))
 }
 
-// @Summary MyOrders
+// @Summary RateLimit
 // @Security ApiKeyAuth
 // @Tags orderManager
-// @Description get all orders of user
-// @ID myOrders
+// @Description get budget of kraken futures private endpoints which is left for user's api key
+// @ID rateLimit
 // @Produce  json
-// @Success 200 {object} []models.Order
+// @Success 200 {object} krakenFuturesSDK.RateLimitUsage
 // @Failure 401,404 {object} errResponse
 // @Failure 500 {object} errResponse
 // @Failure default {object} errResponse
-// @Router /orderManager/my-orders [get]
-func (h *Handler) myOrders(c *gin.Context) {
+// @Router /orderManager/rate-limit [get]
+func (h *Handler) rateLimit(c *gin.Context) {
 	userID, err := getUserID(c)
 	if err != nil {
 		newErrorResponse(c, http.StatusUnauthorized, err.Error())
 		return
 	}
 
-	orders, err := h.services.KrakenOrdersManager.GetUserOrders(userID)
+	usage, err := h.services.KrakenOrdersManager.GetRateLimitUsage(userID)
 	if err != nil {
 		newErrorResponse(c, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	c.JSON(http.StatusOK, map[string]interface{}{
-		"orders": orders,
-	})
+	c.JSON(http.StatusOK, usage)
 }
@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/internal/pkg/models"
+	"trade-bot/internal/pkg/service"
+	mockService "trade-bot/internal/pkg/service/mocks"
+	"trade-bot/internal/pkg/web/webKraken"
+	"trade-bot/pkg/krakenFuturesSDK"
+)
+
+func TestHandler_sendOrder(t *testing.T) {
+	args := krakenFuturesSDK.SendOrderArguments{OrderType: "mkt", Symbol: "pi_xbtusd", Side: "buy", Size: decimal.NewFromInt(1)}
+	body := `{"order_type":"mkt","symbol":"pi_xbtusd","side":"buy","size":1}`
+
+	type mockBehaviour func(s *mockService.MockKrakenOrdersManager, userID int)
+
+	tests := []struct {
+		name                string
+		mockBehaviour       mockBehaviour
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name: "OK",
+			mockBehaviour: func(s *mockService.MockKrakenOrdersManager, userID int) {
+				s.EXPECT().SendOrder(gomock.Any(), userID, args).Return(models.Order{ID: "1", UserID: userID}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name: "Insufficient funds",
+			mockBehaviour: func(s *mockService.MockKrakenOrdersManager, userID int) {
+				err := fmt.Errorf("%s: %w", webKraken.ErrSendOrder, krakenFuturesSDK.NewStatusError("insufficientAvailableFunds"))
+				s.EXPECT().SendOrder(gomock.Any(), userID, args).Return(models.Order{}, err)
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			expectedRequestBody: `{"message":"web sdk: send order: kraken: invalid status: insufficientAvailableFunds",` +
+				`"code":"insufficientAvailableFunds","kind":"insufficient_funds"}`,
+		},
+		{
+			name: "Rate limited",
+			mockBehaviour: func(s *mockService.MockKrakenOrdersManager, userID int) {
+				err := &krakenFuturesSDK.APIError{Code: "apiLimitExceeded", HTTPStatus: http.StatusTooManyRequests}
+				s.EXPECT().SendOrder(gomock.Any(), userID, args).Return(models.Order{}, fmt.Errorf("%s: %w", webKraken.ErrSendOrder, err))
+			},
+			expectedStatusCode: http.StatusTooManyRequests,
+			expectedRequestBody: `{"message":"web sdk: send order: kraken: apiLimitExceeded: http status 429",` +
+				`"code":"apiLimitExceeded","kind":"rate_limited"}`,
+		},
+		{
+			name: "Authentication error",
+			mockBehaviour: func(s *mockService.MockKrakenOrdersManager, userID int) {
+				err := krakenFuturesSDK.KrakenErrorResponse{Result: "error", Error: "authenticationError"}.Err()
+				s.EXPECT().SendOrder(gomock.Any(), userID, args).Return(models.Order{}, fmt.Errorf("%s: %w", webKraken.ErrSendOrder, err))
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedRequestBody: `{"message":"web sdk: send order: kraken: err: authenticationError, server time: , result: error",` +
+				`"code":"authenticationError","kind":"authentication"}`,
+		},
+		{
+			name: "Unknown kraken error",
+			mockBehaviour: func(s *mockService.MockKrakenOrdersManager, userID int) {
+				err := krakenFuturesSDK.NewStatusError("somethingNew")
+				s.EXPECT().SendOrder(gomock.Any(), userID, args).Return(models.Order{}, err)
+			},
+			expectedStatusCode: http.StatusBadGateway,
+			expectedRequestBody: `{"message":"kraken: invalid status: somethingNew",` +
+				`"code":"somethingNew","kind":"unknown"}`,
+		},
+		{
+			name: "Rejected by validation",
+			mockBehaviour: func(s *mockService.MockKrakenOrdersManager, userID int) {
+				err := fmt.Errorf("%s: %w", service.ErrSendOrderServiceMethod,
+					krakenFuturesSDK.NewValidationError("pi_xbtusd", "invalidSize", "size 1.5 is not positive multiple of 1"))
+				s.EXPECT().SendOrder(gomock.Any(), userID, args).Return(models.Order{}, err)
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedRequestBody: `{"message":"send order service method: invalid order for pi_xbtusd: ` +
+				`invalidSize: size 1.5 is not positive multiple of 1","code":"invalidSize","kind":"invalid_argument"}`,
+		},
+		{
+			name: "Instrument is not tradeable",
+			mockBehaviour: func(s *mockService.MockKrakenOrdersManager, userID int) {
+				err := fmt.Errorf("%s: %w", service.ErrSendOrderServiceMethod,
+					krakenFuturesSDK.NewValidationError("pi_xbtusd", "marketSuspended", "instrument is not tradeable"))
+				s.EXPECT().SendOrder(gomock.Any(), userID, args).Return(models.Order{}, err)
+			},
+			expectedStatusCode: http.StatusConflict,
+			expectedRequestBody: `{"message":"send order service method: invalid order for pi_xbtusd: ` +
+				`marketSuspended: instrument is not tradeable","code":"marketSuspended","kind":"market_closed"}`,
+		},
+		{
+			name: "Service error",
+			mockBehaviour: func(s *mockService.MockKrakenOrdersManager, userID int) {
+				s.EXPECT().SendOrder(gomock.Any(), userID, args).Return(models.Order{}, errors.New("something went wrong"))
+			},
+			expectedStatusCode:  http.StatusInternalServerError,
+			expectedRequestBody: `{"message":"something went wrong"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			ordersManager := mockService.NewMockKrakenOrdersManager(c)
+			test.mockBehaviour(ordersManager, 1)
+
+			services := &service.Service{KrakenOrdersManager: ordersManager}
+			handler := Handler{services, nil, nil}
+
+			// test server
+			r := gin.New()
+			r.POST("/send-order", func(c *gin.Context) {
+				c.Set(userIDCtx, 1)
+			}, handler.sendOrder)
+
+			// test request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/send-order", bytes.NewBufferString(body))
+
+			// make request
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			if test.expectedRequestBody != "" {
+				assert.Equal(t, test.expectedRequestBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_sendOrderInvalidSize(t *testing.T) {
+	for _, size := range []string{`0`, `"-0.5"`, `"abc"`} {
+		t.Run(size, func(t *testing.T) {
+			handler := Handler{&service.Service{}, nil, nil}
+
+			r := gin.New()
+			r.POST("/send-order", func(c *gin.Context) {
+				c.Set(userIDCtx, 1)
+			}, handler.sendOrder)
+
+			w := httptest.NewRecorder()
+			body := `{"order_type":"mkt","symbol":"pi_xbtusd","side":"buy","size":` + size + `}`
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/send-order", bytes.NewBufferString(body)))
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Strategies
+// @Security ApiKeyAuth
+// @Tags strategies
+// @Description get all strategies available for trading and entry strategies which open position with their params schema
+// @ID strategies
+// @Produce  json
+// @Success 200 {object} []tradeAlgorithm.Strategy
+// @Failure 401,404 {object} errResponse
+// @Failure default {object} errResponse
+// @Router /orderManager/strategies [get]
+func (h *Handler) strategies(c *gin.Context) {
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"strategies":       h.services.Strategies.GetStrategies(),
+		"entry_strategies": h.services.Strategies.GetEntryStrategies(),
+	})
+}
@@ -0,0 +1,253 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"trade-bot/internal/pkg/service"
+	"trade-bot/internal/pkg/tradeAlgorithm/types"
+)
+
+var ErrInvalidEvent = errors.New("invalid event")
+
+const sessionIDParam = "id"
+
+type tradingDetails struct {
+	Event          string               `json:"event"`
+	TradingDetails types.TradingDetails `json:"trading_details,omitempty"`
+}
+
+const cancelEvent = "cancel_trading"
+const startTrading = "start_trading"
+
+// @Summary StartSession
+// @Security ApiKeyAuth
+// @Tags tradingSessions
+// @Description start trading session running in background
+// @ID startSession
+// @Accept  json
+// @Produce  json
+// @Param input body types.TradingDetails true "trading details"
+// @Success 200 {object} models.TradingSession
+// @Failure 400,401,403,404,409,422,429 {object} errResponse
+// @Failure 500,502,503 {object} errResponse
+// @Failure default {object} errResponse
+// @Router /orderManager/sessions [post]
+func (h *Handler) startSession(c *gin.Context) {
+	var input types.TradingDetails
+
+	if err := c.BindJSON(&input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := h.validate.Struct(input); err != nil {
+		newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, err := getUserID(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	session, err := h.services.TradingSessions.StartSession(c.Request.Context(), userID, input)
+	if err != nil {
+		newServiceErrorResponse(c, sessionErrStatusCode(err), err)
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
+// @Summary MySessions
+// @Security ApiKeyAuth
+// @Tags tradingSessions
+// @Description get all trading sessions of user
+// @ID mySessions
+// @Produce  json
+// @Success 200 {object} []models.TradingSession
+// @Failure 401,404 {object} errResponse
+// @Failure 500 {object} errResponse
+// @Failure default {object} errResponse
+// @Router /orderManager/sessions [get]
+func (h *Handler) mySessions(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	sessions, err := h.services.TradingSessions.GetUserSessions(userID)
+	if err != nil {
+		newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{
+		"sessions": sessions,
+	})
+}
+
+// @Summary GetSession
+// @Security ApiKeyAuth
+// @Tags tradingSessions
+// @Description get trading session of user
+// @ID getSession
+// @Produce  json
+// @Param id path string true "session id"
+// @Success 200 {object} models.TradingSession
+// @Failure 401,404 {object} errResponse
+// @Failure 500 {object} errResponse
+// @Failure default {object} errResponse
+// @Router /orderManager/sessions/{id} [get]
+func (h *Handler) getSession(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	session, err := h.services.TradingSessions.GetSession(userID, c.Param(sessionIDParam))
+	if err != nil {
+		newErrorResponse(c, sessionErrStatusCode(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
+// @Summary StopSession
+// @Security ApiKeyAuth
+// @Tags tradingSessions
+// @Description stop trading session and close its position
+// @ID stopSession
+// @Produce  json
+// @Param id path string true "session id"
+// @Success 200 {object} models.TradingSession
+// @Failure 401,404,409 {object} errResponse
+// @Failure 500 {object} errResponse
+// @Failure default {object} errResponse
+// @Router /orderManager/sessions/{id} [delete]
+func (h *Handler) stopSession(c *gin.Context) {
+	userID, err := getUserID(c)
+	if err != nil {
+		newErrorResponse(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	session, err := h.services.TradingSessions.StopSession(userID, c.Param(sessionIDParam))
+	if err != nil {
+		newErrorResponse(c, sessionErrStatusCode(err), err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
+func sessionErrStatusCode(err error) int {
+	switch {
+	case errors.Is(err, service.ErrSessionNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrSessionIsTerminated):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrInvalidStrategy):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// startTrade starts trading session and streams its events. Closing connection does not stop session,
+// only cancel_trading event does
+func (h *Handler) startTrade(c *gin.Context) {
+	conn, err := h.wsUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		newErrorResponse(c, http.StatusForbidden, err.Error())
+		return
+	}
+	defer conn.Close()
+
+	userID, err := getUserID(c)
+	if err != nil {
+		newWebsocketErrResponse(c, http.StatusUnauthorized, conn, err.Error())
+		return
+	}
+
+	var input tradingDetails
+	if err := conn.ReadJSON(&input); err != nil {
+		newWebsocketErrResponse(c, http.StatusInternalServerError, conn, err.Error())
+		return
+	}
+	if err := h.validate.Struct(input); err != nil {
+		newWebsocketErrResponse(c, http.StatusBadRequest, conn, err.Error())
+		return
+	}
+	if input.Event != startTrading {
+		newWebsocketErrResponse(c, http.StatusBadRequest, conn, ErrInvalidEvent.Error())
+		return
+	}
+
+	session, err := h.services.TradingSessions.StartSession(c.Request.Context(), userID, input.TradingDetails)
+	if err != nil {
+		newWebsocketServiceErrResponse(c, sessionErrStatusCode(err), conn, err)
+		return
+	}
+
+	h.watchTradingSession(c, conn, userID, session.ID)
+}
+
+// watchTrade attaches live view to already running trading session
+func (h *Handler) watchTrade(c *gin.Context) {
+	conn, err := h.wsUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		newErrorResponse(c, http.StatusForbidden, err.Error())
+		return
+	}
+	defer conn.Close()
+
+	userID, err := getUserID(c)
+	if err != nil {
+		newWebsocketErrResponse(c, http.StatusUnauthorized, conn, err.Error())
+		return
+	}
+
+	h.watchTradingSession(c, conn, userID, c.Param(sessionIDParam))
+}
+
+func (h *Handler) watchTradingSession(c *gin.Context, conn *websocket.Conn, userID int, sessionID string) {
+	events, detach, err := h.services.TradingSessions.WatchSession(userID, sessionID)
+	if err != nil {
+		newWebsocketErrResponse(c, sessionErrStatusCode(err), conn, err.Error())
+		return
+	}
+	defer detach()
+
+	go func() {
+		defer detach()
+
+		var input tradingDetails
+		for {
+			if err := conn.ReadJSON(&input); err != nil {
+				return
+			}
+			if input.Event != cancelEvent {
+				continue
+			}
+			if _, err := h.services.TradingSessions.StopSession(userID, sessionID); err != nil {
+				log.Warn(err)
+			}
+		}
+	}()
+
+	for event := range events {
+		if err := conn.WriteJSON(event); err != nil {
+			log.Warn(err)
+			return
+		}
+	}
+}
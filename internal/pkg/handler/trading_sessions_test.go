@@ -0,0 +1,263 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/internal/pkg/models"
+	"trade-bot/internal/pkg/service"
+	mockService "trade-bot/internal/pkg/service/mocks"
+	"trade-bot/internal/pkg/tradeAlgorithm/types"
+	"trade-bot/pkg/krakenFuturesSDK"
+)
+
+func TestHandler_startSession(t *testing.T) {
+	details := types.TradingDetails{OrderType: "mkt", Symbol: "pi_xbtusd", Side: "buy", Size: decimal.NewFromInt(1),
+		Strategy: "stop_loss"}
+	body := `{"order_type":"mkt","symbol":"pi_xbtusd","side":"buy","size":1,"strategy":"stop_loss"}`
+
+	type mockBehaviour func(s *mockService.MockTradingSessions, userID int)
+
+	tests := []struct {
+		name                string
+		body                string
+		mockBehaviour       mockBehaviour
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name: "OK",
+			body: body,
+			mockBehaviour: func(s *mockService.MockTradingSessions, userID int) {
+				s.EXPECT().StartSession(gomock.Any(), userID, details).
+					Return(models.TradingSession{ID: "1", UserID: userID, State: models.TradingSessionStarting}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "Invalid details",
+			body:               `{"order_type":"mkt","symbol":"pi_xbtusd","side":"buy","size":1}`,
+			mockBehaviour:      func(s *mockService.MockTradingSessions, userID int) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "Invalid strategy",
+			body: body,
+			mockBehaviour: func(s *mockService.MockTradingSessions, userID int) {
+				err := fmt.Errorf("%s: %w: %s", service.ErrStartSession, service.ErrInvalidStrategy, "unknown strategy")
+				s.EXPECT().StartSession(gomock.Any(), userID, details).Return(models.TradingSession{}, err)
+			},
+			expectedStatusCode:  http.StatusBadRequest,
+			expectedRequestBody: `{"message":"start trading session: invalid strategy of trading session: unknown strategy"}`,
+		},
+		{
+			name: "Order rejected by validation",
+			body: body,
+			mockBehaviour: func(s *mockService.MockTradingSessions, userID int) {
+				err := krakenFuturesSDK.NewValidationError("pi_xbtusd", "invalidSize", "size is too small")
+				s.EXPECT().StartSession(gomock.Any(), userID, details).Return(models.TradingSession{}, fmt.Errorf("%s: %w", service.ErrStartSession, err))
+			},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "Kraken is unavailable",
+			body: body,
+			mockBehaviour: func(s *mockService.MockTradingSessions, userID int) {
+				err := &krakenFuturesSDK.APIError{Code: krakenFuturesSDK.CodeServerUnavailable, HTTPStatus: http.StatusBadGateway}
+				s.EXPECT().StartSession(gomock.Any(), userID, details).Return(models.TradingSession{}, fmt.Errorf("%s: %w", service.ErrStartSession, err))
+			},
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedRequestBody: `{"message":"start trading session: kraken: serverUnavailable: http status 502",` +
+				`"code":"serverUnavailable","kind":"transient"}`,
+		},
+		{
+			name: "Service error",
+			body: body,
+			mockBehaviour: func(s *mockService.MockTradingSessions, userID int) {
+				s.EXPECT().StartSession(gomock.Any(), userID, details).Return(models.TradingSession{}, errors.New("something went wrong"))
+			},
+			expectedStatusCode:  http.StatusInternalServerError,
+			expectedRequestBody: `{"message":"something went wrong"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			sessions := mockService.NewMockTradingSessions(c)
+			test.mockBehaviour(sessions, 1)
+
+			services := &service.Service{TradingSessions: sessions}
+			handler := NewHandler(services, validator.New(), nil)
+
+			// test server
+			r := gin.New()
+			r.POST("/sessions", func(c *gin.Context) {
+				c.Set(userIDCtx, 1)
+			}, handler.startSession)
+
+			// test request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/sessions", bytes.NewBufferString(test.body))
+
+			// make request
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			if test.expectedRequestBody != "" {
+				assert.Equal(t, test.expectedRequestBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_getSession(t *testing.T) {
+	session := models.TradingSession{ID: "1", UserID: 1, State: models.TradingSessionRunning}
+	sessionJSON, err := json.Marshal(session)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type mockBehaviour func(s *mockService.MockTradingSessions, userID int, sessionID string)
+
+	tests := []struct {
+		name                string
+		sessionID           string
+		mockBehaviour       mockBehaviour
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:      "OK",
+			sessionID: "1",
+			mockBehaviour: func(s *mockService.MockTradingSessions, userID int, sessionID string) {
+				s.EXPECT().GetSession(userID, sessionID).Return(session, nil)
+			},
+			expectedStatusCode:  http.StatusOK,
+			expectedRequestBody: string(sessionJSON),
+		},
+		{
+			name:      "Not found",
+			sessionID: "2",
+			mockBehaviour: func(s *mockService.MockTradingSessions, userID int, sessionID string) {
+				s.EXPECT().GetSession(userID, sessionID).
+					Return(models.TradingSession{}, fmt.Errorf("%s: %w", service.ErrGetSession, service.ErrSessionNotFound))
+			},
+			expectedStatusCode:  http.StatusNotFound,
+			expectedRequestBody: `{"message":"get trading session: trading session not found"}`,
+		},
+		{
+			name:      "Service error",
+			sessionID: "3",
+			mockBehaviour: func(s *mockService.MockTradingSessions, userID int, sessionID string) {
+				s.EXPECT().GetSession(userID, sessionID).Return(models.TradingSession{}, errors.New("something went wrong"))
+			},
+			expectedStatusCode:  http.StatusInternalServerError,
+			expectedRequestBody: `{"message":"something went wrong"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			sessions := mockService.NewMockTradingSessions(c)
+			test.mockBehaviour(sessions, 1, test.sessionID)
+
+			services := &service.Service{TradingSessions: sessions}
+			handler := Handler{services, nil, nil}
+
+			// test server
+			r := gin.New()
+			r.GET("/sessions/:id", func(c *gin.Context) {
+				c.Set(userIDCtx, 1)
+			}, handler.getSession)
+
+			// test request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/sessions/"+test.sessionID, nil)
+
+			// make request
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedRequestBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_stopSession(t *testing.T) {
+	type mockBehaviour func(s *mockService.MockTradingSessions, userID int, sessionID string)
+
+	tests := []struct {
+		name                string
+		sessionID           string
+		mockBehaviour       mockBehaviour
+		expectedStatusCode  int
+		expectedRequestBody string
+	}{
+		{
+			name:      "Already terminated",
+			sessionID: "1",
+			mockBehaviour: func(s *mockService.MockTradingSessions, userID int, sessionID string) {
+				s.EXPECT().StopSession(userID, sessionID).
+					Return(models.TradingSession{}, fmt.Errorf("%s: %w", service.ErrStopSession, service.ErrSessionIsTerminated))
+			},
+			expectedStatusCode:  http.StatusConflict,
+			expectedRequestBody: `{"message":"stop trading session: trading session is already terminated"}`,
+		},
+		{
+			name:      "Not found",
+			sessionID: "2",
+			mockBehaviour: func(s *mockService.MockTradingSessions, userID int, sessionID string) {
+				s.EXPECT().StopSession(userID, sessionID).
+					Return(models.TradingSession{}, fmt.Errorf("%s: %w", service.ErrStopSession, service.ErrSessionNotFound))
+			},
+			expectedStatusCode:  http.StatusNotFound,
+			expectedRequestBody: `{"message":"stop trading session: trading session not found"}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			sessions := mockService.NewMockTradingSessions(c)
+			test.mockBehaviour(sessions, 1, test.sessionID)
+
+			services := &service.Service{TradingSessions: sessions}
+			handler := Handler{services, nil, nil}
+
+			// test server
+			r := gin.New()
+			r.DELETE("/sessions/:id", func(c *gin.Context) {
+				c.Set(userIDCtx, 1)
+			}, handler.stopSession)
+
+			// test request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodDelete, "/sessions/"+test.sessionID, nil)
+
+			// make request
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatusCode, w.Code)
+			assert.Equal(t, test.expectedRequestBody, w.Body.String())
+		})
+	}
+}
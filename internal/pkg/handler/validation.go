@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"reflect"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/shopspring/decimal"
+)
+
+func init() {
+	if validate, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		registerDecimalType(validate)
+	}
+}
+
+// registerDecimalType makes decimal fields validatable like numbers, e.g. `binding:"required,gt=0"`
+func registerDecimalType(validate *validator.Validate) {
+	validate.RegisterCustomTypeFunc(func(field reflect.Value) interface{} {
+		if value, ok := field.Interface().(decimal.Decimal); ok {
+			return value.InexactFloat64()
+		}
+		return nil
+	}, decimal.Decimal{})
+}
@@ -4,10 +4,14 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
 	log "github.com/sirupsen/logrus"
+
+	"trade-bot/pkg/krakenFuturesSDK"
 )
 
 type websocketErrResponse struct {
-	Message string `json:"message"`
+	Message string                     `json:"message"`
+	Code    string                     `json:"code,omitempty"`
+	Kind    krakenFuturesSDK.ErrorKind `json:"kind,omitempty"`
 }
 
 func newWebsocketErrResponse(c *gin.Context, code int, ws *websocket.Conn, message string) {
@@ -17,3 +21,13 @@ func newWebsocketErrResponse(c *gin.Context, code int, ws *websocket.Conn, messa
 	c.AbortWithStatus(code)
 	log.Error(message)
 }
+
+// newWebsocketServiceErrResponse is newServiceErrorResponse for websocket, error code is sent with message
+func newWebsocketServiceErrResponse(c *gin.Context, fallbackStatusCode int, ws *websocket.Conn, err error) {
+	statusCode, response := serviceErrResponse(fallbackStatusCode, err)
+	if err := ws.WriteJSON(websocketErrResponse(response)); err != nil {
+		log.Error(err.Error())
+	}
+	c.AbortWithStatus(statusCode)
+	log.Error(err.Error())
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// DeadMansSwitchStatus is state of kraken timer which cancels all orders of user when bot stops refreshing it
+type DeadMansSwitchStatus struct {
+	UserID int `json:"user_id"`
+	// Armed is true while bot keeps refreshing timer for live trading sessions of user
+	Armed          bool      `json:"armed"`
+	Healthy        bool      `json:"healthy"`
+	TimeoutSeconds uint      `json:"timeout_seconds"`
+	LastRefresh    time.Time `json:"last_refresh,omitempty"`
+	TriggerTime    string    `json:"trigger_time,omitempty"`
+	Error          string    `json:"error,omitempty"`
+}
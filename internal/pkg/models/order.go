@@ -1,15 +1,24 @@
 package models
 
+import (
+	"github.com/shopspring/decimal"
+
+	"trade-bot/internal/pkg/tradeAlgorithm/types"
+)
+
 type Order struct {
-	ID                  string  `json:"id" db:"order_id"`
-	UserID              int     `json:"user_id" db:"user_id"`
-	ClientOrderID       string  `json:"client_order_id" db:"cli_order_id"`
-	Type                string  `json:"type" db:"type"`
-	Symbol              string  `json:"symbol" db:"symbol"`
-	Quantity            float64 `json:"quantity" db:"quantity"`
-	Side                string  `json:"side" db:"side"`
-	Filled              float64 `json:"filled" db:"filled"`
-	Timestamp           string  `json:"timestamp" db:"timestamp"`
-	LastUpdateTimestamp string  `json:"last_update_timestamp" db:"last_update_timestamp"`
-	Price               float64 `json:"price" db:"price"`
+	ID                  string          `json:"id" db:"order_id"`
+	UserID              int             `json:"user_id" db:"user_id"`
+	ClientOrderID       string          `json:"client_order_id" db:"cli_order_id"`
+	Type                string          `json:"type" db:"type"`
+	Symbol              string          `json:"symbol" db:"symbol"`
+	Quantity            decimal.Decimal `json:"quantity" db:"quantity" swaggertype:"string"`
+	Side                string          `json:"side" db:"side"`
+	Filled              decimal.Decimal `json:"filled" db:"filled" swaggertype:"string"`
+	Timestamp           string          `json:"timestamp" db:"timestamp"`
+	LastUpdateTimestamp string          `json:"last_update_timestamp" db:"last_update_timestamp"`
+	Price               decimal.Decimal `json:"price" db:"price" swaggertype:"string"`
+	Paper               bool            `json:"paper" db:"paper"`
+	// ExitReason is set only on order which closed position of trading session
+	ExitReason types.ExitReason `json:"exit_reason,omitempty" db:"exit_reason"`
 }
@@ -0,0 +1,21 @@
+package models
+
+import "github.com/shopspring/decimal"
+
+type PaperPosition struct {
+	Symbol string `json:"symbol"`
+	// Size is positive for long and negative for short position
+	Size          decimal.Decimal `json:"size" swaggertype:"string"`
+	EntryPrice    decimal.Decimal `json:"entry_price" swaggertype:"string"`
+	MarkPrice     decimal.Decimal `json:"mark_price,omitempty" swaggertype:"string"`
+	UnrealizedPnL decimal.Decimal `json:"unrealized_pnl" swaggertype:"string"`
+}
+
+// PaperAccount is a virtual account used by paper trading instead of kraken account
+type PaperAccount struct {
+	UserID      int             `json:"user_id"`
+	Balance     decimal.Decimal `json:"balance" swaggertype:"string"`
+	RealizedPnL decimal.Decimal `json:"realized_pnl" swaggertype:"string"`
+	Fees        decimal.Decimal `json:"fees" swaggertype:"string"`
+	Positions   []PaperPosition `json:"positions"`
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"trade-bot/internal/pkg/tradeAlgorithm/types"
+)
+
+type TradingSessionState string
+
+const (
+	// TradingSessionWaiting is state of session which waits for entry signal
+	TradingSessionWaiting  TradingSessionState = "waiting"
+	TradingSessionStarting TradingSessionState = "starting"
+	TradingSessionRunning  TradingSessionState = "running"
+	TradingSessionStopping TradingSessionState = "stopping"
+	TradingSessionStopped  TradingSessionState = "stopped"
+	TradingSessionFinished TradingSessionState = "finished"
+	TradingSessionFailed   TradingSessionState = "failed"
+)
+
+// IsTerminal reports whether session with such state will never trade again
+func (s TradingSessionState) IsTerminal() bool {
+	return s == TradingSessionStopped || s == TradingSessionFinished || s == TradingSessionFailed
+}
+
+type TradingSession struct {
+	ID           string               `json:"id"`
+	UserID       int                  `json:"user_id"`
+	Details      types.TradingDetails `json:"trading_details"`
+	State        TradingSessionState  `json:"state"`
+	EntryOrderID string               `json:"entry_order_id,omitempty"`
+	EntryPrice   decimal.Decimal      `json:"entry_price,omitempty" swaggertype:"string"`
+	EntryTime    time.Time            `json:"entry_time,omitempty"`
+	ExitOrderID  string               `json:"exit_order_id,omitempty"`
+	Error        string               `json:"error,omitempty"`
+	// RoundTrips is number of positions which were opened and closed by session
+	RoundTrips int       `json:"round_trips"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// InPosition reports whether session has opened position which is not closed yet
+func (s TradingSession) InPosition() bool {
+	return s.EntryOrderID != "" && s.ExitOrderID == ""
+}
+
+const (
+	TradingSessionStateEvent      = "state_changed"
+	TradingSessionEntryOrderEvent = "entry_order"
+	TradingSessionExitOrderEvent  = "exit_order"
+)
+
+type TradingSessionEvent struct {
+	Event   string         `json:"event"`
+	Session TradingSession `json:"session"`
+	Order   *Order         `json:"order,omitempty"`
+}
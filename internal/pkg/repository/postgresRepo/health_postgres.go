@@ -0,0 +1,22 @@
+package postgresRepo
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type HealthPostgres struct {
+	db *sqlx.DB
+}
+
+func NewHealthPostgres(db *sqlx.DB) *HealthPostgres {
+	return &HealthPostgres{db: db}
+}
+
+func (r *HealthPostgres) Ping() error {
+	if err := r.db.Ping(); err != nil {
+		return fmt.Errorf("%s: %w", ErrPingDB, err)
+	}
+	return nil
+}
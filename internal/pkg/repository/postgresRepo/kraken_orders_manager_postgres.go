@@ -24,9 +24,9 @@ func NewKrakenOrdersManagerPostgres(db *sqlx.DB) *KrakenOrdersManagerPostgres {
 
 const createOrderQuery = `
 	INSERT INTO orders(order_id, user_id, cli_order_id, type, symbol, quantity, side, filled,
-	                  timestamp, last_update_timestamp, price)
+	                  timestamp, last_update_timestamp, price, paper, exit_reason)
 	VALUES($1, $2, $3, $4, $5, $6, $7, $8,
-	                  $9, $10, $11)`
+	                  $9, $10, $11, $12, $13)`
 
 const createUsersOrdersQuery = `
 	INSERT INTO users_orders(user_id, order_id) VALUES ($1, $2)
@@ -39,7 +39,7 @@ func (k *KrakenOrdersManagerPostgres) CreateOrder(userID int, order models.Order
 	}
 
 	_, err = tx.Exec(createOrderQuery, order.ID, order.UserID, order.ClientOrderID, order.Type, order.Symbol, order.Quantity,
-		order.Side, order.Filled, order.Timestamp, order.LastUpdateTimestamp, order.Price)
+		order.Side, order.Filled, order.Timestamp, order.LastUpdateTimestamp, order.Price, order.Paper, order.ExitReason)
 	if err != nil {
 		if err := tx.Rollback(); err != nil {
 			return ErrCouldNotRollbackTransaction
@@ -81,7 +81,7 @@ func (k *KrakenOrdersManagerPostgres) GetUserOrders(userID int) ([]models.Order,
 		var order models.Order
 
 		if err := rows.Scan(&order.ID, &order.UserID, &order.ClientOrderID, &order.Type, &order.Symbol, &order.Quantity,
-			&order.Side, &order.Filled, &order.Timestamp, &order.LastUpdateTimestamp, &order.Price); err != nil {
+			&order.Side, &order.Filled, &order.Timestamp, &order.LastUpdateTimestamp, &order.Price, &order.Paper, &order.ExitReason); err != nil {
 			return nil, fmt.Errorf("%s: %w", ErrGetUsersOrder, err)
 		}
 		orders = append(orders, order)
@@ -6,6 +6,7 @@ import (
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jmoiron/sqlx"
 	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 
 	"trade-bot/internal/pkg/models"
@@ -42,12 +43,12 @@ func TestKrakenOrdersManagerPostgres_CreateOrder(t *testing.T) {
 					ClientOrderID:       "1",
 					Type:                "type",
 					Symbol:              "symbol",
-					Quantity:            10,
+					Quantity:            decimal.NewFromInt(10),
 					Side:                "buy",
-					Filled:              2,
+					Filled:              decimal.NewFromInt(2),
 					Timestamp:           "timestamp",
 					LastUpdateTimestamp: "timestamp",
-					Price:               10,
+					Price:               decimal.NewFromInt(10),
 				},
 				userID: 1,
 			},
@@ -56,7 +57,7 @@ func TestKrakenOrdersManagerPostgres_CreateOrder(t *testing.T) {
 
 				mock.ExpectExec("INSERT INTO orders").
 					WithArgs(order.ID, order.UserID, order.ClientOrderID, order.Type, order.Symbol, order.Quantity,
-						order.Side, order.Filled, order.Timestamp, order.LastUpdateTimestamp, order.Price).
+						order.Side, order.Filled, order.Timestamp, order.LastUpdateTimestamp, order.Price, order.Paper, order.ExitReason).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 
 				mock.ExpectExec("INSERT INTO users_orders").WithArgs(userID, order.ID).
@@ -80,7 +81,7 @@ func TestKrakenOrdersManagerPostgres_CreateOrder(t *testing.T) {
 
 				mock.ExpectExec("INSERT INTO orders").
 					WithArgs(order.ID, order.UserID, order.ClientOrderID, order.Type, order.Symbol, order.Quantity,
-						order.Side, order.Filled, order.Timestamp, order.LastUpdateTimestamp, order.Price).
+						order.Side, order.Filled, order.Timestamp, order.LastUpdateTimestamp, order.Price, order.Paper, order.ExitReason).
 					WillReturnError(errors.New("insert error"))
 
 				mock.ExpectRollback()
@@ -96,12 +97,12 @@ func TestKrakenOrdersManagerPostgres_CreateOrder(t *testing.T) {
 					ClientOrderID:       "1",
 					Type:                "type",
 					Symbol:              "symbol",
-					Quantity:            10,
+					Quantity:            decimal.NewFromInt(10),
 					Side:                "buy",
-					Filled:              2,
+					Filled:              decimal.NewFromInt(2),
 					Timestamp:           "timestamp",
 					LastUpdateTimestamp: "timestamp",
-					Price:               10,
+					Price:               decimal.NewFromInt(10),
 				},
 			},
 			mock: func(userID int, order models.Order) {
@@ -109,7 +110,7 @@ func TestKrakenOrdersManagerPostgres_CreateOrder(t *testing.T) {
 
 				mock.ExpectExec("INSERT INTO orders").
 					WithArgs(order.ID, order.UserID, order.ClientOrderID, order.Type, order.Symbol, order.Quantity,
-						order.Side, order.Filled, order.Timestamp, order.LastUpdateTimestamp, order.Price).
+						order.Side, order.Filled, order.Timestamp, order.LastUpdateTimestamp, order.Price, order.Paper, order.ExitReason).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 
 				mock.ExpectExec("INSERT INTO users_orders").WithArgs(userID, order.ID).
@@ -164,17 +165,23 @@ func TestKrakenOrdersManagerPostgres_GetOrder(t *testing.T) {
 			input: args{
 				inputOrderID: "1",
 				order: models.Order{
-					ID: "1",
+					ID:       "1",
+					Quantity: decimal.RequireFromString("0.0015"),
+					Filled:   decimal.RequireFromString("0.0015"),
+					Price:    decimal.RequireFromString("41234.5"),
 				},
 			},
 			want: models.Order{
-				ID: "1",
+				ID:       "1",
+				Quantity: decimal.RequireFromString("0.0015"),
+				Filled:   decimal.RequireFromString("0.0015"),
+				Price:    decimal.RequireFromString("41234.5"),
 			},
 			mock: func(orderID string, order models.Order) {
 				rows := sqlmock.NewRows([]string{"order_id", "user_id", "cli_order_id", "type", "symbol", "quantity",
-					"side", "filled", "timestamp", "last_update_timestamp", "price"}).
+					"side", "filled", "timestamp", "last_update_timestamp", "price", "paper", "exit_reason"}).
 					AddRow(order.ID, order.UserID, order.ClientOrderID, order.Type, order.Symbol, order.Quantity,
-						order.Side, order.Filled, order.Timestamp, order.LastUpdateTimestamp, order.Price)
+						order.Side, order.Filled, order.Timestamp, order.LastUpdateTimestamp, order.Price, order.Paper, order.ExitReason)
 				mock.ExpectQuery("SELECT (.+) FROM orders").
 					WithArgs(orderID).WillReturnRows(rows)
 			},
@@ -185,15 +192,21 @@ func TestKrakenOrdersManagerPostgres_GetOrder(t *testing.T) {
 			input: args{
 				inputOrderID: "1",
 				order: models.Order{
-					ID: "1",
+					ID:       "1",
+					Quantity: decimal.RequireFromString("0.0015"),
+					Filled:   decimal.RequireFromString("0.0015"),
+					Price:    decimal.RequireFromString("41234.5"),
 				},
 			},
 			want: models.Order{
-				ID: "1",
+				ID:       "1",
+				Quantity: decimal.RequireFromString("0.0015"),
+				Filled:   decimal.RequireFromString("0.0015"),
+				Price:    decimal.RequireFromString("41234.5"),
 			},
 			mock: func(orderID string, order models.Order) {
 				rows := sqlmock.NewRows([]string{"order_id", "user_id", "cli_order_id", "type", "symbol", "quantity",
-					"side", "filled", "timestamp", "last_update_timestamp", "price"})
+					"side", "filled", "timestamp", "last_update_timestamp", "price", "paper", "exit_reason"})
 				mock.ExpectQuery("SELECT (.+) FROM orders").
 					WithArgs(orderID).WillReturnRows(rows)
 			},
@@ -244,18 +257,18 @@ func TestKrakenOrdersManagerPostgres_GetUserOrders(t *testing.T) {
 				ClientOrderID:       "1",
 				Type:                "type",
 				Symbol:              "symbol",
-				Quantity:            10,
+				Quantity:            decimal.NewFromInt(10),
 				Side:                "buy",
-				Filled:              10,
+				Filled:              decimal.NewFromInt(10),
 				Timestamp:           "time",
 				LastUpdateTimestamp: "time",
-				Price:               100,
+				Price:               decimal.NewFromInt(100),
 			},
 			mock: func(userID int, order models.Order) {
 				rows := sqlmock.NewRows([]string{"order_id", "user_id", "cli_order_id", "type", "symbol", "quantity",
-					"side", "filled", "timestamp", "last_update_timestamp", "price"}).
+					"side", "filled", "timestamp", "last_update_timestamp", "price", "paper", "exit_reason"}).
 					AddRow(order.ID, order.UserID, order.ClientOrderID, order.Type, order.Symbol, order.Quantity,
-						order.Side, order.Filled, order.Timestamp, order.LastUpdateTimestamp, order.Price)
+						order.Side, order.Filled, order.Timestamp, order.LastUpdateTimestamp, order.Price, order.Paper, order.ExitReason)
 				mock.ExpectQuery("SELECT (.+) FROM orders").
 					WithArgs(userID).WillReturnRows(rows)
 			},
@@ -265,12 +278,12 @@ func TestKrakenOrdersManagerPostgres_GetUserOrders(t *testing.T) {
 				ClientOrderID:       "1",
 				Type:                "type",
 				Symbol:              "symbol",
-				Quantity:            10,
+				Quantity:            decimal.NewFromInt(10),
 				Side:                "buy",
-				Filled:              10,
+				Filled:              decimal.NewFromInt(10),
 				Timestamp:           "time",
 				LastUpdateTimestamp: "time",
-				Price:               100,
+				Price:               decimal.NewFromInt(100),
 			}},
 			wantErr: false,
 		},
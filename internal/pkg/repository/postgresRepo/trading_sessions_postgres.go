@@ -0,0 +1,118 @@
+package postgresRepo
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+
+	"trade-bot/internal/pkg/models"
+)
+
+var (
+	ErrGetUserSessions   = errors.New("get user sessions")
+	ErrGetActiveSessions = errors.New("get active sessions")
+)
+
+type TradingSessionsPostgres struct {
+	db *sqlx.DB
+}
+
+func NewTradingSessionsPostgres(db *sqlx.DB) *TradingSessionsPostgres {
+	return &TradingSessionsPostgres{db: db}
+}
+
+const createSessionQuery = `
+	INSERT INTO trading_sessions(id, user_id, order_type, symbol, side, size, strategy, params, paper,
+	                            entry_strategy, entry_params, entry_order_id, entry_price, entry_time, exit_order_id,
+	                            round_trips, state, error, created_at, updated_at)
+	VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9,
+	       $10, $11, $12, $13, $14, $15,
+	       $16, $17, $18, $19, $20)`
+
+func (r *TradingSessionsPostgres) CreateSession(session models.TradingSession) error {
+	_, err := r.db.Exec(createSessionQuery, session.ID, session.UserID, session.Details.OrderType, session.Details.Symbol,
+		session.Details.Side, session.Details.Size, session.Details.Strategy, session.Details.Params, session.Details.Paper,
+		session.Details.EntryStrategy, session.Details.EntryParams, session.EntryOrderID, session.EntryPrice,
+		session.EntryTime, session.ExitOrderID, session.RoundTrips, session.State, session.Error,
+		session.CreatedAt, session.UpdatedAt)
+	return err
+}
+
+const updateSessionQuery = `
+	UPDATE trading_sessions
+	SET entry_order_id=$2, entry_price=$3, entry_time=$4, exit_order_id=$5, round_trips=$6, state=$7, error=$8,
+	    updated_at=$9
+	WHERE id=$1`
+
+func (r *TradingSessionsPostgres) UpdateSession(session models.TradingSession) error {
+	_, err := r.db.Exec(updateSessionQuery, session.ID, session.EntryOrderID, session.EntryPrice, session.EntryTime,
+		session.ExitOrderID, session.RoundTrips, session.State, session.Error, session.UpdatedAt)
+	return err
+}
+
+const selectSessionsQuery = `
+	SELECT id, user_id, order_type, symbol, side, size, strategy, params, paper, entry_strategy, entry_params,
+	       entry_order_id, entry_price, entry_time, exit_order_id, round_trips, state, error, created_at, updated_at
+	FROM trading_sessions`
+
+const getSessionQuery = selectSessionsQuery + ` WHERE id=$1`
+
+func (r *TradingSessionsPostgres) GetSession(sessionID string) (models.TradingSession, error) {
+	return scanSession(r.db.QueryRowx(getSessionQuery, sessionID))
+}
+
+const getUserSessionsQuery = selectSessionsQuery + ` WHERE user_id=$1 ORDER BY created_at`
+
+func (r *TradingSessionsPostgres) GetUserSessions(userID int) ([]models.TradingSession, error) {
+	sessions, err := r.querySessions(getUserSessionsQuery, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrGetUserSessions, err)
+	}
+	return sessions, nil
+}
+
+const getActiveSessionsQuery = selectSessionsQuery + ` WHERE state NOT IN ($1, $2, $3) ORDER BY created_at`
+
+func (r *TradingSessionsPostgres) GetActiveSessions() ([]models.TradingSession, error) {
+	sessions, err := r.querySessions(getActiveSessionsQuery,
+		models.TradingSessionStopped, models.TradingSessionFinished, models.TradingSessionFailed)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrGetActiveSessions, err)
+	}
+	return sessions, nil
+}
+
+func (r *TradingSessionsPostgres) querySessions(query string, args ...interface{}) ([]models.TradingSession, error) {
+	rows, err := r.db.Queryx(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := make([]models.TradingSession, 0)
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanSession(row rowScanner) (models.TradingSession, error) {
+	var session models.TradingSession
+
+	err := row.Scan(&session.ID, &session.UserID, &session.Details.OrderType, &session.Details.Symbol,
+		&session.Details.Side, &session.Details.Size, &session.Details.Strategy, &session.Details.Params,
+		&session.Details.Paper, &session.Details.EntryStrategy, &session.Details.EntryParams, &session.EntryOrderID,
+		&session.EntryPrice, &session.EntryTime, &session.ExitOrderID, &session.RoundTrips, &session.State,
+		&session.Error, &session.CreatedAt, &session.UpdatedAt)
+	return session, err
+}
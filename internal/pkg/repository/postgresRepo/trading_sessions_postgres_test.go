@@ -0,0 +1,174 @@
+package postgresRepo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/internal/pkg/models"
+	"trade-bot/internal/pkg/tradeAlgorithm/types"
+)
+
+var sessionColumns = []string{"id", "user_id", "order_type", "symbol", "side", "size", "strategy",
+	"params", "paper", "entry_strategy", "entry_params", "entry_order_id", "entry_price", "entry_time",
+	"exit_order_id", "round_trips", "state", "error", "created_at", "updated_at"}
+
+func testSession() models.TradingSession {
+	createdAt := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	return models.TradingSession{
+		ID:     "1",
+		UserID: 1,
+		Details: types.TradingDetails{
+			OrderType:     "mkt",
+			Symbol:        "pi_xbtusd",
+			Side:          "buy",
+			Size:          decimal.NewFromInt(10),
+			Strategy:      "stop_loss_take_profit",
+			Params:        types.StrategyParams{"stop_loss_border": float64(5), "take_profit_border": float64(10)},
+			EntryStrategy: "rsi",
+			EntryParams:   types.StrategyParams{"period": float64(14)},
+		},
+		State:        models.TradingSessionRunning,
+		EntryOrderID: "order",
+		EntryPrice:   decimal.NewFromInt(100),
+		EntryTime:    createdAt,
+		RoundTrips:   2,
+		CreatedAt:    createdAt,
+		UpdatedAt:    createdAt,
+	}
+}
+
+func TestTradingSessionsPostgres_CreateSession(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	r := NewTradingSessionsPostgres(sqlxDB)
+
+	tests := []struct {
+		name    string
+		session models.TradingSession
+		mock    func(session models.TradingSession)
+		wantErr bool
+	}{
+		{
+			name:    "OK",
+			session: testSession(),
+			mock: func(s models.TradingSession) {
+				mock.ExpectExec("INSERT INTO trading_sessions").
+					WithArgs(s.ID, s.UserID, s.Details.OrderType, s.Details.Symbol, s.Details.Side, s.Details.Size,
+						s.Details.Strategy, []byte(`{"stop_loss_border":5,"take_profit_border":10}`), s.Details.Paper,
+						s.Details.EntryStrategy, []byte(`{"period":14}`), s.EntryOrderID, s.EntryPrice, s.EntryTime,
+						s.ExitOrderID, s.RoundTrips, s.State, s.Error, s.CreatedAt, s.UpdatedAt).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+		{
+			name:    "Insert error",
+			session: testSession(),
+			mock: func(s models.TradingSession) {
+				mock.ExpectExec("INSERT INTO trading_sessions").WillReturnError(errors.New("insert error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock(test.session)
+
+			err := r.CreateSession(test.session)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestTradingSessionsPostgres_GetActiveSessions(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	r := NewTradingSessionsPostgres(sqlxDB)
+
+	tests := []struct {
+		name    string
+		session models.TradingSession
+		mock    func(session models.TradingSession)
+		want    []models.TradingSession
+		wantErr bool
+	}{
+		{
+			name:    "OK",
+			session: testSession(),
+			mock: func(s models.TradingSession) {
+				rows := sqlmock.NewRows(sessionColumns).
+					AddRow(s.ID, s.UserID, s.Details.OrderType, s.Details.Symbol, s.Details.Side, s.Details.Size,
+						s.Details.Strategy, []byte(`{"stop_loss_border":5,"take_profit_border":10}`), s.Details.Paper,
+						s.Details.EntryStrategy, []byte(`{"period":14}`), s.EntryOrderID, s.EntryPrice, s.EntryTime,
+						s.ExitOrderID, s.RoundTrips, s.State, s.Error, s.CreatedAt, s.UpdatedAt)
+				mock.ExpectQuery("SELECT (.+) FROM trading_sessions WHERE state NOT IN").
+					WithArgs(models.TradingSessionStopped, models.TradingSessionFinished, models.TradingSessionFailed).
+					WillReturnRows(rows)
+			},
+			want: []models.TradingSession{testSession()},
+		},
+		{
+			name:    "Query error",
+			session: testSession(),
+			mock: func(s models.TradingSession) {
+				mock.ExpectQuery("SELECT (.+) FROM trading_sessions WHERE state NOT IN").
+					WillReturnError(errors.New("select error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.mock(test.session)
+
+			got, err := r.GetActiveSessions()
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, got)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestTradingSessionsPostgres_GetSession(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer mockDB.Close()
+	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
+
+	r := NewTradingSessionsPostgres(sqlxDB)
+
+	mock.ExpectQuery("SELECT (.+) FROM trading_sessions WHERE id").
+		WithArgs("1").WillReturnRows(sqlmock.NewRows(sessionColumns))
+
+	_, err = r.GetSession("1")
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
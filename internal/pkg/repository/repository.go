@@ -28,10 +28,25 @@ type KrakenOrdersManager interface {
 	GetOrder(orderID string) (models.Order, error)
 }
 
+type TradingSessions interface {
+	CreateSession(session models.TradingSession) error
+	UpdateSession(session models.TradingSession) error
+	GetSession(sessionID string) (models.TradingSession, error)
+	GetUserSessions(userID int) ([]models.TradingSession, error)
+	GetActiveSessions() ([]models.TradingSession, error)
+}
+
+// Health reports whether storage is reachable
+type Health interface {
+	Ping() error
+}
+
 type Repository struct {
 	Authorization
 	JWT
 	KrakenOrdersManager
+	TradingSessions
+	Health
 }
 
 func NewRepository(db *sqlx.DB, jwtDB *redis.Client) *Repository {
@@ -39,5 +54,7 @@ func NewRepository(db *sqlx.DB, jwtDB *redis.Client) *Repository {
 		Authorization:       postgresRepo.NewAuthPostgres(db),
 		JWT:                 redisRepo.NewJWTRedis(jwtDB),
 		KrakenOrdersManager: postgresRepo.NewKrakenOrdersManagerPostgres(db),
+		TradingSessions:     postgresRepo.NewTradingSessionsPostgres(db),
+		Health:              postgresRepo.NewHealthPostgres(db),
 	}
 }
@@ -8,6 +8,7 @@ import (
 
 	"trade-bot/internal/pkg/models"
 	"trade-bot/internal/pkg/repository"
+	"trade-bot/internal/pkg/web"
 	"trade-bot/pkg/utils"
 )
 
@@ -21,12 +22,14 @@ var (
 )
 
 type AuthService struct {
-	repo    repository.Authorization
-	jwtRepo repository.JWT
+	repo          repository.Authorization
+	jwtRepo       repository.JWT
+	ordersManager web.KrakenOrdersManagerFactory
 }
 
-func NewAuthService(repo repository.Authorization, jwtRepo repository.JWT) *AuthService {
-	return &AuthService{repo: repo, jwtRepo: jwtRepo}
+func NewAuthService(repo repository.Authorization, jwtRepo repository.JWT,
+	ordersManager web.KrakenOrdersManagerFactory) *AuthService {
+	return &AuthService{repo: repo, jwtRepo: jwtRepo, ordersManager: ordersManager}
 }
 
 func (s *AuthService) CreateUser(user models.User) (int, error) {
@@ -81,6 +84,7 @@ func (s *AuthService) LogoutUser(token string) error {
 	if err := s.jwtRepo.DeleteJWT(ad); err != nil {
 		return fmt.Errorf("%s: %w", ErrLogoutUser, err)
 	}
+	s.ordersManager.EvictOrdersManager(int(ad.UserID))
 	return nil
 }
 
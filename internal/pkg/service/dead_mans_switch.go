@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"trade-bot/configs"
+	"trade-bot/internal/pkg/models"
+	"trade-bot/internal/pkg/repository"
+)
+
+var (
+	ErrRefreshDeadMansSwitch = errors.New("refresh dead man's switch")
+	ErrDisarmDeadMansSwitch  = errors.New("disarm dead man's switch")
+	ErrBotIsNotHealthy       = errors.New("bot is not healthy, dead man's switch is left to expire")
+)
+
+const (
+	defaultDeadMansSwitchTimeout         = 60
+	defaultDeadMansSwitchRefreshInterval = 15
+)
+
+// liveSessions reports users which have trading sessions sending real orders to kraken
+type liveSessions interface {
+	LiveSessionUsers() []int
+}
+
+// DeadMansSwitchService keeps kraken cancelallordersafter timer armed for every user with live trading sessions.
+// Timer is not refreshed on shutdown or when bot is not healthy, so kraken cancels resting orders by itself
+type DeadMansSwitchService struct {
+	orders   KrakenOrdersManager
+	sessions liveSessions
+	health   repository.Health
+	timeout  uint
+	interval time.Duration
+
+	mu       sync.RWMutex
+	healthy  bool
+	statuses map[int]models.DeadMansSwitchStatus
+
+	// ctx is cancelled on Shutdown, so refresh in progress is interrupted
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewDeadMansSwitchService(orders KrakenOrdersManager, sessions liveSessions, health repository.Health,
+	config configs.DeadMansSwitchConfiguration) *DeadMansSwitchService {
+	timeout := config.TimeoutInSeconds
+	if timeout == 0 {
+		timeout = defaultDeadMansSwitchTimeout
+	}
+	interval := config.RefreshIntervalInSeconds
+	if interval == 0 || interval >= timeout {
+		interval = timeout / 4
+		if interval == 0 {
+			interval = 1
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &DeadMansSwitchService{
+		orders:   orders,
+		sessions: sessions,
+		health:   health,
+		timeout:  timeout,
+		interval: time.Duration(interval) * time.Second,
+		statuses: make(map[int]models.DeadMansSwitchStatus),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Start refreshes timers right away and then on every interval until Shutdown
+func (d *DeadMansSwitchService) Start() {
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+
+		ticker := time.NewTicker(d.interval)
+		defer ticker.Stop()
+
+		for {
+			d.Refresh(d.ctx)
+
+			select {
+			case <-d.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Shutdown stops refreshing, armed timers are left to expire
+func (d *DeadMansSwitchService) Shutdown() {
+	d.cancel()
+	d.wg.Wait()
+}
+
+// Refresh arms timer of every user with live sessions and disarms timer of users which don't have them anymore
+func (d *DeadMansSwitchService) Refresh(ctx context.Context) {
+	if err := d.health.Ping(); err != nil {
+		log.Errorf("%s: %s: %s", ErrRefreshDeadMansSwitch, ErrBotIsNotHealthy, err)
+		d.mu.Lock()
+		d.healthy = false
+		d.mu.Unlock()
+		return
+	}
+
+	live := make(map[int]struct{})
+	for _, userID := range d.sessions.LiveSessionUsers() {
+		live[userID] = struct{}{}
+	}
+
+	d.mu.RLock()
+	previous := make(map[int]models.DeadMansSwitchStatus, len(d.statuses))
+	armed := make([]int, 0, len(d.statuses))
+	for userID, status := range d.statuses {
+		previous[userID] = status
+		if _, ok := live[userID]; !ok && status.Armed {
+			armed = append(armed, userID)
+		}
+	}
+	d.mu.RUnlock()
+
+	statuses := make(map[int]models.DeadMansSwitchStatus, len(live)+len(armed))
+	for userID := range live {
+		statuses[userID] = d.arm(ctx, userID, d.timeout, previous[userID])
+	}
+	for _, userID := range armed {
+		statuses[userID] = d.arm(ctx, userID, 0, previous[userID])
+	}
+
+	d.mu.Lock()
+	d.healthy = true
+	for userID, status := range statuses {
+		d.statuses[userID] = status
+	}
+	d.mu.Unlock()
+}
+
+// arm sets timer of user, zero timeout disarms it. Timer of kraken is unchanged when request fails,
+// so status keeps previous timer then and timer which failed to disarm is disarmed again on next refresh
+func (d *DeadMansSwitchService) arm(ctx context.Context, userID int, timeout uint, previous models.DeadMansSwitchStatus) models.DeadMansSwitchStatus {
+	status := previous
+	status.UserID = userID
+	status.Healthy = true
+
+	krakenStatus, err := d.orders.CancelAllOrdersAfter(ctx, userID, timeout)
+	if err != nil {
+		errType := ErrRefreshDeadMansSwitch
+		if timeout == 0 {
+			errType = ErrDisarmDeadMansSwitch
+		}
+		log.Errorf("%s: user %d: %s", errType, userID, err)
+		status.Error = err.Error()
+		return status
+	}
+
+	status.Armed = timeout != 0
+	status.TimeoutSeconds = timeout
+	status.Error = ""
+	status.LastRefresh = time.Now().UTC()
+	status.TriggerTime = krakenStatus.TriggerTime
+	return status
+}
+
+func (d *DeadMansSwitchService) GetDeadMansSwitchStatus(userID int) models.DeadMansSwitchStatus {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	status, ok := d.statuses[userID]
+	if !ok {
+		status = models.DeadMansSwitchStatus{UserID: userID}
+	}
+	status.Healthy = d.healthy
+	return status
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/configs"
+	mockService "trade-bot/internal/pkg/service/mocks"
+	"trade-bot/pkg/krakenFuturesSDK"
+)
+
+type fakeLiveSessions []int
+
+func (f *fakeLiveSessions) LiveSessionUsers() []int {
+	return *f
+}
+
+type fakeHealth struct {
+	err error
+}
+
+func (f *fakeHealth) Ping() error {
+	return f.err
+}
+
+func TestDeadMansSwitchService_Refresh(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	orders := mockService.NewMockKrakenOrdersManager(c)
+	sessions := &fakeLiveSessions{1, 2}
+	health := &fakeHealth{}
+	d := NewDeadMansSwitchService(orders, sessions, health, configs.DeadMansSwitchConfiguration{TimeoutInSeconds: 60})
+
+	// users with live sessions are armed
+	orders.EXPECT().CancelAllOrdersAfter(gomock.Any(), 1, uint(60)).Return(krakenFuturesSDK.DeadManSwitchStatus{TriggerTime: "t1"}, nil)
+	orders.EXPECT().CancelAllOrdersAfter(gomock.Any(), 2, uint(60)).Return(krakenFuturesSDK.DeadManSwitchStatus{}, errors.New("kraken error"))
+	d.Refresh(context.Background())
+
+	status := d.GetDeadMansSwitchStatus(1)
+	assert.True(t, status.Armed)
+	assert.True(t, status.Healthy)
+	assert.Equal(t, "t1", status.TriggerTime)
+	// timer which kraken did not set is not reported as armed
+	status = d.GetDeadMansSwitchStatus(2)
+	assert.False(t, status.Armed)
+	assert.Equal(t, "kraken error", status.Error)
+
+	// timer which failed to disarm is still armed, so it is disarmed again on next refresh
+	*sessions = fakeLiveSessions{2}
+	orders.EXPECT().CancelAllOrdersAfter(gomock.Any(), 1, uint(0)).Return(krakenFuturesSDK.DeadManSwitchStatus{}, errors.New("kraken error"))
+	orders.EXPECT().CancelAllOrdersAfter(gomock.Any(), 2, uint(60)).Return(krakenFuturesSDK.DeadManSwitchStatus{TriggerTime: "t2"}, nil)
+	d.Refresh(context.Background())
+	status = d.GetDeadMansSwitchStatus(1)
+	assert.True(t, status.Armed)
+	assert.Equal(t, "t1", status.TriggerTime)
+	assert.Empty(t, d.GetDeadMansSwitchStatus(2).Error)
+
+	// user without live sessions is disarmed once
+	orders.EXPECT().CancelAllOrdersAfter(gomock.Any(), 1, uint(0)).Return(krakenFuturesSDK.DeadManSwitchStatus{}, nil)
+	orders.EXPECT().CancelAllOrdersAfter(gomock.Any(), 2, uint(60)).Return(krakenFuturesSDK.DeadManSwitchStatus{TriggerTime: "t2"}, nil)
+	d.Refresh(context.Background())
+	assert.False(t, d.GetDeadMansSwitchStatus(1).Armed)
+
+	orders.EXPECT().CancelAllOrdersAfter(gomock.Any(), 2, uint(60)).Return(krakenFuturesSDK.DeadManSwitchStatus{TriggerTime: "t3"}, nil)
+	d.Refresh(context.Background())
+
+	// timers are not refreshed while bot is not healthy
+	health.err = errors.New("db is down")
+	d.Refresh(context.Background())
+
+	status = d.GetDeadMansSwitchStatus(2)
+	assert.False(t, status.Healthy)
+	assert.Equal(t, "t3", status.TriggerTime)
+	assert.False(t, d.GetDeadMansSwitchStatus(3).Armed)
+}
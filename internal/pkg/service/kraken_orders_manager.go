@@ -4,12 +4,11 @@ import (
 	"context"
 	"fmt"
 	"time"
-	"trade-bot/internal/pkg/models"
 
 	"github.com/pkg/errors"
 
+	"trade-bot/internal/pkg/models"
 	"trade-bot/internal/pkg/repository"
-	"trade-bot/internal/pkg/tradeAlgorithm"
 	"trade-bot/internal/pkg/tradeAlgorithm/types"
 	"trade-bot/internal/pkg/web"
 	"trade-bot/pkg/krakenFuturesSDK"
@@ -17,73 +16,234 @@ import (
 
 var (
 	ErrSendOrderServiceMethod    = errors.New("send order service method")
+	ErrSendPaperOrder            = errors.New("send paper order service method")
+	ErrSendExitOrder             = errors.New("send exit order service method")
+	ErrBatchOrderServiceMethod   = errors.New("batch order service method")
+	ErrCancelAllOrdersAfter      = errors.New("cancel all orders after service method")
+	ErrGetRateLimitUsage         = errors.New("get rate limit usage service method")
 	ErrStartTradingService       = errors.New("start trading service")
 	ErrUnableToParseBuyTimestamp = errors.New("unable to convert buy timestamp")
+	ErrGetUserOrdersManager      = errors.New("get user orders manager")
+	ErrValidateOrder             = errors.New("validate order")
 )
 
 type KrakenOrdersManagerService struct {
-	sdk    web.KrakenOrdersManager
-	repo   repository.KrakenOrdersManager
-	trader tradeAlgorithm.Trader
+	sdk         web.KrakenOrdersManagerFactory
+	paper       web.KrakenPaperOrdersManagerFactory
+	instruments web.KrakenInstruments
+	repo        repository.KrakenOrdersManager
+	authRepo    repository.Authorization
 }
 
-func NewKrakenOrdersManagerService(sdk web.KrakenOrdersManager, repo repository.KrakenOrdersManager,
-	trader tradeAlgorithm.Trader) *KrakenOrdersManagerService {
-	return &KrakenOrdersManagerService{sdk: sdk, repo: repo, trader: trader}
+func NewKrakenOrdersManagerService(sdk web.KrakenOrdersManagerFactory, paper web.KrakenPaperOrdersManagerFactory,
+	instruments web.KrakenInstruments, repo repository.KrakenOrdersManager, authRepo repository.Authorization) *KrakenOrdersManagerService {
+	return &KrakenOrdersManagerService{sdk: sdk, paper: paper, instruments: instruments, repo: repo, authRepo: authRepo}
 }
 
-func (k *KrakenOrdersManagerService) SendOrder(userID int, args krakenFuturesSDK.SendOrderArguments) (models.Order, error) {
-	sendStatus, err := k.sdk.SendOrder(args)
+// ordersManager returns orders manager signed with user's own kraken api keys. Keys are loaded every time,
+// so cached manager is reused only while keys of user are the same
+func (k *KrakenOrdersManagerService) ordersManager(userID int) (web.KrakenOrdersManager, error) {
+	publicAPIKey, privateAPIKey, err := k.authRepo.GetUserAPIKeys(userID)
 	if err != nil {
-		return models.Order{}, fmt.Errorf("%s: %w", ErrSendOrderServiceMethod, err)
+		return nil, fmt.Errorf("%s: %w", ErrGetUserOrdersManager, err)
 	}
 
-	order, err := k.sdk.ParseSendStatusToExecutedOrder(userID, sendStatus)
+	return k.sdk.OrdersManager(userID, publicAPIKey, privateAPIKey), nil
+}
+
+func (k *KrakenOrdersManagerService) SendOrder(ctx context.Context, userID int, args krakenFuturesSDK.SendOrderArguments) (models.Order, error) {
+	sdk, err := k.ordersManager(userID)
 	if err != nil {
 		return models.Order{}, fmt.Errorf("%s: %w", ErrSendOrderServiceMethod, err)
 	}
 
-	if err := k.repo.CreateOrder(userID, order); err != nil {
+	order, err := k.sendOrder(ctx, userID, sdk, args, "")
+	if err != nil {
 		return models.Order{}, fmt.Errorf("%s: %w", ErrSendOrderServiceMethod, err)
 	}
+	return order, nil
+}
 
+// SendPaperOrder executes order on user's virtual account, order is stored like the real one but marked as paper
+func (k *KrakenOrdersManagerService) SendPaperOrder(ctx context.Context, userID int, args krakenFuturesSDK.SendOrderArguments) (models.Order, error) {
+	order, err := k.sendOrder(ctx, userID, k.paper.PaperOrdersManager(userID), args, "")
+	if err != nil {
+		return models.Order{}, fmt.Errorf("%s: %w", ErrSendPaperOrder, err)
+	}
 	return order, nil
 }
 
-func (k *KrakenOrdersManagerService) StartTrading(ctx context.Context, userID int, details types.TradingDetails) (models.Order, error) {
-	sendArgs := krakenFuturesSDK.SendOrderArguments{
-		OrderType: details.OrderType,
-		Symbol:    details.Symbol,
-		Side:      details.Side,
-		Size:      details.Size,
+// SendExitOrder sends order which closes position of trading session and stores it with the reason of exit
+func (k *KrakenOrdersManagerService) SendExitOrder(ctx context.Context, userID int, paper bool, args krakenFuturesSDK.SendOrderArguments,
+	reason types.ExitReason) (models.Order, error) {
+	var (
+		sdk web.KrakenOrdersManager
+		err error
+	)
+	if paper {
+		sdk = k.paper.PaperOrdersManager(userID)
+	} else if sdk, err = k.ordersManager(userID); err != nil {
+		return models.Order{}, fmt.Errorf("%s: %w", ErrSendExitOrder, err)
 	}
 
-	startOrder, err := k.SendOrder(userID, sendArgs)
+	order, err := k.sendOrder(ctx, userID, sdk, args, reason)
 	if err != nil {
-		return models.Order{}, fmt.Errorf("%s: %w", ErrStartTradingService, err)
+		return models.Order{}, fmt.Errorf("%s: %w", ErrSendExitOrder, err)
 	}
+	return order, nil
+}
 
-	details.BuyPrice = startOrder.Price
-	buyTime, err := time.Parse(time.RFC3339, startOrder.Timestamp)
+// CancelAllOrdersAfter arms dead man's switch on user's kraken account, zero timeout disarms it
+func (k *KrakenOrdersManagerService) CancelAllOrdersAfter(ctx context.Context, userID int, timeout uint) (krakenFuturesSDK.DeadManSwitchStatus, error) {
+	sdk, err := k.ordersManager(userID)
 	if err != nil {
-		return models.Order{}, fmt.Errorf("%s: %w", ErrUnableToParseBuyTimestamp, err)
+		return krakenFuturesSDK.DeadManSwitchStatus{}, fmt.Errorf("%s: %w", ErrCancelAllOrdersAfter, err)
 	}
 
-	if err := k.trader.StartAnalyzing(ctx, buyTime, details); err != nil {
-		return models.Order{}, fmt.Errorf("%s: %w", ErrStartTradingService, err)
+	status, err := sdk.CancelAllOrdersAfter(ctx, timeout)
+	if err != nil {
+		return krakenFuturesSDK.DeadManSwitchStatus{}, fmt.Errorf("%s: %w", ErrCancelAllOrdersAfter, err)
 	}
+	return status, nil
+}
 
-	opositeArgs := sendArgs
-	opositeArgs.ChangeToOpositeOrderSide()
+// BatchOrder sends several instructions in one request and stores every order which was placed or executed by it
+func (k *KrakenOrdersManagerService) BatchOrder(ctx context.Context, userID int, args krakenFuturesSDK.BatchOrderArguments) (
+	[]krakenFuturesSDK.BatchStatus, []models.Order, error) {
+	sdk, err := k.ordersManager(userID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", ErrBatchOrderServiceMethod, err)
+	}
 
-	finishOrder, err := k.SendOrder(userID, opositeArgs)
+	args, err = k.validateBatch(ctx, args)
 	if err != nil {
-		return models.Order{}, fmt.Errorf("%s: %w", ErrStartTradingService, err)
+		return nil, nil, fmt.Errorf("%s: %w", ErrBatchOrderServiceMethod, err)
 	}
 
-	return finishOrder, nil
+	statuses, err := sdk.BatchOrder(ctx, args)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", ErrBatchOrderServiceMethod, err)
+	}
+
+	orders := sdk.ParseBatchStatusToOrders(userID, statuses)
+	for _, order := range orders {
+		if err := k.repo.CreateOrder(userID, order); err != nil {
+			return statuses, orders, fmt.Errorf("%s: %w", ErrBatchOrderServiceMethod, err)
+		}
+	}
+
+	return statuses, orders, nil
+}
+
+func (k *KrakenOrdersManagerService) sendOrder(ctx context.Context, userID int, sdk web.KrakenOrdersManager,
+	args krakenFuturesSDK.SendOrderArguments, reason types.ExitReason) (models.Order, error) {
+	// order which closes or reduces position is not held back by catalog, so position can be closed
+	// even when catalog can't be loaded or instrument was delisted meanwhile
+	if !args.ReduceOnly && reason == "" {
+		var err error
+		if args, err = k.validateOrder(ctx, args); err != nil {
+			return models.Order{}, err
+		}
+	}
+
+	sendStatus, err := sdk.SendOrder(ctx, args)
+	var apiErr *krakenFuturesSDK.APIError
+	if reason != "" && errors.As(err, &apiErr) && apiErr.Code == krakenFuturesSDK.CodeClientOrderIDAlreadyExist {
+		// exit order is repeated with the same client order ID, so it was executed by the earlier attempt
+		// whose response was lost
+		return k.executedExitOrder(ctx, userID, sdk, args.CliOrderID, reason)
+	}
+	if err != nil {
+		return models.Order{}, err
+	}
+
+	order, err := sdk.ParseSendStatusToExecutedOrder(userID, sendStatus)
+	if err != nil {
+		return models.Order{}, err
+	}
+
+	order.ExitReason = reason
+
+	if err := k.repo.CreateOrder(userID, order); err != nil {
+		return models.Order{}, err
+	}
+
+	return order, nil
+}
+
+// executedExitOrder returns exit order which was already executed by kraken, it is stored unless it already was
+func (k *KrakenOrdersManagerService) executedExitOrder(ctx context.Context, userID int, sdk web.KrakenOrdersManager,
+	cliOrdID string, reason types.ExitReason) (models.Order, error) {
+	order, err := sdk.ExecutedOrder(ctx, userID, cliOrdID)
+	if err != nil {
+		return models.Order{}, err
+	}
+
+	if stored, err := k.repo.GetOrder(order.ID); err == nil {
+		return stored, nil
+	}
+
+	order.ExitReason = reason
+	if err := k.repo.CreateOrder(userID, order); err != nil {
+		return models.Order{}, err
+	}
+	return order, nil
+}
+
+// validateOrder checks order against cached instrument before it goes to kraken and rounds its prices to tick size,
+// so invalid order is rejected with krakenFuturesSDK.ValidationError without kraken round trip
+func (k *KrakenOrdersManagerService) validateOrder(ctx context.Context, args krakenFuturesSDK.SendOrderArguments) (krakenFuturesSDK.SendOrderArguments, error) {
+	instrument, err := k.instruments.Instrument(ctx, args.Symbol)
+	if err != nil {
+		return args, fmt.Errorf("%s: %w", ErrValidateOrder, err)
+	}
+
+	args, err = instrument.ValidateOrder(args, time.Now().UTC())
+	if err != nil {
+		return args, fmt.Errorf("%s: %w", ErrValidateOrder, err)
+	}
+	return args, nil
+}
+
+// validateBatch validates every send instruction of batch which does not reduce position,
+// edit has no symbol, so it is sent as is
+func (k *KrakenOrdersManagerService) validateBatch(ctx context.Context, args krakenFuturesSDK.BatchOrderArguments) (krakenFuturesSDK.BatchOrderArguments, error) {
+	instructions := make([]krakenFuturesSDK.BatchInstruction, len(args.Instructions))
+	for i, instruction := range args.Instructions {
+		if instruction.Order == krakenFuturesSDK.BatchSend && !instruction.ReduceOnly {
+			order, err := k.validateOrder(ctx, krakenFuturesSDK.SendOrderArguments{
+				OrderType:  instruction.OrderType,
+				Symbol:     instruction.Symbol,
+				Side:       instruction.Side,
+				Size:       instruction.Size,
+				LimitPrice: instruction.LimitPrice,
+				StopPrice:  instruction.StopPrice,
+			})
+			if err != nil {
+				return args, err
+			}
+			instruction.LimitPrice, instruction.StopPrice = order.LimitPrice, order.StopPrice
+		}
+		instructions[i] = instruction
+	}
+
+	args.Instructions = instructions
+	return args, nil
 }
 
 func (k *KrakenOrdersManagerService) GetUserOrders(userID int) ([]models.Order, error) {
 	return k.repo.GetUserOrders(userID)
 }
+
+// GetRateLimitUsage returns budget of kraken private endpoints which is left for user's api key
+func (k *KrakenOrdersManagerService) GetRateLimitUsage(userID int) (krakenFuturesSDK.RateLimitUsage, error) {
+	sdk, err := k.ordersManager(userID)
+	if err != nil {
+		return krakenFuturesSDK.RateLimitUsage{}, fmt.Errorf("%s: %w", ErrGetRateLimitUsage, err)
+	}
+	return sdk.RateLimitUsage(), nil
+}
+
+func (k *KrakenOrdersManagerService) GetPaperAccount(userID int) models.PaperAccount {
+	return k.paper.PaperAccount(userID)
+}
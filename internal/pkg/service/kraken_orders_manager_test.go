@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/internal/pkg/models"
+	"trade-bot/internal/pkg/repository"
+	"trade-bot/internal/pkg/tradeAlgorithm/types"
+	"trade-bot/internal/pkg/web"
+	"trade-bot/pkg/krakenFuturesSDK"
+)
+
+type fakeInstruments map[string]krakenFuturesSDK.Instrument
+
+func (f fakeInstruments) Start() {}
+
+func (f fakeInstruments) Shutdown() {}
+
+func (f fakeInstruments) TickSize(ctx context.Context, symbol string) (decimal.Decimal, error) {
+	instrument, err := f.Instrument(ctx, symbol)
+	return instrument.TickSize, err
+}
+
+func (f fakeInstruments) Instrument(_ context.Context, symbol string) (krakenFuturesSDK.Instrument, error) {
+	instrument, ok := f[symbol]
+	if !ok {
+		return krakenFuturesSDK.Instrument{}, krakenFuturesSDK.NewValidationError(symbol,
+			krakenFuturesSDK.CodeUnknownInstrument, "kraken futures does not list instrument")
+	}
+	return instrument, nil
+}
+
+func tradeableInstrument(symbol, tickSize string, precision int) krakenFuturesSDK.Instrument {
+	return krakenFuturesSDK.Instrument{
+		Symbol:                      symbol,
+		Tradeable:                   true,
+		TickSize:                    decimal.RequireFromString(tickSize),
+		ContractSize:                decimal.NewFromInt(1),
+		ContractValueTradePrecision: precision,
+		MarginLevels:                []krakenFuturesSDK.MarginLevel{{Contracts: decimal.Zero}},
+	}
+}
+
+func TestKrakenOrdersManagerService_validateOrder(t *testing.T) {
+	d := decimal.RequireFromString
+	suspended := tradeableInstrument("pi_ethusd", "0.05", 0)
+	suspended.Tradeable = false
+	k := NewKrakenOrdersManagerService(nil, nil, fakeInstruments{
+		"pi_xbtusd": tradeableInstrument("pi_xbtusd", "0.5", 0),
+		"pf_ethusd": tradeableInstrument("pf_ethusd", "0.01", 3),
+		"pi_ethusd": suspended,
+	}, nil, nil)
+
+	tests := []struct {
+		name           string
+		args           krakenFuturesSDK.SendOrderArguments
+		wantLimitPrice string
+		wantStopPrice  string
+		wantCode       string
+	}{
+		{
+			name: "Prices are rounded to tick size",
+			args: krakenFuturesSDK.SendOrderArguments{OrderType: "stp", Symbol: "pi_xbtusd", Size: d("10"),
+				LimitPrice: d("41234.26"), StopPrice: d("41000.7")},
+			wantLimitPrice: "41234.5",
+			wantStopPrice:  "41000.5",
+		},
+		{
+			name:           "Fractional size",
+			args:           krakenFuturesSDK.SendOrderArguments{OrderType: "lmt", Symbol: "pf_ethusd", Size: d("0.015"), LimitPrice: d("3012.345")},
+			wantLimitPrice: "3012.35",
+			wantStopPrice:  "0",
+		},
+		{
+			name:     "Fractional size of whole contracts instrument",
+			args:     krakenFuturesSDK.SendOrderArguments{OrderType: "mkt", Symbol: "pi_xbtusd", Size: d("1.5")},
+			wantCode: "invalidSize",
+		},
+		{
+			name:     "Too precise size",
+			args:     krakenFuturesSDK.SendOrderArguments{OrderType: "mkt", Symbol: "pf_ethusd", Size: d("0.0015")},
+			wantCode: "invalidSize",
+		},
+		{
+			name:     "Not tradeable instrument",
+			args:     krakenFuturesSDK.SendOrderArguments{OrderType: "mkt", Symbol: "pi_ethusd", Size: d("1")},
+			wantCode: "marketSuspended",
+		},
+		{
+			name:     "Unknown instrument",
+			args:     krakenFuturesSDK.SendOrderArguments{OrderType: "mkt", Symbol: "pi_dogeusd", Size: d("1")},
+			wantCode: krakenFuturesSDK.CodeUnknownInstrument,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := k.validateOrder(context.Background(), test.args)
+			if test.wantCode != "" {
+				var validationErr *krakenFuturesSDK.ValidationError
+				assert.True(t, errors.As(err, &validationErr))
+				assert.Equal(t, test.wantCode, validationErr.Code)
+				assert.Contains(t, err.Error(), ErrValidateOrder.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.args.Size, got.Size)
+			assert.Equal(t, test.wantLimitPrice, got.LimitPrice.String())
+			assert.Equal(t, test.wantStopPrice, got.StopPrice.String())
+		})
+	}
+}
+
+func TestKrakenOrdersManagerService_validateBatch(t *testing.T) {
+	d := decimal.RequireFromString
+	k := NewKrakenOrdersManagerService(nil, nil, fakeInstruments{
+		"pi_xbtusd": tradeableInstrument("pi_xbtusd", "0.5", 0),
+	}, nil, nil)
+
+	args := krakenFuturesSDK.BatchOrderArguments{Instructions: []krakenFuturesSDK.BatchInstruction{
+		{Order: krakenFuturesSDK.BatchSend, OrderType: "lmt", Symbol: "pi_xbtusd", Size: d("1"), LimitPrice: d("100.3")},
+		{Order: krakenFuturesSDK.BatchEdit, OrderID: "1", LimitPrice: d("100.3")},
+	}}
+
+	got, err := k.validateBatch(context.Background(), args)
+	assert.NoError(t, err)
+	assert.Equal(t, "100.5", got.Instructions[0].LimitPrice.String())
+	assert.Equal(t, "100.3", got.Instructions[1].LimitPrice.String())
+	// instructions of caller are not changed
+	assert.Equal(t, "100.3", args.Instructions[0].LimitPrice.String())
+
+	args.Instructions[0].Size = d("0.5")
+	_, err = k.validateBatch(context.Background(), args)
+	var validationErr *krakenFuturesSDK.ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, "invalidSize", validationErr.Code)
+}
+
+// fakeOrdersManager records orders it gets and reports every one of them executed, unless sendErr is set
+type fakeOrdersManager struct {
+	web.KrakenOrdersManager
+	sent    []krakenFuturesSDK.SendOrderArguments
+	sendErr error
+}
+
+func (f *fakeOrdersManager) SendOrder(_ context.Context, args krakenFuturesSDK.SendOrderArguments) (krakenFuturesSDK.SendStatus, error) {
+	f.sent = append(f.sent, args)
+	return krakenFuturesSDK.SendStatus{OrderID: args.CliOrderID}, f.sendErr
+}
+
+func (f *fakeOrdersManager) ExecutedOrder(_ context.Context, userID int, cliOrdID string) (models.Order, error) {
+	return models.Order{ID: "executed-" + cliOrdID, UserID: userID, ClientOrderID: cliOrdID}, nil
+}
+
+func (f *fakeOrdersManager) ParseSendStatusToExecutedOrder(userID int, sendStatus krakenFuturesSDK.SendStatus) (models.Order, error) {
+	return models.Order{ID: sendStatus.OrderID, UserID: userID}, nil
+}
+
+type fakeOrdersRepo struct {
+	repository.KrakenOrdersManager
+	created *[]models.Order
+}
+
+func (f fakeOrdersRepo) CreateOrder(_ int, order models.Order) error {
+	if f.created != nil {
+		*f.created = append(*f.created, order)
+	}
+	return nil
+}
+
+func (f fakeOrdersRepo) GetOrder(orderID string) (models.Order, error) {
+	if f.created != nil {
+		for _, order := range *f.created {
+			if order.ID == orderID {
+				return order, nil
+			}
+		}
+	}
+	return models.Order{}, errors.New("order not found")
+}
+
+func TestKrakenOrdersManagerService_sendOrder(t *testing.T) {
+	d := decimal.RequireFromString
+	k := NewKrakenOrdersManagerService(nil, nil, fakeInstruments{}, fakeOrdersRepo{}, nil)
+
+	tests := []struct {
+		name      string
+		args      krakenFuturesSDK.SendOrderArguments
+		reason    types.ExitReason
+		wantError bool
+	}{
+		{
+			name:      "Order of unknown instrument is rejected",
+			args:      krakenFuturesSDK.SendOrderArguments{OrderType: "mkt", Symbol: "pi_xbtusd", Size: d("1")},
+			wantError: true,
+		},
+		{
+			name: "Reduce only order is not validated",
+			args: krakenFuturesSDK.SendOrderArguments{OrderType: "mkt", Symbol: "pi_xbtusd", Size: d("1"), ReduceOnly: true},
+		},
+		{
+			name:   "Exit order is not validated",
+			args:   krakenFuturesSDK.SendOrderArguments{OrderType: "mkt", Symbol: "pi_xbtusd", Size: d("1")},
+			reason: types.ExitReasonCancelled,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sdk := &fakeOrdersManager{}
+			_, err := k.sendOrder(context.Background(), 1, sdk, test.args, test.reason)
+			if test.wantError {
+				assert.Error(t, err)
+				assert.Empty(t, sdk.sent)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, []krakenFuturesSDK.SendOrderArguments{test.args}, sdk.sent)
+		})
+	}
+}
+
+func TestKrakenOrdersManagerService_SendExitOrder_ClientOrderIDAlreadyExist(t *testing.T) {
+	var created []models.Order
+	k := NewKrakenOrdersManagerService(nil, nil, fakeInstruments{}, fakeOrdersRepo{created: &created}, nil)
+	args := krakenFuturesSDK.SendOrderArguments{OrderType: "mkt", Symbol: "pi_xbtusd", Size: decimal.NewFromInt(1),
+		CliOrderID: "session-exit-1"}
+	sdk := &fakeOrdersManager{sendErr: krakenFuturesSDK.NewStatusError(krakenFuturesSDK.CodeClientOrderIDAlreadyExist)}
+
+	// the earlier attempt was executed, so exit order is found and stored once however many times it is repeated
+	for i := 0; i < 2; i++ {
+		order, err := k.sendOrder(context.Background(), 1, sdk, args, types.ExitReasonTakeProfit)
+		assert.NoError(t, err)
+		assert.Equal(t, "executed-session-exit-1", order.ID)
+		assert.Equal(t, types.ExitReasonTakeProfit, order.ExitReason)
+	}
+	assert.Len(t, created, 1)
+
+	// order of user which repeats client order ID is rejected as it is
+	_, err := k.sendOrder(context.Background(), 1, sdk, krakenFuturesSDK.SendOrderArguments{
+		OrderType: "mkt", Symbol: "pi_xbtusd", Size: decimal.NewFromInt(1), CliOrderID: "mine", ReduceOnly: true}, "")
+	var apiErr *krakenFuturesSDK.APIError
+	assert.True(t, errors.As(err, &apiErr))
+}
@@ -8,6 +8,7 @@ import (
 	context "context"
 	reflect "reflect"
 	models "trade-bot/internal/pkg/models"
+	tradeAlgorithm "trade-bot/internal/pkg/tradeAlgorithm"
 	types "trade-bot/internal/pkg/tradeAlgorithm/types"
 	krakenFuturesSDK "trade-bot/pkg/krakenFuturesSDK"
 
@@ -135,32 +136,387 @@ func (m *MockKrakenOrdersManager) EXPECT() *MockKrakenOrdersManagerMockRecorder
 	return m.recorder
 }
 
+// BatchOrder mocks base method.
+func (m *MockKrakenOrdersManager) BatchOrder(ctx context.Context, userID int, args krakenFuturesSDK.BatchOrderArguments) ([]krakenFuturesSDK.BatchStatus, []models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchOrder", ctx, userID, args)
+	ret0, _ := ret[0].([]krakenFuturesSDK.BatchStatus)
+	ret1, _ := ret[1].([]models.Order)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// BatchOrder indicates an expected call of BatchOrder.
+func (mr *MockKrakenOrdersManagerMockRecorder) BatchOrder(ctx, userID, args interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchOrder", reflect.TypeOf((*MockKrakenOrdersManager)(nil).BatchOrder), ctx, userID, args)
+}
+
+// CancelAllOrdersAfter mocks base method.
+func (m *MockKrakenOrdersManager) CancelAllOrdersAfter(ctx context.Context, userID int, timeout uint) (krakenFuturesSDK.DeadManSwitchStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelAllOrdersAfter", ctx, userID, timeout)
+	ret0, _ := ret[0].(krakenFuturesSDK.DeadManSwitchStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelAllOrdersAfter indicates an expected call of CancelAllOrdersAfter.
+func (mr *MockKrakenOrdersManagerMockRecorder) CancelAllOrdersAfter(ctx, userID, timeout interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelAllOrdersAfter", reflect.TypeOf((*MockKrakenOrdersManager)(nil).CancelAllOrdersAfter), ctx, userID, timeout)
+}
+
+// GetPaperAccount mocks base method.
+func (m *MockKrakenOrdersManager) GetPaperAccount(userID int) models.PaperAccount {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaperAccount", userID)
+	ret0, _ := ret[0].(models.PaperAccount)
+	return ret0
+}
+
+// GetPaperAccount indicates an expected call of GetPaperAccount.
+func (mr *MockKrakenOrdersManagerMockRecorder) GetPaperAccount(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaperAccount", reflect.TypeOf((*MockKrakenOrdersManager)(nil).GetPaperAccount), userID)
+}
+
+// GetRateLimitUsage mocks base method.
+func (m *MockKrakenOrdersManager) GetRateLimitUsage(userID int) (krakenFuturesSDK.RateLimitUsage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRateLimitUsage", userID)
+	ret0, _ := ret[0].(krakenFuturesSDK.RateLimitUsage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRateLimitUsage indicates an expected call of GetRateLimitUsage.
+func (mr *MockKrakenOrdersManagerMockRecorder) GetRateLimitUsage(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRateLimitUsage", reflect.TypeOf((*MockKrakenOrdersManager)(nil).GetRateLimitUsage), userID)
+}
+
+// GetUserOrders mocks base method.
+func (m *MockKrakenOrdersManager) GetUserOrders(userID int) ([]models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserOrders", userID)
+	ret0, _ := ret[0].([]models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserOrders indicates an expected call of GetUserOrders.
+func (mr *MockKrakenOrdersManagerMockRecorder) GetUserOrders(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserOrders", reflect.TypeOf((*MockKrakenOrdersManager)(nil).GetUserOrders), userID)
+}
+
+// SendExitOrder mocks base method.
+func (m *MockKrakenOrdersManager) SendExitOrder(ctx context.Context, userID int, paper bool, args krakenFuturesSDK.SendOrderArguments, reason types.ExitReason) (models.Order, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendExitOrder", ctx, userID, paper, args, reason)
+	ret0, _ := ret[0].(models.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendExitOrder indicates an expected call of SendExitOrder.
+func (mr *MockKrakenOrdersManagerMockRecorder) SendExitOrder(ctx, userID, paper, args, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendExitOrder", reflect.TypeOf((*MockKrakenOrdersManager)(nil).SendExitOrder), ctx, userID, paper, args, reason)
+}
+
 // SendOrder mocks base method.
-func (m *MockKrakenOrdersManager) SendOrder(userID int, args krakenFuturesSDK.SendOrderArguments) (models.Order, error) {
+func (m *MockKrakenOrdersManager) SendOrder(ctx context.Context, userID int, args krakenFuturesSDK.SendOrderArguments) (models.Order, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SendOrder", userID, args)
+	ret := m.ctrl.Call(m, "SendOrder", ctx, userID, args)
 	ret0, _ := ret[0].(models.Order)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SendOrder indicates an expected call of SendOrder.
-func (mr *MockKrakenOrdersManagerMockRecorder) SendOrder(userID, args interface{}) *gomock.Call {
+func (mr *MockKrakenOrdersManagerMockRecorder) SendOrder(ctx, userID, args interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendOrder", reflect.TypeOf((*MockKrakenOrdersManager)(nil).SendOrder), userID, args)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendOrder", reflect.TypeOf((*MockKrakenOrdersManager)(nil).SendOrder), ctx, userID, args)
 }
 
-// StartTrading mocks base method.
-func (m *MockKrakenOrdersManager) StartTrading(ctx context.Context, userID int, details types.TradingDetails) (models.Order, error) {
+// SendPaperOrder mocks base method.
+func (m *MockKrakenOrdersManager) SendPaperOrder(ctx context.Context, userID int, args krakenFuturesSDK.SendOrderArguments) (models.Order, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "StartTrading", ctx, userID, details)
+	ret := m.ctrl.Call(m, "SendPaperOrder", ctx, userID, args)
 	ret0, _ := ret[0].(models.Order)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// StartTrading indicates an expected call of StartTrading.
-func (mr *MockKrakenOrdersManagerMockRecorder) StartTrading(ctx, userID, details interface{}) *gomock.Call {
+// SendPaperOrder indicates an expected call of SendPaperOrder.
+func (mr *MockKrakenOrdersManagerMockRecorder) SendPaperOrder(ctx, userID, args interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendPaperOrder", reflect.TypeOf((*MockKrakenOrdersManager)(nil).SendPaperOrder), ctx, userID, args)
+}
+
+// MockTradingSessions is a mock of TradingSessions interface.
+type MockTradingSessions struct {
+	ctrl     *gomock.Controller
+	recorder *MockTradingSessionsMockRecorder
+}
+
+// MockTradingSessionsMockRecorder is the mock recorder for MockTradingSessions.
+type MockTradingSessionsMockRecorder struct {
+	mock *MockTradingSessions
+}
+
+// NewMockTradingSessions creates a new mock instance.
+func NewMockTradingSessions(ctrl *gomock.Controller) *MockTradingSessions {
+	mock := &MockTradingSessions{ctrl: ctrl}
+	mock.recorder = &MockTradingSessionsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTradingSessions) EXPECT() *MockTradingSessionsMockRecorder {
+	return m.recorder
+}
+
+// GetSession mocks base method.
+func (m *MockTradingSessions) GetSession(userID int, sessionID string) (models.TradingSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSession", userID, sessionID)
+	ret0, _ := ret[0].(models.TradingSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSession indicates an expected call of GetSession.
+func (mr *MockTradingSessionsMockRecorder) GetSession(userID, sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockTradingSessions)(nil).GetSession), userID, sessionID)
+}
+
+// GetUserSessions mocks base method.
+func (m *MockTradingSessions) GetUserSessions(userID int) ([]models.TradingSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserSessions", userID)
+	ret0, _ := ret[0].([]models.TradingSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserSessions indicates an expected call of GetUserSessions.
+func (mr *MockTradingSessionsMockRecorder) GetUserSessions(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserSessions", reflect.TypeOf((*MockTradingSessions)(nil).GetUserSessions), userID)
+}
+
+// ResumeSessions mocks base method.
+func (m *MockTradingSessions) ResumeSessions() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResumeSessions")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResumeSessions indicates an expected call of ResumeSessions.
+func (mr *MockTradingSessionsMockRecorder) ResumeSessions() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResumeSessions", reflect.TypeOf((*MockTradingSessions)(nil).ResumeSessions))
+}
+
+// Shutdown mocks base method.
+func (m *MockTradingSessions) Shutdown() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Shutdown")
+}
+
+// Shutdown indicates an expected call of Shutdown.
+func (mr *MockTradingSessionsMockRecorder) Shutdown() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Shutdown", reflect.TypeOf((*MockTradingSessions)(nil).Shutdown))
+}
+
+// StartSession mocks base method.
+func (m *MockTradingSessions) StartSession(ctx context.Context, userID int, details types.TradingDetails) (models.TradingSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartSession", ctx, userID, details)
+	ret0, _ := ret[0].(models.TradingSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StartSession indicates an expected call of StartSession.
+func (mr *MockTradingSessionsMockRecorder) StartSession(ctx, userID, details interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartSession", reflect.TypeOf((*MockTradingSessions)(nil).StartSession), ctx, userID, details)
+}
+
+// StopSession mocks base method.
+func (m *MockTradingSessions) StopSession(userID int, sessionID string) (models.TradingSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StopSession", userID, sessionID)
+	ret0, _ := ret[0].(models.TradingSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StopSession indicates an expected call of StopSession.
+func (mr *MockTradingSessionsMockRecorder) StopSession(userID, sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopSession", reflect.TypeOf((*MockTradingSessions)(nil).StopSession), userID, sessionID)
+}
+
+// WatchSession mocks base method.
+func (m *MockTradingSessions) WatchSession(userID int, sessionID string) (<-chan models.TradingSessionEvent, func(), error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WatchSession", userID, sessionID)
+	ret0, _ := ret[0].(<-chan models.TradingSessionEvent)
+	ret1, _ := ret[1].(func())
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// WatchSession indicates an expected call of WatchSession.
+func (mr *MockTradingSessionsMockRecorder) WatchSession(userID, sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchSession", reflect.TypeOf((*MockTradingSessions)(nil).WatchSession), userID, sessionID)
+}
+
+// MockStrategies is a mock of Strategies interface.
+type MockStrategies struct {
+	ctrl     *gomock.Controller
+	recorder *MockStrategiesMockRecorder
+}
+
+// MockStrategiesMockRecorder is the mock recorder for MockStrategies.
+type MockStrategiesMockRecorder struct {
+	mock *MockStrategies
+}
+
+// NewMockStrategies creates a new mock instance.
+func NewMockStrategies(ctrl *gomock.Controller) *MockStrategies {
+	mock := &MockStrategies{ctrl: ctrl}
+	mock.recorder = &MockStrategiesMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStrategies) EXPECT() *MockStrategiesMockRecorder {
+	return m.recorder
+}
+
+// GetEntryStrategies mocks base method.
+func (m *MockStrategies) GetEntryStrategies() []tradeAlgorithm.EntryStrategy {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEntryStrategies")
+	ret0, _ := ret[0].([]tradeAlgorithm.EntryStrategy)
+	return ret0
+}
+
+// GetEntryStrategies indicates an expected call of GetEntryStrategies.
+func (mr *MockStrategiesMockRecorder) GetEntryStrategies() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntryStrategies", reflect.TypeOf((*MockStrategies)(nil).GetEntryStrategies))
+}
+
+// GetStrategies mocks base method.
+func (m *MockStrategies) GetStrategies() []tradeAlgorithm.Strategy {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStrategies")
+	ret0, _ := ret[0].([]tradeAlgorithm.Strategy)
+	return ret0
+}
+
+// GetStrategies indicates an expected call of GetStrategies.
+func (mr *MockStrategiesMockRecorder) GetStrategies() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStrategies", reflect.TypeOf((*MockStrategies)(nil).GetStrategies))
+}
+
+// ValidateEntryStrategy mocks base method.
+func (m *MockStrategies) ValidateEntryStrategy(name string, params types.StrategyParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidateEntryStrategy", name, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ValidateEntryStrategy indicates an expected call of ValidateEntryStrategy.
+func (mr *MockStrategiesMockRecorder) ValidateEntryStrategy(name, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateEntryStrategy", reflect.TypeOf((*MockStrategies)(nil).ValidateEntryStrategy), name, params)
+}
+
+// ValidateStrategy mocks base method.
+func (m *MockStrategies) ValidateStrategy(name string, params types.StrategyParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidateStrategy", name, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ValidateStrategy indicates an expected call of ValidateStrategy.
+func (mr *MockStrategiesMockRecorder) ValidateStrategy(name, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateStrategy", reflect.TypeOf((*MockStrategies)(nil).ValidateStrategy), name, params)
+}
+
+// MockDeadMansSwitch is a mock of DeadMansSwitch interface.
+type MockDeadMansSwitch struct {
+	ctrl     *gomock.Controller
+	recorder *MockDeadMansSwitchMockRecorder
+}
+
+// MockDeadMansSwitchMockRecorder is the mock recorder for MockDeadMansSwitch.
+type MockDeadMansSwitchMockRecorder struct {
+	mock *MockDeadMansSwitch
+}
+
+// NewMockDeadMansSwitch creates a new mock instance.
+func NewMockDeadMansSwitch(ctrl *gomock.Controller) *MockDeadMansSwitch {
+	mock := &MockDeadMansSwitch{ctrl: ctrl}
+	mock.recorder = &MockDeadMansSwitchMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDeadMansSwitch) EXPECT() *MockDeadMansSwitchMockRecorder {
+	return m.recorder
+}
+
+// GetDeadMansSwitchStatus mocks base method.
+func (m *MockDeadMansSwitch) GetDeadMansSwitchStatus(userID int) models.DeadMansSwitchStatus {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeadMansSwitchStatus", userID)
+	ret0, _ := ret[0].(models.DeadMansSwitchStatus)
+	return ret0
+}
+
+// GetDeadMansSwitchStatus indicates an expected call of GetDeadMansSwitchStatus.
+func (mr *MockDeadMansSwitchMockRecorder) GetDeadMansSwitchStatus(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeadMansSwitchStatus", reflect.TypeOf((*MockDeadMansSwitch)(nil).GetDeadMansSwitchStatus), userID)
+}
+
+// Shutdown mocks base method.
+func (m *MockDeadMansSwitch) Shutdown() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Shutdown")
+}
+
+// Shutdown indicates an expected call of Shutdown.
+func (mr *MockDeadMansSwitchMockRecorder) Shutdown() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Shutdown", reflect.TypeOf((*MockDeadMansSwitch)(nil).Shutdown))
+}
+
+// Start mocks base method.
+func (m *MockDeadMansSwitch) Start() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Start")
+}
+
+// Start indicates an expected call of Start.
+func (mr *MockDeadMansSwitchMockRecorder) Start() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartTrading", reflect.TypeOf((*MockKrakenOrdersManager)(nil).StartTrading), ctx, userID, details)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Start", reflect.TypeOf((*MockDeadMansSwitch)(nil).Start))
 }
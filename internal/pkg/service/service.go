@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+
+	"trade-bot/configs"
 	"trade-bot/internal/pkg/models"
 	"trade-bot/internal/pkg/repository"
 	"trade-bot/internal/pkg/tradeAlgorithm"
@@ -19,19 +21,59 @@ type Authorization interface {
 }
 
 type KrakenOrdersManager interface {
-	SendOrder(userID int, args krakenFuturesSDK.SendOrderArguments) (models.Order, error)
+	SendOrder(ctx context.Context, userID int, args krakenFuturesSDK.SendOrderArguments) (models.Order, error)
+	SendPaperOrder(ctx context.Context, userID int, args krakenFuturesSDK.SendOrderArguments) (models.Order, error)
+	SendExitOrder(ctx context.Context, userID int, paper bool, args krakenFuturesSDK.SendOrderArguments,
+		reason types.ExitReason) (models.Order, error)
+	BatchOrder(ctx context.Context, userID int, args krakenFuturesSDK.BatchOrderArguments) ([]krakenFuturesSDK.BatchStatus, []models.Order, error)
+	CancelAllOrdersAfter(ctx context.Context, userID int, timeout uint) (krakenFuturesSDK.DeadManSwitchStatus, error)
+	GetRateLimitUsage(userID int) (krakenFuturesSDK.RateLimitUsage, error)
 	GetUserOrders(userID int) ([]models.Order, error)
-	StartTrading(ctx context.Context, userID int, details types.TradingDetails) (models.Order, error)
+	GetPaperAccount(userID int) models.PaperAccount
+}
+
+type TradingSessions interface {
+	StartSession(ctx context.Context, userID int, details types.TradingDetails) (models.TradingSession, error)
+	GetUserSessions(userID int) ([]models.TradingSession, error)
+	GetSession(userID int, sessionID string) (models.TradingSession, error)
+	StopSession(userID int, sessionID string) (models.TradingSession, error)
+	WatchSession(userID int, sessionID string) (<-chan models.TradingSessionEvent, func(), error)
+	ResumeSessions() error
+	Shutdown()
+}
+
+type Strategies interface {
+	GetStrategies() []tradeAlgorithm.Strategy
+	ValidateStrategy(name string, params types.StrategyParams) error
+	GetEntryStrategies() []tradeAlgorithm.EntryStrategy
+	ValidateEntryStrategy(name string, params types.StrategyParams) error
+}
+
+type DeadMansSwitch interface {
+	Start()
+	Shutdown()
+	GetDeadMansSwitchStatus(userID int) models.DeadMansSwitchStatus
 }
 
 type Service struct {
 	Authorization
 	KrakenOrdersManager
+	TradingSessions
+	Strategies
+	DeadMansSwitch
 }
 
-func NewService(r *repository.Repository, w *web.Web, a *tradeAlgorithm.TradeAlgorithm) *Service {
+func NewService(r *repository.Repository, w *web.Web, a *tradeAlgorithm.TradeAlgorithm,
+	deadMansSwitchConfig configs.DeadMansSwitchConfiguration) *Service {
+	ordersManager := NewKrakenOrdersManagerService(w.KrakenOrdersManagerFactory, w.KrakenPaperOrdersManagerFactory,
+		w.KrakenInstruments, r.KrakenOrdersManager, r.Authorization)
+	tradingSessions := NewTradingSessionsService(ordersManager, a.Strategies, w.KrakenInstruments, r.TradingSessions)
+
 	return &Service{
-		Authorization:       NewAuthService(r.Authorization, r.JWT),
-		KrakenOrdersManager: NewKrakenOrdersManagerService(w.KrakenOrdersManager, r.KrakenOrdersManager, a.Trader),
+		Authorization:       NewAuthService(r.Authorization, r.JWT, w.KrakenOrdersManagerFactory),
+		KrakenOrdersManager: ordersManager,
+		TradingSessions:     tradingSessions,
+		Strategies:          NewStrategiesService(a.Strategies),
+		DeadMansSwitch:      NewDeadMansSwitchService(ordersManager, tradingSessions, r.Health, deadMansSwitchConfig),
 	}
 }
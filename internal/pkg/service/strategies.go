@@ -0,0 +1,45 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"trade-bot/internal/pkg/tradeAlgorithm"
+	"trade-bot/internal/pkg/tradeAlgorithm/types"
+)
+
+var (
+	ErrValidateStrategy      = errors.New("validate strategy")
+	ErrValidateEntryStrategy = errors.New("validate entry strategy")
+)
+
+type StrategiesService struct {
+	strategies tradeAlgorithm.Strategies
+}
+
+func NewStrategiesService(strategies tradeAlgorithm.Strategies) *StrategiesService {
+	return &StrategiesService{strategies: strategies}
+}
+
+func (s *StrategiesService) GetStrategies() []tradeAlgorithm.Strategy {
+	return s.strategies.Strategies()
+}
+
+func (s *StrategiesService) ValidateStrategy(name string, params types.StrategyParams) error {
+	if err := s.strategies.Validate(name, params); err != nil {
+		return fmt.Errorf("%s: %w", ErrValidateStrategy, err)
+	}
+	return nil
+}
+
+func (s *StrategiesService) GetEntryStrategies() []tradeAlgorithm.EntryStrategy {
+	return s.strategies.EntryStrategies()
+}
+
+func (s *StrategiesService) ValidateEntryStrategy(name string, params types.StrategyParams) error {
+	if err := s.strategies.ValidateEntry(name, params); err != nil {
+		return fmt.Errorf("%s: %w", ErrValidateEntryStrategy, err)
+	}
+	return nil
+}
@@ -0,0 +1,651 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"trade-bot/internal/pkg/models"
+	"trade-bot/internal/pkg/repository"
+	"trade-bot/internal/pkg/tradeAlgorithm"
+	"trade-bot/internal/pkg/tradeAlgorithm/types"
+	"trade-bot/internal/pkg/web"
+	"trade-bot/pkg/krakenFuturesSDK"
+)
+
+var (
+	ErrStartSession        = errors.New("start trading session")
+	ErrGetSession          = errors.New("get trading session")
+	ErrGetUserSessions     = errors.New("get user trading sessions")
+	ErrStopSession         = errors.New("stop trading session")
+	ErrWatchSession        = errors.New("watch trading session")
+	ErrResumeSessions      = errors.New("resume trading sessions")
+	ErrSessionNotFound     = errors.New("trading session not found")
+	ErrSessionIsTerminated = errors.New("trading session is already terminated")
+	ErrSessionInterrupted  = errors.New("trading session was interrupted before entry order was confirmed")
+	ErrPaperSessionLost    = errors.New("paper trading session was interrupted, paper account is kept only in memory")
+	ErrWaitForEntry        = errors.New("wait for entry signal")
+	ErrInvalidStrategy     = errors.New("invalid strategy of trading session")
+)
+
+const (
+	sessionEventsBufferSize = 16
+	// exitOrderAttempts is how many times exit order is sent when kraken fails it with transient error
+	exitOrderAttempts = 5
+)
+
+// exitOrderRetryDelay is delay before the second attempt of exit order, it doubles before every next one
+var exitOrderRetryDelay = time.Second
+
+type tradingSession struct {
+	mu            sync.Mutex
+	session       models.TradingSession
+	cancel        context.CancelFunc
+	shutdown      bool
+	watchers      map[int]chan models.TradingSessionEvent
+	nextWatcherID int
+}
+
+// TradingSessionsService runs trading strategies in background, independent of any client connection.
+// Every session is persisted, so sessions which were running when server stopped are resumed on start
+type TradingSessionsService struct {
+	orders      KrakenOrdersManager
+	strategies  tradeAlgorithm.Strategies
+	instruments web.KrakenInstruments
+	repo        repository.TradingSessions
+
+	mu       sync.RWMutex
+	sessions map[string]*tradingSession
+	wg       sync.WaitGroup
+
+	// ctx is cancelled by Shutdown, it interrupts exit orders which are retried when server stops
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func NewTradingSessionsService(orders KrakenOrdersManager, strategies tradeAlgorithm.Strategies,
+	instruments web.KrakenInstruments, repo repository.TradingSessions) *TradingSessionsService {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &TradingSessionsService{
+		orders:      orders,
+		strategies:  strategies,
+		instruments: instruments,
+		repo:        repo,
+		sessions:    make(map[string]*tradingSession),
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+// StartSession validates and starts session, ctx is used only for validation - session runs until it is stopped
+func (s *TradingSessionsService) StartSession(ctx context.Context, userID int, details types.TradingDetails) (models.TradingSession, error) {
+	if err := s.strategies.Validate(details.Strategy, details.Params); err != nil {
+		return models.TradingSession{}, fmt.Errorf("%s: %w: %s", ErrStartSession, ErrInvalidStrategy, err)
+	}
+	if details.EntryStrategy != "" {
+		if err := s.strategies.ValidateEntry(details.EntryStrategy, details.EntryParams); err != nil {
+			return models.TradingSession{}, fmt.Errorf("%s: %w: %s", ErrStartSession, ErrInvalidStrategy, err)
+		}
+	}
+	if err := s.validateOrder(ctx, details); err != nil {
+		return models.TradingSession{}, fmt.Errorf("%s: %w", ErrStartSession, err)
+	}
+
+	id, err := uuid.NewV4()
+	if err != nil {
+		return models.TradingSession{}, fmt.Errorf("%s: %w", ErrStartSession, err)
+	}
+
+	now := time.Now().UTC()
+	session := models.TradingSession{
+		ID:        id.String(),
+		UserID:    userID,
+		Details:   details,
+		State:     models.TradingSessionStarting,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := s.repo.CreateSession(session); err != nil {
+		return models.TradingSession{}, fmt.Errorf("%s: %w", ErrStartSession, err)
+	}
+
+	return s.launch(session), nil
+}
+
+// validateOrder checks order of session against its instrument, so session which could never enter is not started
+func (s *TradingSessionsService) validateOrder(ctx context.Context, details types.TradingDetails) error {
+	instrument, err := s.instruments.Instrument(ctx, details.Symbol)
+	if err != nil {
+		return err
+	}
+
+	_, err = instrument.ValidateOrder(krakenFuturesSDK.SendOrderArguments{
+		OrderType: details.OrderType,
+		Symbol:    details.Symbol,
+		Side:      details.Side,
+		Size:      details.Size,
+	}, time.Now().UTC())
+	return err
+}
+
+// ResumeSessions reloads not terminated sessions and continues them from the stored state:
+// waiting sessions wait for entry signal again and sessions in position continue from the stored entry order.
+// Paper sessions are failed, their paper accounts were lost with the restart
+func (s *TradingSessionsService) ResumeSessions() error {
+	sessions, err := s.repo.GetActiveSessions()
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrResumeSessions, err)
+	}
+
+	for _, session := range sessions {
+		switch {
+		case session.Details.Paper:
+			s.failInterrupted(session, ErrPaperSessionLost)
+			continue
+		case !session.InPosition() && session.State != models.TradingSessionWaiting:
+			// it is unknown whether entry order reached kraken, so session is not restarted blindly
+			s.failInterrupted(session, ErrSessionInterrupted)
+			continue
+		}
+
+		log.Infof("resuming trading session %s of user %d", session.ID, session.UserID)
+		ts := s.launch(session)
+		if ts.State == models.TradingSessionStopping {
+			if _, err := s.StopSession(ts.UserID, ts.ID); err != nil {
+				log.Errorf("%s: %s", ErrResumeSessions, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// failInterrupted marks session which can't be resumed as failed with reason
+func (s *TradingSessionsService) failInterrupted(session models.TradingSession, reason error) {
+	session.State = models.TradingSessionFailed
+	session.Error = reason.Error()
+	session.UpdatedAt = time.Now().UTC()
+	if err := s.repo.UpdateSession(session); err != nil {
+		log.Errorf("%s: %s", ErrResumeSessions, err)
+	}
+}
+
+// Shutdown interrupts all running sessions without closing their positions and waits for them,
+// sessions keep their state and are resumed on next start
+func (s *TradingSessionsService) Shutdown() {
+	s.mu.RLock()
+	for _, ts := range s.sessions {
+		ts.mu.Lock()
+		ts.shutdown = true
+		ts.mu.Unlock()
+		ts.cancel()
+	}
+	s.mu.RUnlock()
+	s.cancel()
+
+	s.wg.Wait()
+}
+
+// LiveSessionUsers returns users which have running sessions trading on kraken, paper sessions are not counted
+func (s *TradingSessionsService) LiveSessionUsers() []int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	seen := make(map[int]struct{})
+	users := make([]int, 0)
+	for _, ts := range s.sessions {
+		session := ts.snapshot()
+		if session.Details.Paper || session.State.IsTerminal() {
+			continue
+		}
+		if _, ok := seen[session.UserID]; !ok {
+			seen[session.UserID] = struct{}{}
+			users = append(users, session.UserID)
+		}
+	}
+	sort.Ints(users)
+	return users
+}
+
+func (s *TradingSessionsService) GetUserSessions(userID int) ([]models.TradingSession, error) {
+	sessions, err := s.repo.GetUserSessions(userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrGetUserSessions, err)
+	}
+	return sessions, nil
+}
+
+func (s *TradingSessionsService) GetSession(userID int, sessionID string) (models.TradingSession, error) {
+	if ts, ok := s.runningSession(userID, sessionID); ok {
+		return ts.snapshot(), nil
+	}
+
+	session, err := s.storedSession(userID, sessionID)
+	if err != nil {
+		return models.TradingSession{}, fmt.Errorf("%s: %w", ErrGetSession, err)
+	}
+	return session, nil
+}
+
+func (s *TradingSessionsService) StopSession(userID int, sessionID string) (models.TradingSession, error) {
+	ts, ok := s.runningSession(userID, sessionID)
+	if !ok {
+		if _, err := s.storedSession(userID, sessionID); err != nil {
+			return models.TradingSession{}, fmt.Errorf("%s: %w", ErrStopSession, err)
+		}
+		return models.TradingSession{}, fmt.Errorf("%s: %w", ErrStopSession, ErrSessionIsTerminated)
+	}
+
+	ts.mu.Lock()
+	if ts.session.State.IsTerminal() {
+		ts.mu.Unlock()
+		return models.TradingSession{}, fmt.Errorf("%s: %w", ErrStopSession, ErrSessionIsTerminated)
+	}
+	ts.setStateLocked(models.TradingSessionStopping)
+	session := ts.session
+	// persisted under lock, so it can not overwrite terminal state stored by run
+	s.persist(session)
+	ts.mu.Unlock()
+
+	ts.cancel()
+	return session, nil
+}
+
+// WatchSession attaches live view to the session. Current session state is sent first,
+// channel is closed when session is terminated or returned detach func is called
+func (s *TradingSessionsService) WatchSession(userID int, sessionID string) (<-chan models.TradingSessionEvent, func(), error) {
+	events := make(chan models.TradingSessionEvent, sessionEventsBufferSize)
+
+	ts, ok := s.runningSession(userID, sessionID)
+	if !ok {
+		session, err := s.storedSession(userID, sessionID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", ErrWatchSession, err)
+		}
+		events <- models.TradingSessionEvent{Event: models.TradingSessionStateEvent, Session: session}
+		close(events)
+		return events, func() {}, nil
+	}
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	events <- models.TradingSessionEvent{Event: models.TradingSessionStateEvent, Session: ts.session}
+	if ts.session.State.IsTerminal() {
+		close(events)
+		return events, func() {}, nil
+	}
+
+	watcherID := ts.nextWatcherID
+	ts.nextWatcherID++
+	ts.watchers[watcherID] = events
+
+	detach := func() {
+		ts.mu.Lock()
+		defer ts.mu.Unlock()
+
+		if ch, ok := ts.watchers[watcherID]; ok {
+			delete(ts.watchers, watcherID)
+			close(ch)
+		}
+	}
+
+	return events, detach, nil
+}
+
+func (s *TradingSessionsService) runningSession(userID int, sessionID string) (*tradingSession, bool) {
+	s.mu.RLock()
+	ts, ok := s.sessions[sessionID]
+	s.mu.RUnlock()
+
+	if !ok || ts.snapshot().UserID != userID {
+		return nil, false
+	}
+	return ts, true
+}
+
+func (s *TradingSessionsService) storedSession(userID int, sessionID string) (models.TradingSession, error) {
+	session, err := s.repo.GetSession(sessionID)
+	if err != nil || session.UserID != userID {
+		return models.TradingSession{}, ErrSessionNotFound
+	}
+	return session, nil
+}
+
+func (s *TradingSessionsService) launch(session models.TradingSession) models.TradingSession {
+	ctx, cancel := context.WithCancel(context.Background())
+	ts := &tradingSession{
+		session:  session,
+		cancel:   cancel,
+		watchers: make(map[int]chan models.TradingSessionEvent),
+	}
+
+	s.mu.Lock()
+	s.sessions[session.ID] = ts
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		// releases subscriptions and timers of ctx which are left when session finishes on its own
+		defer cancel()
+		defer func() {
+			s.mu.Lock()
+			delete(s.sessions, session.ID)
+			s.mu.Unlock()
+		}()
+
+		s.run(ctx, ts)
+	}()
+
+	return session
+}
+
+// sendEntryOrder sends order which opens position of session to the venue chosen for it.
+// Orders of session are not cancelled with session's ctx, so it is always known whether they reached venue
+func (s *TradingSessionsService) sendEntryOrder(session models.TradingSession, args krakenFuturesSDK.SendOrderArguments) (models.Order, error) {
+	if session.Details.Paper {
+		return s.orders.SendPaperOrder(context.Background(), session.UserID, args)
+	}
+	return s.orders.SendOrder(context.Background(), session.UserID, args)
+}
+
+// sendExitOrder closes position of session, it is sent even when session was stopped, so it is not cancelled with its ctx,
+// only server shutdown interrupts it. Exit order has client order ID made of session ID and round trip, so it is repeated
+// after transient error without risk of being executed twice. It is retried only here, not by kraken sdk as well
+func (s *TradingSessionsService) sendExitOrder(session models.TradingSession, args krakenFuturesSDK.SendOrderArguments,
+	reason types.ExitReason) (models.Order, error) {
+	args.CliOrderID = exitOrderID(session)
+	ctx := krakenFuturesSDK.WithoutRetry(s.ctx)
+
+	delay := exitOrderRetryDelay
+	for attempt := 1; ; attempt++ {
+		order, err := s.orders.SendExitOrder(ctx, session.UserID, session.Details.Paper, args, reason)
+
+		var apiErr *krakenFuturesSDK.APIError
+		if err == nil || attempt == exitOrderAttempts || !errors.As(err, &apiErr) || !apiErr.Temporary() {
+			return order, err
+		}
+
+		log.Warnf("trading session %s: exit order attempt %d failed, retrying in %s: %s", session.ID, attempt, delay, err)
+		select {
+		case <-ctx.Done():
+			return models.Order{}, ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// exitOrderID is client order ID of exit order of the current round trip of session
+func exitOrderID(session models.TradingSession) string {
+	return fmt.Sprintf("%s-exit-%d", session.ID, session.RoundTrips+1)
+}
+
+func (s *TradingSessionsService) persist(session models.TradingSession) {
+	if err := s.repo.UpdateSession(session); err != nil {
+		log.Errorf("unable to persist trading session %s: %s", session.ID, err)
+	}
+}
+
+// run trades round trips of session: waits for entry signal (if session has entry strategy), sends entry order
+// (unless session is resumed after it), waits for trader decision and closes position. Session without entry
+// strategy finishes after the first round trip, otherwise it trades until stopped.
+// Position is closed even if session was stopped or trader failed, so it is never left without exit order.
+// The only exception is server shutdown - then session is left as is to be resumed later
+func (s *TradingSessionsService) run(ctx context.Context, ts *tradingSession) {
+	session := ts.snapshot()
+	details := session.Details
+
+	sendArgs := krakenFuturesSDK.SendOrderArguments{
+		OrderType: details.OrderType,
+		Symbol:    details.Symbol,
+		Side:      details.Side,
+		Size:      details.Size,
+	}
+
+	trader, traderErr := s.strategies.NewTrader(details.Strategy, details.Params)
+	if traderErr != nil && !session.InPosition() {
+		s.persist(ts.finish(models.TradingSessionFailed, fmt.Errorf("%s: %w", ErrStartTradingService, traderErr)))
+		return
+	}
+
+	var (
+		entrySignal tradeAlgorithm.EntrySignal
+		entryErr    error
+	)
+	if details.EntryStrategy != "" {
+		entrySignal, entryErr = s.strategies.NewEntrySignal(details.EntryStrategy, details.EntryParams)
+		if entryErr != nil && !session.InPosition() {
+			s.persist(ts.finish(models.TradingSessionFailed, fmt.Errorf("%s: %w", ErrStartTradingService, entryErr)))
+			return
+		}
+	}
+
+	// tick size is required before entry, so position is never opened for strategy which can't trade it
+	tickSize, err := s.instruments.TickSize(ctx, details.Symbol)
+	if err != nil {
+		if !session.InPosition() {
+			s.persist(ts.finish(models.TradingSessionFailed, fmt.Errorf("%s: %w", ErrStartTradingService, err)))
+			return
+		}
+		log.Warnf("%s: %s", ErrStartTradingService, err)
+	}
+	details.TickSize = tickSize
+
+	for {
+		if !session.InPosition() {
+			var ok bool
+			if session, ok = s.enter(ctx, ts, entrySignal, details, sendArgs); !ok {
+				return
+			}
+		}
+
+		// if trader of resumed session could not be created, its position is closed right away
+		details.BuyPrice = session.EntryPrice
+		analyzeErr := traderErr
+		var reason types.ExitReason
+		if traderErr == nil {
+			// every phase has its own ctx, so feeds it subscribed to are released as soon as it returns
+			analyzeCtx, cancelAnalyze := context.WithCancel(ctx)
+			reason, analyzeErr = trader.StartAnalyzing(analyzeCtx, session.EntryTime, details)
+			cancelAnalyze()
+		}
+
+		if ts.isShutdown() {
+			return
+		}
+
+		switch {
+		case ctx.Err() != nil:
+			reason = types.ExitReasonCancelled
+		case analyzeErr != nil:
+			reason = types.ExitReasonFailed
+		}
+
+		exitArgs := sendArgs
+		exitArgs.ChangeToOpositeOrderSide()
+
+		exitOrder, err := s.sendExitOrder(session, exitArgs, reason)
+		if err != nil && ts.isShutdown() {
+			// exit order may have reached kraken, resumed session repeats it with the same client order ID
+			return
+		}
+		if err != nil {
+			s.persist(ts.finish(models.TradingSessionFailed, fmt.Errorf("%s: %w", ErrStartTradingService, err)))
+			return
+		}
+
+		session = ts.exited(exitOrder)
+
+		switch {
+		case ctx.Err() != nil:
+			s.persist(ts.finish(models.TradingSessionStopped, nil))
+			return
+		case analyzeErr != nil:
+			s.persist(ts.finish(models.TradingSessionFailed, fmt.Errorf("%s: %w", ErrStartTradingService, analyzeErr)))
+			return
+		case entryErr != nil:
+			s.persist(ts.finish(models.TradingSessionFailed, fmt.Errorf("%s: %w", ErrStartTradingService, entryErr)))
+			return
+		case entrySignal == nil:
+			s.persist(ts.finish(models.TradingSessionFinished, nil))
+			return
+		}
+		s.persist(session)
+	}
+}
+
+// enter waits for entry signal and opens position of session. It returns false when session is over
+func (s *TradingSessionsService) enter(ctx context.Context, ts *tradingSession, entrySignal tradeAlgorithm.EntrySignal,
+	details types.TradingDetails, sendArgs krakenFuturesSDK.SendOrderArguments) (models.TradingSession, bool) {
+	if entrySignal != nil {
+		s.setState(ts, models.TradingSessionWaiting)
+
+		waitCtx, cancelWait := context.WithCancel(ctx)
+		err := entrySignal.WaitForEntry(waitCtx, details)
+		cancelWait()
+		if ts.isShutdown() {
+			return models.TradingSession{}, false
+		}
+		if ctx.Err() != nil {
+			s.persist(ts.finish(models.TradingSessionStopped, nil))
+			return models.TradingSession{}, false
+		}
+		if err != nil {
+			s.persist(ts.finish(models.TradingSessionFailed, fmt.Errorf("%s: %w", ErrWaitForEntry, err)))
+			return models.TradingSession{}, false
+		}
+
+		s.setState(ts, models.TradingSessionStarting)
+	}
+
+	if ctx.Err() != nil {
+		s.persist(ts.finish(models.TradingSessionStopped, nil))
+		return models.TradingSession{}, false
+	}
+
+	session := ts.snapshot()
+	entryOrder, err := s.sendEntryOrder(session, sendArgs)
+	if err != nil {
+		s.persist(ts.finish(models.TradingSessionFailed, fmt.Errorf("%s: %w", ErrStartTradingService, err)))
+		return models.TradingSession{}, false
+	}
+
+	entryTime, err := time.Parse(time.RFC3339, entryOrder.Timestamp)
+	if err != nil {
+		log.Warnf("%s: %s", ErrUnableToParseBuyTimestamp, err)
+		entryTime = time.Now().UTC()
+	}
+
+	session = ts.entered(entryOrder, entryTime)
+	s.persist(session)
+	return session, true
+}
+
+// setState moves session between active states, stopping state set by StopSession is never overwritten
+func (s *TradingSessionsService) setState(ts *tradingSession, state models.TradingSessionState) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if ts.session.State == models.TradingSessionStopping || ts.session.State.IsTerminal() {
+		return
+	}
+	ts.setStateLocked(state)
+	// persisted under lock, so it can not overwrite state stored by StopSession
+	s.persist(ts.session)
+}
+
+func (ts *tradingSession) snapshot() models.TradingSession {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	return ts.session
+}
+
+func (ts *tradingSession) isShutdown() bool {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	return ts.shutdown
+}
+
+func (ts *tradingSession) entered(order models.Order, entryTime time.Time) models.TradingSession {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	ts.session.EntryOrderID = order.ID
+	ts.session.EntryPrice = order.Price
+	ts.session.EntryTime = entryTime
+	ts.session.ExitOrderID = ""
+	if ts.session.State == models.TradingSessionStarting {
+		ts.session.State = models.TradingSessionRunning
+	}
+	ts.session.UpdatedAt = time.Now().UTC()
+
+	ts.publishLocked(models.TradingSessionEvent{
+		Event:   models.TradingSessionEntryOrderEvent,
+		Session: ts.session,
+		Order:   &order,
+	})
+	return ts.session
+}
+
+func (ts *tradingSession) exited(order models.Order) models.TradingSession {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	ts.session.ExitOrderID = order.ID
+	ts.session.RoundTrips++
+	ts.session.UpdatedAt = time.Now().UTC()
+
+	ts.publishLocked(models.TradingSessionEvent{
+		Event:   models.TradingSessionExitOrderEvent,
+		Session: ts.session,
+		Order:   &order,
+	})
+	return ts.session
+}
+
+func (ts *tradingSession) finish(state models.TradingSessionState, err error) models.TradingSession {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if err != nil {
+		log.Error(err)
+		ts.session.Error = err.Error()
+	}
+	ts.setStateLocked(state)
+
+	for id, ch := range ts.watchers {
+		delete(ts.watchers, id)
+		close(ch)
+	}
+	return ts.session
+}
+
+func (ts *tradingSession) setStateLocked(state models.TradingSessionState) {
+	ts.session.State = state
+	ts.session.UpdatedAt = time.Now().UTC()
+
+	ts.publishLocked(models.TradingSessionEvent{
+		Event:   models.TradingSessionStateEvent,
+		Session: ts.session,
+	})
+}
+
+// publishLocked never blocks trading: event is dropped for watcher which does not keep up
+func (ts *tradingSession) publishLocked(event models.TradingSessionEvent) {
+	for _, ch := range ts.watchers {
+		select {
+		case ch <- event:
+		default:
+			log.Warnf("trading session %s: watcher is too slow, event %s dropped", ts.session.ID, event.Event)
+		}
+	}
+}
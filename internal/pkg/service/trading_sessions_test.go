@@ -0,0 +1,444 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/internal/pkg/models"
+	mockService "trade-bot/internal/pkg/service/mocks"
+	"trade-bot/internal/pkg/tradeAlgorithm"
+	"trade-bot/internal/pkg/tradeAlgorithm/types"
+	"trade-bot/pkg/krakenFuturesSDK"
+)
+
+type fakeSessionsRepo struct {
+	mu       sync.Mutex
+	sessions map[string]models.TradingSession
+}
+
+func newFakeSessionsRepo(sessions ...models.TradingSession) *fakeSessionsRepo {
+	repo := &fakeSessionsRepo{sessions: make(map[string]models.TradingSession)}
+	for _, session := range sessions {
+		repo.sessions[session.ID] = session
+	}
+	return repo
+}
+
+func (f *fakeSessionsRepo) CreateSession(session models.TradingSession) error {
+	return f.UpdateSession(session)
+}
+
+func (f *fakeSessionsRepo) UpdateSession(session models.TradingSession) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sessions[session.ID] = session
+	return nil
+}
+
+func (f *fakeSessionsRepo) GetSession(sessionID string) (models.TradingSession, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	session, ok := f.sessions[sessionID]
+	if !ok {
+		return models.TradingSession{}, errors.New("session not found")
+	}
+	return session, nil
+}
+
+func (f *fakeSessionsRepo) GetUserSessions(userID int) ([]models.TradingSession, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var sessions []models.TradingSession
+	for _, session := range f.sessions {
+		if session.UserID == userID {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
+func (f *fakeSessionsRepo) GetActiveSessions() ([]models.TradingSession, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var sessions []models.TradingSession
+	for _, session := range f.sessions {
+		if !session.State.IsTerminal() {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
+type fakeTrader func(ctx context.Context) (types.ExitReason, error)
+
+func (f fakeTrader) StartAnalyzing(ctx context.Context, _ time.Time, _ types.TradingDetails) (types.ExitReason, error) {
+	return f(ctx)
+}
+
+type fakeEntrySignal func(ctx context.Context) error
+
+func (f fakeEntrySignal) WaitForEntry(ctx context.Context, _ types.TradingDetails) error {
+	return f(ctx)
+}
+
+// fakeStrategies has the only strategy and the only entry strategy, both of any name
+type fakeStrategies struct {
+	validateErr error
+	trader      fakeTrader
+	entrySignal fakeEntrySignal
+}
+
+func (f fakeStrategies) Strategies() []tradeAlgorithm.Strategy {
+	return nil
+}
+
+func (f fakeStrategies) EntryStrategies() []tradeAlgorithm.EntryStrategy {
+	return nil
+}
+
+func (f fakeStrategies) Validate(_ string, _ types.StrategyParams) error {
+	return f.validateErr
+}
+
+func (f fakeStrategies) ValidateEntry(_ string, _ types.StrategyParams) error {
+	return f.validateErr
+}
+
+func (f fakeStrategies) NewTrader(_ string, _ types.StrategyParams) (tradeAlgorithm.Trader, error) {
+	return f.trader, f.validateErr
+}
+
+func (f fakeStrategies) NewEntrySignal(_ string, _ types.StrategyParams) (tradeAlgorithm.EntrySignal, error) {
+	return f.entrySignal, f.validateErr
+}
+
+var sessionInstruments = fakeInstruments{"pi_xbtusd": tradeableInstrument("pi_xbtusd", "0.5", 0)}
+
+func sessionDetails() types.TradingDetails {
+	return types.TradingDetails{
+		OrderType: "mkt",
+		Symbol:    "pi_xbtusd",
+		Side:      krakenFuturesSDK.BuySide,
+		Size:      decimal.NewFromInt(1),
+		Strategy:  "fake",
+	}
+}
+
+func entryOrder(id string) models.Order {
+	return models.Order{ID: id, Price: decimal.NewFromInt(100), Timestamp: "2022-04-15T10:00:00Z"}
+}
+
+func takeProfit(context.Context) (types.ExitReason, error) {
+	return types.ExitReasonTakeProfit, nil
+}
+
+func TestTradingSessionsService_StartSession_InvalidStrategy(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	repo := newFakeSessionsRepo()
+	s := NewTradingSessionsService(mockService.NewMockKrakenOrdersManager(c),
+		fakeStrategies{validateErr: errors.New("unknown strategy")}, sessionInstruments, repo)
+
+	_, err := s.StartSession(context.Background(), 1, sessionDetails())
+	assert.ErrorIs(t, err, ErrInvalidStrategy)
+	assert.Empty(t, repo.sessions)
+}
+
+func TestTradingSessionsService_ExitOrder(t *testing.T) {
+	defer func(delay time.Duration) { exitOrderRetryDelay = delay }(exitOrderRetryDelay)
+	exitOrderRetryDelay = time.Millisecond
+
+	unavailable := &krakenFuturesSDK.APIError{Code: krakenFuturesSDK.CodeServerUnavailable}
+	invalid := &krakenFuturesSDK.APIError{Code: "invalidArgument"}
+
+	tests := []struct {
+		name      string
+		exitErrs  []error
+		wantState models.TradingSessionState
+		wantErr   bool
+	}{
+		{
+			name:      "Transient errors are retried",
+			exitErrs:  []error{unavailable, unavailable, nil},
+			wantState: models.TradingSessionFinished,
+		},
+		{
+			name:      "Other errors fail session",
+			exitErrs:  []error{invalid},
+			wantState: models.TradingSessionFailed,
+			wantErr:   true,
+		},
+		{
+			name:      "Transient errors fail session after the last attempt",
+			exitErrs:  []error{unavailable, unavailable, unavailable, unavailable, unavailable},
+			wantState: models.TradingSessionFailed,
+			wantErr:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			orders := mockService.NewMockKrakenOrdersManager(c)
+			repo := newFakeSessionsRepo()
+			s := NewTradingSessionsService(orders, fakeStrategies{trader: takeProfit}, sessionInstruments, repo)
+
+			orders.EXPECT().SendOrder(gomock.Any(), 1, gomock.Any()).Return(entryOrder("entry"), nil)
+
+			var cliOrderIDs []string
+			attempt := 0
+			orders.EXPECT().SendExitOrder(gomock.Any(), 1, false, gomock.Any(), types.ExitReasonTakeProfit).
+				Times(len(test.exitErrs)).
+				DoAndReturn(func(_ context.Context, _ int, _ bool, args krakenFuturesSDK.SendOrderArguments,
+					_ types.ExitReason) (models.Order, error) {
+					assert.Equal(t, krakenFuturesSDK.SellSide, args.Side)
+					cliOrderIDs = append(cliOrderIDs, args.CliOrderID)
+					err := test.exitErrs[attempt]
+					attempt++
+					if err != nil {
+						return models.Order{}, err
+					}
+					return models.Order{ID: "exit"}, nil
+				})
+
+			session, err := s.StartSession(context.Background(), 1, sessionDetails())
+			assert.NoError(t, err)
+			s.wg.Wait()
+
+			// every attempt has the same client order ID, so kraken executes exit order only once
+			for _, id := range cliOrderIDs {
+				assert.Equal(t, session.ID+"-exit-1", id)
+			}
+
+			stored, err := repo.GetSession(session.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, test.wantState, stored.State)
+			assert.Equal(t, test.wantErr, stored.Error != "")
+		})
+	}
+}
+
+func TestTradingSessionsService_ResumeSessions(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	inPosition := models.TradingSession{ID: "in-position", UserID: 1, Details: sessionDetails(),
+		State: models.TradingSessionRunning, EntryOrderID: "entry", EntryPrice: decimal.NewFromInt(100)}
+	interrupted := models.TradingSession{ID: "interrupted", UserID: 1, Details: sessionDetails(),
+		State: models.TradingSessionStarting}
+	paperDetails := sessionDetails()
+	paperDetails.Paper = true
+	paper := models.TradingSession{ID: "paper", UserID: 1, Details: paperDetails,
+		State: models.TradingSessionRunning, EntryOrderID: "paper-entry"}
+
+	orders := mockService.NewMockKrakenOrdersManager(c)
+	repo := newFakeSessionsRepo(inPosition, interrupted, paper)
+	s := NewTradingSessionsService(orders, fakeStrategies{trader: takeProfit}, sessionInstruments, repo)
+
+	// session in position continues from its entry order, so only exit order is sent
+	orders.EXPECT().SendExitOrder(gomock.Any(), 1, false, gomock.Any(), types.ExitReasonTakeProfit).
+		Return(models.Order{ID: "exit"}, nil)
+
+	assert.NoError(t, s.ResumeSessions())
+	s.wg.Wait()
+
+	stored, _ := repo.GetSession(inPosition.ID)
+	assert.Equal(t, models.TradingSessionFinished, stored.State)
+	assert.Equal(t, "exit", stored.ExitOrderID)
+	assert.Equal(t, 1, stored.RoundTrips)
+
+	stored, _ = repo.GetSession(interrupted.ID)
+	assert.Equal(t, models.TradingSessionFailed, stored.State)
+	assert.Equal(t, ErrSessionInterrupted.Error(), stored.Error)
+
+	stored, _ = repo.GetSession(paper.ID)
+	assert.Equal(t, models.TradingSessionFailed, stored.State)
+	assert.Equal(t, ErrPaperSessionLost.Error(), stored.Error)
+}
+
+func TestTradingSessionsService_RoundTripsUntilEntryFails(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	var phaseCtxs []context.Context
+	entries := 0
+	strategies := fakeStrategies{
+		trader: func(ctx context.Context) (types.ExitReason, error) {
+			phaseCtxs = append(phaseCtxs, ctx)
+			return types.ExitReasonTakeProfit, nil
+		},
+		entrySignal: func(ctx context.Context) error {
+			phaseCtxs = append(phaseCtxs, ctx)
+			entries++
+			if entries == 3 {
+				return errors.New("feed is closed")
+			}
+			return nil
+		},
+	}
+
+	orders := mockService.NewMockKrakenOrdersManager(c)
+	repo := newFakeSessionsRepo()
+	s := NewTradingSessionsService(orders, strategies, sessionInstruments, repo)
+
+	details := sessionDetails()
+	details.EntryStrategy = "fake"
+
+	orders.EXPECT().SendOrder(gomock.Any(), 1, gomock.Any()).Return(entryOrder("entry"), nil).Times(2)
+	gomock.InOrder(
+		orders.EXPECT().SendExitOrder(gomock.Any(), 1, false, gomock.Any(), types.ExitReasonTakeProfit).
+			DoAndReturn(func(_ context.Context, _ int, _ bool, args krakenFuturesSDK.SendOrderArguments,
+				_ types.ExitReason) (models.Order, error) {
+				assert.Contains(t, args.CliOrderID, "-exit-1")
+				return models.Order{ID: "exit-1"}, nil
+			}),
+		orders.EXPECT().SendExitOrder(gomock.Any(), 1, false, gomock.Any(), types.ExitReasonTakeProfit).
+			DoAndReturn(func(_ context.Context, _ int, _ bool, args krakenFuturesSDK.SendOrderArguments,
+				_ types.ExitReason) (models.Order, error) {
+				assert.Contains(t, args.CliOrderID, "-exit-2")
+				return models.Order{ID: "exit-2"}, nil
+			}),
+	)
+
+	session, err := s.StartSession(context.Background(), 1, details)
+	assert.NoError(t, err)
+	s.wg.Wait()
+
+	stored, _ := repo.GetSession(session.ID)
+	assert.Equal(t, models.TradingSessionFailed, stored.State)
+	assert.Contains(t, stored.Error, ErrWaitForEntry.Error())
+	assert.Equal(t, 2, stored.RoundTrips)
+
+	// every phase releases its ctx as soon as it returns
+	assert.Len(t, phaseCtxs, 5)
+	for _, ctx := range phaseCtxs {
+		assert.Error(t, ctx.Err())
+	}
+}
+
+func TestTradingSessionsService_StopSession(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	analyzing := make(chan context.Context)
+	strategies := fakeStrategies{
+		trader: func(ctx context.Context) (types.ExitReason, error) {
+			analyzing <- ctx
+			<-ctx.Done()
+			return "", ctx.Err()
+		},
+	}
+
+	orders := mockService.NewMockKrakenOrdersManager(c)
+	repo := newFakeSessionsRepo()
+	s := NewTradingSessionsService(orders, strategies, sessionInstruments, repo)
+
+	orders.EXPECT().SendOrder(gomock.Any(), 1, gomock.Any()).Return(entryOrder("entry"), nil)
+	// position of stopped session is closed with order which is not cancelled with session
+	orders.EXPECT().SendExitOrder(gomock.Any(), 1, false, gomock.Any(), types.ExitReasonCancelled).
+		DoAndReturn(func(ctx context.Context, _ int, _ bool, _ krakenFuturesSDK.SendOrderArguments,
+			_ types.ExitReason) (models.Order, error) {
+			assert.NoError(t, ctx.Err())
+			return models.Order{ID: "exit"}, nil
+		})
+
+	session, err := s.StartSession(context.Background(), 1, sessionDetails())
+	assert.NoError(t, err)
+	analyzeCtx := <-analyzing
+
+	stopping, err := s.StopSession(1, session.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.TradingSessionStopping, stopping.State)
+	s.wg.Wait()
+
+	assert.Error(t, analyzeCtx.Err())
+	stored, _ := repo.GetSession(session.ID)
+	assert.Equal(t, models.TradingSessionStopped, stored.State)
+	assert.Equal(t, "exit", stored.ExitOrderID)
+
+	_, err = s.StopSession(1, session.ID)
+	assert.ErrorIs(t, err, ErrSessionIsTerminated)
+}
+
+func TestTradingSessionsService_Shutdown(t *testing.T) {
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	analyzing := make(chan struct{})
+	strategies := fakeStrategies{
+		trader: func(ctx context.Context) (types.ExitReason, error) {
+			close(analyzing)
+			<-ctx.Done()
+			return "", ctx.Err()
+		},
+	}
+
+	orders := mockService.NewMockKrakenOrdersManager(c)
+	repo := newFakeSessionsRepo()
+	s := NewTradingSessionsService(orders, strategies, sessionInstruments, repo)
+
+	// position is left open on shutdown, so exit order is not expected
+	orders.EXPECT().SendOrder(gomock.Any(), 1, gomock.Any()).Return(entryOrder("entry"), nil)
+
+	session, err := s.StartSession(context.Background(), 1, sessionDetails())
+	assert.NoError(t, err)
+	<-analyzing
+	s.Shutdown()
+
+	stored, _ := repo.GetSession(session.ID)
+	assert.Equal(t, models.TradingSessionRunning, stored.State)
+	assert.True(t, stored.InPosition())
+}
+
+func TestTradingSessionsService_ShutdownDuringExitRetry(t *testing.T) {
+	defer func(delay time.Duration) { exitOrderRetryDelay = delay }(exitOrderRetryDelay)
+	exitOrderRetryDelay = time.Hour
+
+	c := gomock.NewController(t)
+	defer c.Finish()
+
+	orders := mockService.NewMockKrakenOrdersManager(c)
+	repo := newFakeSessionsRepo()
+	s := NewTradingSessionsService(orders, fakeStrategies{trader: takeProfit}, sessionInstruments, repo)
+
+	orders.EXPECT().SendOrder(gomock.Any(), 1, gomock.Any()).Return(entryOrder("entry"), nil)
+	failed := make(chan struct{})
+	orders.EXPECT().SendExitOrder(gomock.Any(), 1, false, gomock.Any(), types.ExitReasonTakeProfit).
+		DoAndReturn(func(context.Context, int, bool, krakenFuturesSDK.SendOrderArguments, types.ExitReason) (models.Order, error) {
+			close(failed)
+			return models.Order{}, &krakenFuturesSDK.APIError{Code: krakenFuturesSDK.CodeServerUnavailable}
+		})
+
+	session, err := s.StartSession(context.Background(), 1, sessionDetails())
+	assert.NoError(t, err)
+	<-failed
+
+	// shutdown does not wait for delay before the next attempt, session is left in position to be resumed
+	done := make(chan struct{})
+	go func() {
+		s.Shutdown()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("shutdown waits for exit order retry")
+	}
+
+	stored, _ := repo.GetSession(session.ID)
+	assert.Equal(t, models.TradingSessionRunning, stored.State)
+	assert.True(t, stored.InPosition())
+}
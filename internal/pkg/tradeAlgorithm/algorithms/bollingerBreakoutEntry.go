@@ -0,0 +1,78 @@
+package algorithms
+
+import (
+	"context"
+	"fmt"
+
+	"trade-bot/internal/pkg/tradeAlgorithm/indicators"
+	"trade-bot/internal/pkg/tradeAlgorithm/types"
+	"trade-bot/internal/pkg/web"
+	"trade-bot/pkg/krakenFuturesWSSDK"
+)
+
+const (
+	BollingerBreakoutName = "bollinger_breakout"
+
+	bollingerPeriodParam     = "period"
+	bollingerMultiplierParam = "multiplier"
+)
+
+var BollingerBreakoutSchema = append(types.ParamsSchema{
+	{
+		Name:        bollingerPeriodParam,
+		Type:        types.IntegerParam,
+		Description: "number of candles in bands",
+		Min:         types.Float(2),
+		Default:     float64(20),
+	},
+	{
+		Name:        bollingerMultiplierParam,
+		Type:        types.NumberParam,
+		Description: "number of standard deviations between middle and outer bands",
+		Min:         types.Float(0),
+		Default:     float64(2),
+	},
+}, candlesParams(krakenFuturesWSSDK.OneMinuteResolution, krakenFuturesWSSDK.TradePriceSource)...)
+
+// BollingerBreakoutEntry opens long position when close breaks above upper band of previous candles
+// and short one when it breaks below lower band
+type BollingerBreakoutEntry struct {
+	krakenWebsocketSDK web.KrakenAnalyzer
+	feed               string
+	period             int
+	multiplier         float64
+}
+
+func NewBollingerBreakoutEntry(krakenAnalyzer web.KrakenAnalyzer, params types.StrategyParams) (*BollingerBreakoutEntry, error) {
+	period, multiplier := params.Int(bollingerPeriodParam), params.Float(bollingerMultiplierParam)
+	if _, err := indicators.NewBollingerBands(period, multiplier); err != nil {
+		return nil, err
+	}
+
+	return &BollingerBreakoutEntry{
+		krakenWebsocketSDK: krakenAnalyzer,
+		feed:               candlesFeed(params),
+		period:             period,
+		multiplier:         multiplier,
+	}, nil
+}
+
+func (e *BollingerBreakoutEntry) WaitForEntry(ctx context.Context, details types.TradingDetails) error {
+	bands, err := indicators.NewBollingerBands(e.period, e.multiplier)
+	if err != nil {
+		return fmt.Errorf("%s: %w", BollingerBreakoutName, err)
+	}
+
+	err = waitForSignal(ctx, e.krakenWebsocketSDK, e.feed, details, e.period, func(bar indicators.Bar, isLong bool) bool {
+		prev, ready := bands.Value(), bands.Ready()
+		bands.Update(bar.Close)
+		if !ready {
+			return false
+		}
+		return isLong && bar.Close > prev.Upper || !isLong && bar.Close < prev.Lower
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", BollingerBreakoutName, err)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package algorithms
+
+import (
+	"trade-bot/internal/pkg/tradeAlgorithm/types"
+	"trade-bot/pkg/krakenFuturesWSSDK"
+)
+
+const (
+	resolutionParam  = "resolution"
+	priceSourceParam = "price_source"
+)
+
+// candlesParams declares resolution and price source of candles which strategy analyzes, values are
+// defaults of the strategy and can be changed by params of every session
+func candlesParams(resolution, priceSource string) types.ParamsSchema {
+	return types.ParamsSchema{
+		{
+			Name:        resolutionParam,
+			Type:        types.StringParam,
+			Description: "resolution of candles strategy analyzes",
+			Enum:        krakenFuturesWSSDK.Resolutions,
+			Default:     resolution,
+		},
+		{
+			Name:        priceSourceParam,
+			Type:        types.StringParam,
+			Description: "price of candles strategy analyzes: trade, mark or spot price",
+			Enum:        krakenFuturesWSSDK.PriceSources,
+			Default:     priceSource,
+		},
+	}
+}
+
+// candlesFeed returns feed of candles selected by params, params without them select 1 minute trade candles
+func candlesFeed(params types.StrategyParams) string {
+	resolution, priceSource := params.String(resolutionParam), params.String(priceSourceParam)
+	if resolution == "" {
+		resolution = krakenFuturesWSSDK.OneMinuteResolution
+	}
+	if priceSource == "" {
+		priceSource = krakenFuturesWSSDK.TradePriceSource
+	}
+	return krakenFuturesWSSDK.CandlesFeed(priceSource, resolution)
+}
@@ -0,0 +1,83 @@
+package algorithms
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/internal/pkg/tradeAlgorithm/types"
+	"trade-bot/pkg/krakenFuturesWSSDK"
+)
+
+// feedAnalyzer is historyAnalyzer which records feeds candles were requested from
+type feedAnalyzer struct {
+	historyAnalyzer
+	feeds *[]string
+}
+
+func (f feedAnalyzer) LookForCandles(ctx context.Context, feed string, productIDs []string) (<-chan krakenFuturesWSSDK.Candle, error) {
+	*f.feeds = append(*f.feeds, feed)
+	return f.historyAnalyzer.LookForCandles(ctx, feed, productIDs)
+}
+
+func (f feedAnalyzer) HistoricalCandles(ctx context.Context, feed, symbol string, count int) ([]krakenFuturesWSSDK.Candle, error) {
+	*f.feeds = append(*f.feeds, feed)
+	return f.historyAnalyzer.HistoricalCandles(ctx, feed, symbol, count)
+}
+
+func TestCandlesFeed(t *testing.T) {
+	tests := []struct {
+		name     string
+		params   types.StrategyParams
+		wantFeed string
+	}{
+		{
+			name:     "Defaults of strategy",
+			params:   RSISchema.WithDefaults(types.StrategyParams{}),
+			wantFeed: krakenFuturesWSSDK.OneMinuteCandlesFeed,
+		},
+		{
+			name:     "Params without candles params",
+			params:   types.StrategyParams{},
+			wantFeed: krakenFuturesWSSDK.OneMinuteCandlesFeed,
+		},
+		{
+			name:     "Mark price candles",
+			params:   RSISchema.WithDefaults(types.StrategyParams{"resolution": "5m", "price_source": "mark"}),
+			wantFeed: krakenFuturesWSSDK.FiveMinutesMarkCandlesFeed,
+		},
+		{
+			name:     "Spot price candles",
+			params:   types.StrategyParams{"resolution": "1h", "price_source": "spot"},
+			wantFeed: krakenFuturesWSSDK.OneHourSpotCandlesFeed,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.wantFeed, candlesFeed(test.params))
+		})
+	}
+
+	err := TrailingStopSchema.Validate(types.StrategyParams{"trail_distance": 1.0, "resolution": "2m"})
+	assert.Contains(t, err.Error(), types.ErrParamNotInEnum.Error())
+	err = EMACrossoverSchema.Validate(types.StrategyParams{"price_source": "last"})
+	assert.Contains(t, err.Error(), types.ErrParamNotInEnum.Error())
+}
+
+func TestEntrySignals_Feed(t *testing.T) {
+	var feeds []string
+	analyzer := feedAnalyzer{
+		historyAnalyzer: historyAnalyzer{fakeAnalyzer: closeCandles(10, 9, 8), history: closeCandles(10)},
+		feeds:           &feeds,
+	}
+	params := RSISchema.WithDefaults(types.StrategyParams{"period": 2.0, "resolution": "15m", "price_source": "mark"})
+	signal, err := NewRSIEntry(analyzer, params)
+	assert.NoError(t, err)
+
+	assert.NoError(t, signal.WaitForEntry(context.Background(), types.TradingDetails{Symbol: "pi_xbtusd", Side: "buy"}))
+	// indicators are warmed up and fed with candles of the same feed
+	feed := krakenFuturesWSSDK.FifteenMinutesMarkCandlesFeed
+	assert.Equal(t, []string{feed, feed}, feeds)
+}
@@ -0,0 +1,89 @@
+package algorithms
+
+import (
+	"context"
+	"fmt"
+
+	"trade-bot/internal/pkg/tradeAlgorithm/indicators"
+	"trade-bot/internal/pkg/tradeAlgorithm/types"
+	"trade-bot/internal/pkg/web"
+	"trade-bot/pkg/krakenFuturesWSSDK"
+)
+
+const (
+	EMACrossoverName = "ema_crossover"
+
+	fastPeriodParam = "fast_period"
+	slowPeriodParam = "slow_period"
+)
+
+var EMACrossoverSchema = append(types.ParamsSchema{
+	{
+		Name:        fastPeriodParam,
+		Type:        types.IntegerParam,
+		Description: "number of candles in fast EMA",
+		Min:         types.Float(1),
+		Default:     float64(9),
+	},
+	{
+		Name:        slowPeriodParam,
+		Type:        types.IntegerParam,
+		Description: "number of candles in slow EMA",
+		Min:         types.Float(2),
+		Default:     float64(21),
+	},
+}, candlesParams(krakenFuturesWSSDK.OneMinuteResolution, krakenFuturesWSSDK.TradePriceSource)...)
+
+// EMACrossoverEntry opens long position when fast EMA crosses above slow EMA and short one when it crosses below
+type EMACrossoverEntry struct {
+	krakenWebsocketSDK web.KrakenAnalyzer
+	feed               string
+	fastPeriod         int
+	slowPeriod         int
+}
+
+func NewEMACrossoverEntry(krakenAnalyzer web.KrakenAnalyzer, params types.StrategyParams) (*EMACrossoverEntry, error) {
+	fastPeriod, slowPeriod := params.Int(fastPeriodParam), params.Int(slowPeriodParam)
+	if fastPeriod >= slowPeriod {
+		return nil, ErrInvalidPeriods
+	}
+	if _, err := indicators.NewEMA(fastPeriod); err != nil {
+		return nil, err
+	}
+
+	return &EMACrossoverEntry{
+		krakenWebsocketSDK: krakenAnalyzer,
+		feed:               candlesFeed(params),
+		fastPeriod:         fastPeriod,
+		slowPeriod:         slowPeriod,
+	}, nil
+}
+
+func (e *EMACrossoverEntry) WaitForEntry(ctx context.Context, details types.TradingDetails) error {
+	fast, err := indicators.NewEMA(e.fastPeriod)
+	if err != nil {
+		return fmt.Errorf("%s: %w", EMACrossoverName, err)
+	}
+	slow, err := indicators.NewEMA(e.slowPeriod)
+	if err != nil {
+		return fmt.Errorf("%s: %w", EMACrossoverName, err)
+	}
+	var prevDiff float64
+	hasPrev := false
+
+	// EMA depends on all previous candles, so it is warmed up on twice its period to forget the first ones
+	err = waitForSignal(ctx, e.krakenWebsocketSDK, e.feed, details, 2*e.slowPeriod, func(bar indicators.Bar, isLong bool) bool {
+		diff := fast.Update(bar.Close) - slow.Update(bar.Close)
+		if !slow.Ready() {
+			return false
+		}
+
+		crossed := hasPrev && (isLong && prevDiff <= 0 && diff > 0 || !isLong && prevDiff >= 0 && diff < 0)
+		prevDiff, hasPrev = diff, true
+		return crossed
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", EMACrossoverName, err)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package algorithms
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"trade-bot/internal/pkg/tradeAlgorithm/indicators"
+	"trade-bot/internal/pkg/tradeAlgorithm/types"
+	"trade-bot/internal/pkg/web"
+	"trade-bot/pkg/krakenFuturesSDK"
+	"trade-bot/pkg/krakenFuturesWSSDK"
+)
+
+var (
+	ErrWaitForEntry        = errors.New("wait for entry")
+	ErrInvalidPeriods      = errors.New("fast period must be less than slow period")
+	ErrInvalidRSIThreshold = errors.New("oversold threshold must be less than overbought threshold")
+	ErrWarmUp              = errors.New("warm up indicators, waiting for entry with cold ones")
+	ErrCandleIsNotClosed   = errors.New("candle of period which is not over is skipped")
+)
+
+// signalFunc is called with every candle and reports whether position of the given side should be opened
+type signalFunc func(bar indicators.Bar, isLong bool) bool
+
+// waitForSignal feeds candles of feed for details.Symbol to signal until it fires. Signal is warmed up with warmup
+// closed candles first if analyzer can load them, so it may fire on the first live candle.
+// Indicators are defined on closed candles only, so candle of period which is not over yet is never fed
+func waitForSignal(ctx context.Context, analyzer web.KrakenAnalyzer, feed string, details types.TradingDetails,
+	warmup int, signal signalFunc) error {
+	var isLong bool
+	switch details.Side {
+	case krakenFuturesSDK.BuySide:
+		isLong = true
+	case krakenFuturesSDK.SellSide:
+	default:
+		return fmt.Errorf("%s: %s: %s", ErrWaitForEntry, ErrInvalidSide, details.Side)
+	}
+
+	period := feedPeriod(feed)
+	lastWarmupTime := warmUp(ctx, analyzer, feed, details.Symbol, warmup, period, func(bar indicators.Bar) {
+		signal(bar, isLong)
+	})
+
+	candles, err := analyzer.LookForCandles(ctx, feed, []string{details.Symbol})
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrWaitForEntry, err)
+	}
+
+	for candle := range candles {
+		if candle.Time <= lastWarmupTime {
+			continue
+		}
+		if !isClosed(candle, period, time.Now()) {
+			log.Warnf("%s: %s: %d", ErrWaitForEntry, ErrCandleIsNotClosed, candle.Time)
+			continue
+		}
+
+		if signal(indicators.NewBar(candle), isLong) {
+			return nil
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%s: %w", ErrWaitForEntry, err)
+	}
+	return fmt.Errorf("%s: %s", ErrWaitForEntry, ErrUnableToGetCandles)
+}
+
+// warmUp feeds closed candles to update and returns time of the last one. Signal which can't be warmed up
+// just waits longer, so errors are only logged
+func warmUp(ctx context.Context, analyzer web.KrakenAnalyzer, feed, symbol string, count int, period time.Duration,
+	update func(bar indicators.Bar)) int {
+	history, ok := analyzer.(web.KrakenCandlesHistory)
+	if !ok || count <= 0 {
+		return 0
+	}
+
+	candles, err := history.HistoricalCandles(ctx, feed, symbol, count)
+	if err != nil {
+		log.Warnf("%s: %s", ErrWarmUp, err)
+		return 0
+	}
+
+	var lastTime int
+	now := time.Now()
+	for _, candle := range candles {
+		if !isClosed(candle, period, now) {
+			log.Warnf("%s: %s: %d", ErrWarmUp, ErrCandleIsNotClosed, candle.Time)
+			break
+		}
+		update(indicators.NewBar(candle))
+		lastTime = candle.Time
+	}
+	return lastTime
+}
+
+// feedPeriod returns period of candles of feed, it is zero for unknown feed
+func feedPeriod(feed string) time.Duration {
+	_, resolution, err := krakenFuturesWSSDK.ParseCandlesFeed(feed)
+	if err != nil {
+		return 0
+	}
+	period, _ := krakenFuturesWSSDK.ResolutionDuration(resolution)
+	return period
+}
+
+// isClosed reports whether period of candle is over at now
+func isClosed(candle krakenFuturesWSSDK.Candle, period time.Duration, now time.Time) bool {
+	return !time.Unix(int64(candle.Time), 0).Add(period).After(now)
+}
@@ -0,0 +1,192 @@
+package algorithms
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/internal/pkg/tradeAlgorithm/indicators"
+	"trade-bot/internal/pkg/tradeAlgorithm/types"
+	"trade-bot/internal/pkg/web"
+	"trade-bot/pkg/krakenFuturesWSSDK"
+)
+
+type entrySignal interface {
+	WaitForEntry(ctx context.Context, details types.TradingDetails) error
+}
+
+func TestEntrySignals_WaitForEntry(t *testing.T) {
+	emaCrossover := func(analyzer web.KrakenAnalyzer) entrySignal {
+		signal, err := NewEMACrossoverEntry(analyzer, types.StrategyParams{"fast_period": 2.0, "slow_period": 3.0})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signal
+	}
+	rsi := func(analyzer web.KrakenAnalyzer) entrySignal {
+		signal, err := NewRSIEntry(analyzer, types.StrategyParams{"period": 2.0, "oversold": 30.0, "overbought": 70.0})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signal
+	}
+	bollinger := func(analyzer web.KrakenAnalyzer) entrySignal {
+		signal, err := NewBollingerBreakoutEntry(analyzer, types.StrategyParams{"period": 2.0, "multiplier": 1.0})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signal
+	}
+
+	tests := []struct {
+		name    string
+		signal  func(analyzer web.KrakenAnalyzer) entrySignal
+		side    string
+		prices  []float64
+		wantErr bool
+	}{
+		{
+			name:   "EMA crossover up opens long",
+			signal: emaCrossover,
+			side:   "buy",
+			prices: []float64{10, 10, 10, 12},
+		},
+		{
+			name:   "EMA crossover down opens short",
+			signal: emaCrossover,
+			side:   "sell",
+			prices: []float64{10, 10, 10, 8},
+		},
+		{
+			name:    "EMA crossover down does not open long",
+			signal:  emaCrossover,
+			side:    "buy",
+			prices:  []float64{10, 10, 10, 8},
+			wantErr: true,
+		},
+		{
+			name:   "RSI oversold opens long",
+			signal: rsi,
+			side:   "buy",
+			prices: []float64{10, 9, 8},
+		},
+		{
+			name:   "RSI overbought opens short",
+			signal: rsi,
+			side:   "sell",
+			prices: []float64{10, 11, 12},
+		},
+		{
+			name:    "RSI overbought does not open long",
+			signal:  rsi,
+			side:    "buy",
+			prices:  []float64{10, 11, 12},
+			wantErr: true,
+		},
+		{
+			name:   "Bollinger breakout up opens long",
+			signal: bollinger,
+			side:   "buy",
+			prices: []float64{10, 10, 11},
+		},
+		{
+			name:   "Bollinger breakout down opens short",
+			signal: bollinger,
+			side:   "sell",
+			prices: []float64{10, 10, 9},
+		},
+		{
+			name:    "Bollinger breakout down does not open long",
+			signal:  bollinger,
+			side:    "buy",
+			prices:  []float64{10, 10, 9},
+			wantErr: true,
+		},
+		{
+			name:    "Invalid side",
+			signal:  bollinger,
+			side:    "hold",
+			prices:  []float64{10, 10, 11},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			signal := test.signal(closeCandles(test.prices...))
+
+			err := signal.WaitForEntry(context.Background(), types.TradingDetails{Symbol: "pi_xbtusd", Side: test.side})
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestEntrySignals_InvalidParams(t *testing.T) {
+	_, err := NewEMACrossoverEntry(closeCandles(), types.StrategyParams{"fast_period": 21.0, "slow_period": 9.0})
+	assert.ErrorIs(t, err, ErrInvalidPeriods)
+
+	_, err = NewRSIEntry(closeCandles(), types.StrategyParams{"period": 14.0, "oversold": 70.0, "overbought": 30.0})
+	assert.ErrorIs(t, err, ErrInvalidRSIThreshold)
+
+	// params which were not validated against schema are still checked by indicators
+	_, err = NewEMACrossoverEntry(closeCandles(), types.StrategyParams{"fast_period": 0.0, "slow_period": 9.0})
+	assert.ErrorIs(t, err, indicators.ErrInvalidPeriod)
+
+	_, err = NewRSIEntry(closeCandles(), types.StrategyParams{"period": 0.0, "oversold": 30.0, "overbought": 70.0})
+	assert.ErrorIs(t, err, indicators.ErrInvalidPeriod)
+
+	_, err = NewBollingerBreakoutEntry(closeCandles(), types.StrategyParams{"period": -1.0, "multiplier": 1.0})
+	assert.ErrorIs(t, err, indicators.ErrInvalidPeriod)
+}
+
+// historyAnalyzer is fakeAnalyzer which can load closed candles before live ones
+type historyAnalyzer struct {
+	fakeAnalyzer
+	history fakeAnalyzer
+}
+
+func (h historyAnalyzer) HistoricalCandles(_ context.Context, _, _ string, count int) ([]krakenFuturesWSSDK.Candle, error) {
+	if len(h.history) > count {
+		return h.history[len(h.history)-count:], nil
+	}
+	return h.history, nil
+}
+
+func TestEntrySignals_WarmUp(t *testing.T) {
+	signal, err := NewEMACrossoverEntry(nil, types.StrategyParams{"fast_period": 2.0, "slow_period": 3.0})
+	assert.NoError(t, err)
+
+	// live feed alone is too short for EMA to cross
+	signal.krakenWebsocketSDK = closeCandles(10, 12)
+	assert.Error(t, signal.WaitForEntry(context.Background(), types.TradingDetails{Symbol: "pi_xbtusd", Side: "buy"}))
+
+	history := closeCandles(10, 10, 10, 10, 12)
+	warmedUp := closeCandles(10, 10, 10, 10, 12, 12)[5:]
+	// warmed up EMA crosses on the first live candle
+	signal.krakenWebsocketSDK = historyAnalyzer{fakeAnalyzer: history[4:], history: history[:4]}
+	assert.NoError(t, signal.WaitForEntry(context.Background(), types.TradingDetails{Symbol: "pi_xbtusd", Side: "buy"}))
+
+	// crossing which happened on already loaded candle does not fire signal again
+	signal.krakenWebsocketSDK = historyAnalyzer{fakeAnalyzer: append(fakeAnalyzer{history[4]}, warmedUp...), history: history}
+	assert.Error(t, signal.WaitForEntry(context.Background(), types.TradingDetails{Symbol: "pi_xbtusd", Side: "buy"}))
+}
+
+func TestEntrySignals_CandleIsNotClosed(t *testing.T) {
+	signal, err := NewEMACrossoverEntry(nil, types.StrategyParams{"fast_period": 2.0, "slow_period": 3.0})
+	assert.NoError(t, err)
+
+	// crossing candle of the current minute is opening tick of its period, it is not fed to indicators
+	candles := closeCandles(10, 10, 10, 12)
+	candles[3].Time = int(time.Now().Unix())
+	signal.krakenWebsocketSDK = candles
+	assert.Error(t, signal.WaitForEntry(context.Background(), types.TradingDetails{Symbol: "pi_xbtusd", Side: "buy"}))
+
+	candles[3].Time = int(time.Now().Add(-time.Minute).Unix())
+	assert.NoError(t, signal.WaitForEntry(context.Background(), types.TradingDetails{Symbol: "pi_xbtusd", Side: "buy"}))
+}
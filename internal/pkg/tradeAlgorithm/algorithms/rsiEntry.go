@@ -0,0 +1,92 @@
+package algorithms
+
+import (
+	"context"
+	"fmt"
+
+	"trade-bot/internal/pkg/tradeAlgorithm/indicators"
+	"trade-bot/internal/pkg/tradeAlgorithm/types"
+	"trade-bot/internal/pkg/web"
+	"trade-bot/pkg/krakenFuturesWSSDK"
+)
+
+const (
+	RSIName = "rsi"
+
+	rsiPeriodParam  = "period"
+	oversoldParam   = "oversold"
+	overboughtParam = "overbought"
+)
+
+var RSISchema = append(types.ParamsSchema{
+	{
+		Name:        rsiPeriodParam,
+		Type:        types.IntegerParam,
+		Description: "number of candles in RSI",
+		Min:         types.Float(1),
+		Default:     float64(14),
+	},
+	{
+		Name:        oversoldParam,
+		Type:        types.NumberParam,
+		Description: "RSI at or below which long position is opened",
+		Min:         types.Float(0),
+		Max:         types.Float(100),
+		Default:     float64(30),
+	},
+	{
+		Name:        overboughtParam,
+		Type:        types.NumberParam,
+		Description: "RSI at or above which short position is opened",
+		Min:         types.Float(0),
+		Max:         types.Float(100),
+		Default:     float64(70),
+	},
+}, candlesParams(krakenFuturesWSSDK.OneMinuteResolution, krakenFuturesWSSDK.TradePriceSource)...)
+
+// RSIEntry opens long position when market is oversold and short one when it is overbought
+type RSIEntry struct {
+	krakenWebsocketSDK web.KrakenAnalyzer
+	feed               string
+	period             int
+	oversold           float64
+	overbought         float64
+}
+
+func NewRSIEntry(krakenAnalyzer web.KrakenAnalyzer, params types.StrategyParams) (*RSIEntry, error) {
+	oversold, overbought := params.Float(oversoldParam), params.Float(overboughtParam)
+	if oversold >= overbought {
+		return nil, ErrInvalidRSIThreshold
+	}
+	period := params.Int(rsiPeriodParam)
+	if _, err := indicators.NewRSI(period); err != nil {
+		return nil, err
+	}
+
+	return &RSIEntry{
+		krakenWebsocketSDK: krakenAnalyzer,
+		feed:               candlesFeed(params),
+		period:             period,
+		oversold:           oversold,
+		overbought:         overbought,
+	}, nil
+}
+
+func (e *RSIEntry) WaitForEntry(ctx context.Context, details types.TradingDetails) error {
+	rsi, err := indicators.NewRSI(e.period)
+	if err != nil {
+		return fmt.Errorf("%s: %w", RSIName, err)
+	}
+
+	err = waitForSignal(ctx, e.krakenWebsocketSDK, e.feed, details, 2*e.period+1, func(bar indicators.Bar, isLong bool) bool {
+		value := rsi.Update(bar.Close)
+		if !rsi.Ready() {
+			return false
+		}
+		return isLong && value <= e.oversold || !isLong && value >= e.overbought
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", RSIName, err)
+	}
+	return nil
+}
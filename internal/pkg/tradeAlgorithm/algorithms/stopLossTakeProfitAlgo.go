@@ -3,55 +3,133 @@ package algorithms
 import (
 	"context"
 	"fmt"
-	"strconv"
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
 
 	"trade-bot/internal/pkg/tradeAlgorithm/types"
 	"trade-bot/internal/pkg/web"
+	"trade-bot/pkg/krakenFuturesSDK"
 	"trade-bot/pkg/krakenFuturesWSSDK"
 )
 
 var (
 	ErrStartAnalyzing     = errors.New("start analyzing")
 	ErrUnableToGetCandles = errors.New("unable to get candles")
+	ErrUnknownTickSize    = errors.New("tick size of instrument is unknown")
+	ErrInvalidSide        = errors.New("invalid side")
 )
 
+const (
+	StopLossTakeProfitName = "stop_loss_take_profit"
+
+	stopLossBorderParam   = "stop_loss_border"
+	takeProfitBorderParam = "take_profit_border"
+	borderTypeParam       = "border_type"
+
+	AbsoluteBorder = "absolute"
+	PercentBorder  = "percent"
+	TicksBorder    = "ticks"
+)
+
+var StopLossTakeProfitSchema = append(types.ParamsSchema{
+	{
+		Name:        stopLossBorderParam,
+		Type:        types.NumberParam,
+		Required:    true,
+		Description: "distance from entry price in the losing direction at which the position will be closed",
+		Min:         types.Float(0),
+	},
+	{
+		Name:        takeProfitBorderParam,
+		Type:        types.NumberParam,
+		Required:    true,
+		Description: "distance from entry price in the winning direction at which the position will be closed",
+		Min:         types.Float(0),
+	},
+	{
+		Name:        borderTypeParam,
+		Type:        types.StringParam,
+		Description: "units of borders: absolute price delta, percent of entry price or ticks of instrument tick size",
+		Enum:        []string{AbsoluteBorder, PercentBorder, TicksBorder},
+		Default:     AbsoluteBorder,
+	},
+}, candlesParams(krakenFuturesWSSDK.OneMinuteResolution, krakenFuturesWSSDK.TradePriceSource)...)
+
 type StopLossTakeProfitAlgo struct {
 	krakenWebsocketSDK web.KrakenAnalyzer
+	feed               string
+	stopLossBorder     decimal.Decimal
+	takeProfitBorder   decimal.Decimal
+	borderType         string
 }
 
-func NewStopLossTakeProfitAlgo(krakenAnalyzer web.KrakenAnalyzer) *StopLossTakeProfitAlgo {
+func NewStopLossTakeProfitAlgo(krakenAnalyzer web.KrakenAnalyzer, params types.StrategyParams) *StopLossTakeProfitAlgo {
 	return &StopLossTakeProfitAlgo{
 		krakenWebsocketSDK: krakenAnalyzer,
+		feed:               candlesFeed(params),
+		stopLossBorder:     decimal.NewFromFloat(params.Float(stopLossBorderParam)),
+		takeProfitBorder:   decimal.NewFromFloat(params.Float(takeProfitBorderParam)),
+		borderType:         params.String(borderTypeParam),
+	}
+}
+
+// levels returns prices of stop loss and take profit for position opened at details.BuyPrice
+func (a *StopLossTakeProfitAlgo) levels(details types.TradingDetails) (stopLoss, takeProfit decimal.Decimal, err error) {
+	stopLossDelta, takeProfitDelta := a.stopLossBorder, a.takeProfitBorder
+
+	switch a.borderType {
+	case PercentBorder:
+		hundred := decimal.NewFromInt(100)
+		stopLossDelta = details.BuyPrice.Mul(a.stopLossBorder).Div(hundred)
+		takeProfitDelta = details.BuyPrice.Mul(a.takeProfitBorder).Div(hundred)
+	case TicksBorder:
+		if !details.TickSize.IsPositive() {
+			return decimal.Zero, decimal.Zero, fmt.Errorf("%s: %s", ErrUnknownTickSize, details.Symbol)
+		}
+		stopLossDelta = details.TickSize.Mul(a.stopLossBorder)
+		takeProfitDelta = details.TickSize.Mul(a.takeProfitBorder)
+	}
+
+	switch details.Side {
+	case krakenFuturesSDK.BuySide:
+		return details.BuyPrice.Sub(stopLossDelta), details.BuyPrice.Add(takeProfitDelta), nil
+	case krakenFuturesSDK.SellSide:
+		return details.BuyPrice.Add(stopLossDelta), details.BuyPrice.Sub(takeProfitDelta), nil
+	default:
+		return decimal.Zero, decimal.Zero, fmt.Errorf("%s: %s", ErrInvalidSide, details.Side)
 	}
 }
 
-func (a *StopLossTakeProfitAlgo) StartAnalyzing(ctx context.Context, buyTime time.Time, details types.TradingDetails) error {
-	candles, err := a.krakenWebsocketSDK.LookForCandles(ctx, krakenFuturesWSSDK.OneMinuteCandlesFeed, []string{details.Symbol})
+func (a *StopLossTakeProfitAlgo) StartAnalyzing(ctx context.Context, buyTime time.Time, details types.TradingDetails) (types.ExitReason, error) {
+	stopLoss, takeProfit, err := a.levels(details)
 	if err != nil {
-		return fmt.Errorf("%s: %w", ErrStartAnalyzing, err)
+		return "", fmt.Errorf("%s: %w", ErrStartAnalyzing, err)
 	}
+	isLong := details.Side == krakenFuturesSDK.BuySide
 
-	for candle := range candles {
-		price, err := strconv.ParseFloat(candle.Close, 64)
+	candles, err := a.krakenWebsocketSDK.LookForCandles(ctx, a.feed, []string{details.Symbol})
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", ErrStartAnalyzing, err)
+	}
 
+	for candle := range candles {
 		if time.Unix(int64(candle.Time), 0).Before(buyTime) {
 			continue
 		}
 
-		if err != nil {
-			return fmt.Errorf("%s: %w", ErrStartAnalyzing, err)
-		}
-
-		if price > details.BuyPrice+details.TakeProfitBorder {
-			return nil
-		}
-		if price < details.BuyPrice-details.StopLossBorder {
-			return nil
+		price := candle.Close
+		switch {
+		case isLong && price.GreaterThanOrEqual(takeProfit), !isLong && price.LessThanOrEqual(takeProfit):
+			return types.ExitReasonTakeProfit, nil
+		case isLong && price.LessThanOrEqual(stopLoss), !isLong && price.GreaterThanOrEqual(stopLoss):
+			return types.ExitReasonStopLoss, nil
 		}
 	}
 
-	return fmt.Errorf("%s: %s", ErrStartAnalyzing, ErrUnableToGetCandles)
+	if ctx.Err() != nil {
+		return types.ExitReasonCancelled, nil
+	}
+	return "", fmt.Errorf("%s: %s", ErrStartAnalyzing, ErrUnableToGetCandles)
 }
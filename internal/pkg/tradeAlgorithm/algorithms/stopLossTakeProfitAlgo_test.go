@@ -0,0 +1,135 @@
+package algorithms
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/internal/pkg/tradeAlgorithm/types"
+	"trade-bot/pkg/krakenFuturesWSSDK"
+)
+
+type fakeAnalyzer []krakenFuturesWSSDK.Candle
+
+func (f fakeAnalyzer) LookForCandles(ctx context.Context, _ string, _ []string) (<-chan krakenFuturesWSSDK.Candle, error) {
+	candles := make(chan krakenFuturesWSSDK.Candle, len(f))
+	for _, candle := range f {
+		candles <- candle
+	}
+	close(candles)
+	return candles, nil
+}
+
+// closeCandles returns minute candles with high, low and close equal to price
+func closeCandles(prices ...float64) fakeAnalyzer {
+	candles := make(fakeAnalyzer, len(prices))
+	for i, price := range prices {
+		p := decimal.NewFromFloat(price)
+		candles[i] = krakenFuturesWSSDK.Candle{Time: 1650000000 + i*60, Open: p, High: p, Low: p, Close: p}
+	}
+	return candles
+}
+
+func TestStopLossTakeProfitAlgo_StartAnalyzing(t *testing.T) {
+	tests := []struct {
+		name     string
+		params   types.StrategyParams
+		side     string
+		tickSize float64
+		prices   []float64
+		want     types.ExitReason
+		wantErr  bool
+	}{
+		{
+			name:   "Long take profit",
+			params: types.StrategyParams{"stop_loss_border": 5.0, "take_profit_border": 10.0},
+			side:   "buy",
+			prices: []float64{101, 96, 110},
+			want:   types.ExitReasonTakeProfit,
+		},
+		{
+			name:   "Long stop loss",
+			params: types.StrategyParams{"stop_loss_border": 5.0, "take_profit_border": 10.0},
+			side:   "buy",
+			prices: []float64{101, 95},
+			want:   types.ExitReasonStopLoss,
+		},
+		{
+			name:   "Short take profit",
+			params: types.StrategyParams{"stop_loss_border": 5.0, "take_profit_border": 10.0},
+			side:   "sell",
+			prices: []float64{104, 90},
+			want:   types.ExitReasonTakeProfit,
+		},
+		{
+			name:   "Short stop loss",
+			params: types.StrategyParams{"stop_loss_border": 5.0, "take_profit_border": 10.0},
+			side:   "sell",
+			prices: []float64{91, 105},
+			want:   types.ExitReasonStopLoss,
+		},
+		{
+			name:   "Percent borders",
+			params: types.StrategyParams{"stop_loss_border": 1.0, "take_profit_border": 2.0, "border_type": "percent"},
+			side:   "sell",
+			prices: []float64{100.9, 101},
+			want:   types.ExitReasonStopLoss,
+		},
+		{
+			name:     "Ticks borders",
+			params:   types.StrategyParams{"stop_loss_border": 10.0, "take_profit_border": 4.0, "border_type": "ticks"},
+			side:     "buy",
+			tickSize: 0.5,
+			prices:   []float64{101.5, 102},
+			want:     types.ExitReasonTakeProfit,
+		},
+		{
+			name:    "Ticks borders without tick size",
+			params:  types.StrategyParams{"stop_loss_border": 10.0, "take_profit_border": 4.0, "border_type": "ticks"},
+			side:    "buy",
+			prices:  []float64{102},
+			wantErr: true,
+		},
+		{
+			name:    "Candles are over",
+			params:  types.StrategyParams{"stop_loss_border": 5.0, "take_profit_border": 10.0},
+			side:    "buy",
+			prices:  []float64{101},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			params := StopLossTakeProfitSchema.WithDefaults(test.params)
+			assert.NoError(t, StopLossTakeProfitSchema.Validate(params))
+
+			algo := NewStopLossTakeProfitAlgo(closeCandles(test.prices...), params)
+			reason, err := algo.StartAnalyzing(context.Background(), time.Unix(1650000000, 0), types.TradingDetails{
+				Symbol:   "pi_xbtusd",
+				Side:     test.side,
+				BuyPrice: decimal.NewFromInt(100),
+				TickSize: decimal.NewFromFloat(test.tickSize),
+			})
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, reason)
+		})
+	}
+}
+
+func TestStopLossTakeProfitAlgo_StartAnalyzingCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	algo := NewStopLossTakeProfitAlgo(fakeAnalyzer{}, types.StrategyParams{"stop_loss_border": 5.0, "take_profit_border": 10.0})
+	reason, err := algo.StartAnalyzing(ctx, time.Unix(1650000000, 0), types.TradingDetails{Side: "buy", BuyPrice: decimal.NewFromInt(100)})
+	assert.NoError(t, err)
+	assert.Equal(t, types.ExitReasonCancelled, reason)
+}
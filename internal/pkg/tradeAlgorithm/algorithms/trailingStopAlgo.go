@@ -0,0 +1,157 @@
+package algorithms
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"trade-bot/internal/pkg/tradeAlgorithm/indicators"
+	"trade-bot/internal/pkg/tradeAlgorithm/types"
+	"trade-bot/internal/pkg/web"
+	"trade-bot/pkg/krakenFuturesSDK"
+	"trade-bot/pkg/krakenFuturesWSSDK"
+)
+
+const (
+	TrailingStopName = "trailing_stop"
+
+	trailDistanceParam       = "trail_distance"
+	distanceTypeParam        = "distance_type"
+	activationDistanceParam  = "activation_distance"
+	atrPeriodParam           = "atr_period"
+	ATRDistance              = "atr"
+	defaultTrailingATRPeriod = 14
+)
+
+var TrailingStopSchema = append(types.ParamsSchema{
+	{
+		Name:        trailDistanceParam,
+		Type:        types.NumberParam,
+		Required:    true,
+		Description: "retracement from the best price since entry at which the position will be closed",
+		Min:         types.Float(0),
+	},
+	{
+		Name:        distanceTypeParam,
+		Type:        types.StringParam,
+		Description: "units of distances: absolute price delta, percent of price or multiple of ATR",
+		Enum:        []string{AbsoluteBorder, PercentBorder, ATRDistance},
+		Default:     AbsoluteBorder,
+	},
+	{
+		Name: activationDistanceParam,
+		Type: types.NumberParam,
+		Description: "profit from entry price after which the stop starts trailing, " +
+			"until then it stays at trail distance from entry price",
+		Min:     types.Float(0),
+		Default: float64(0),
+	},
+	{
+		Name:        atrPeriodParam,
+		Type:        types.IntegerParam,
+		Description: "number of candles in ATR when distance type is atr",
+		Min:         types.Float(1),
+		Default:     float64(defaultTrailingATRPeriod),
+	},
+}, candlesParams(krakenFuturesWSSDK.OneMinuteResolution, krakenFuturesWSSDK.TradePriceSource)...)
+
+type TrailingStopAlgo struct {
+	krakenWebsocketSDK web.KrakenAnalyzer
+	feed               string
+	trailDistance      decimal.Decimal
+	distanceType       string
+	activationDistance decimal.Decimal
+	atrPeriod          int
+}
+
+func NewTrailingStopAlgo(krakenAnalyzer web.KrakenAnalyzer, params types.StrategyParams) (*TrailingStopAlgo, error) {
+	atrPeriod := params.Int(atrPeriodParam)
+	if _, err := indicators.NewATR(atrPeriod); err != nil {
+		return nil, err
+	}
+
+	return &TrailingStopAlgo{
+		krakenWebsocketSDK: krakenAnalyzer,
+		feed:               candlesFeed(params),
+		trailDistance:      decimal.NewFromFloat(params.Float(trailDistanceParam)),
+		distanceType:       params.String(distanceTypeParam),
+		activationDistance: decimal.NewFromFloat(params.Float(activationDistanceParam)),
+		atrPeriod:          atrPeriod,
+	}, nil
+}
+
+// distance converts value in units of distance type to price delta
+func (a *TrailingStopAlgo) distance(value, price, atr decimal.Decimal) decimal.Decimal {
+	switch a.distanceType {
+	case PercentBorder:
+		return price.Mul(value).Div(decimal.NewFromInt(100))
+	case ATRDistance:
+		return atr.Mul(value)
+	default:
+		return value
+	}
+}
+
+func (a *TrailingStopAlgo) StartAnalyzing(ctx context.Context, buyTime time.Time, details types.TradingDetails) (types.ExitReason, error) {
+	var direction decimal.Decimal
+	switch details.Side {
+	case krakenFuturesSDK.BuySide:
+		direction = decimal.NewFromInt(1)
+	case krakenFuturesSDK.SellSide:
+		direction = decimal.NewFromInt(-1)
+	default:
+		return "", fmt.Errorf("%s: %s: %s", ErrStartAnalyzing, ErrInvalidSide, details.Side)
+	}
+
+	candles, err := a.krakenWebsocketSDK.LookForCandles(ctx, a.feed, []string{details.Symbol})
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", ErrStartAnalyzing, err)
+	}
+
+	buyPrice := details.BuyPrice
+	atr, err := indicators.NewATR(a.atrPeriod)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", ErrStartAnalyzing, err)
+	}
+	best := buyPrice
+	activated := false
+
+	for candle := range candles {
+		if time.Unix(int64(candle.Time), 0).Before(buyTime) {
+			continue
+		}
+
+		price := candle.Close
+		// only ATR is float indicator, stop prices are exact like prices of orders
+		atrValue := decimal.NewFromFloat(atr.Update(indicators.NewBar(candle)))
+
+		// best price is moved only in the winning direction
+		if price.Sub(best).Mul(direction).IsPositive() {
+			best = price
+		}
+
+		if !activated {
+			activation := a.distance(a.activationDistance, buyPrice, atrValue)
+			activated = best.Sub(buyPrice).Mul(direction).GreaterThanOrEqual(activation)
+		}
+
+		stop := buyPrice.Sub(a.distance(a.trailDistance, buyPrice, atrValue).Mul(direction))
+		if activated {
+			stop = best.Sub(a.distance(a.trailDistance, best, atrValue).Mul(direction))
+		}
+
+		if !price.Sub(stop).Mul(direction).IsPositive() {
+			if activated {
+				return types.ExitReasonTrailingStop, nil
+			}
+			return types.ExitReasonStopLoss, nil
+		}
+	}
+
+	if ctx.Err() != nil {
+		return types.ExitReasonCancelled, nil
+	}
+	return "", fmt.Errorf("%s: %s", ErrStartAnalyzing, ErrUnableToGetCandles)
+}
@@ -0,0 +1,123 @@
+package algorithms
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/internal/pkg/tradeAlgorithm/indicators"
+	"trade-bot/internal/pkg/tradeAlgorithm/types"
+)
+
+func TestTrailingStopAlgo_StartAnalyzing(t *testing.T) {
+	d := decimal.RequireFromString
+	tests := []struct {
+		name     string
+		params   types.StrategyParams
+		side     string
+		buyPrice decimal.Decimal
+		candles  fakeAnalyzer
+		want     types.ExitReason
+		wantErr  bool
+	}{
+		{
+			name:    "Long trails best price",
+			params:  types.StrategyParams{"trail_distance": 5.0},
+			side:    "buy",
+			candles: closeCandles(103, 110, 120, 116, 115),
+			want:    types.ExitReasonTrailingStop,
+		},
+		{
+			name:    "Long initial stop",
+			params:  types.StrategyParams{"trail_distance": 5.0, "activation_distance": 10.0},
+			side:    "buy",
+			candles: closeCandles(104, 108, 102, 95),
+			want:    types.ExitReasonStopLoss,
+		},
+		{
+			name:    "Long is not trailed before activation",
+			params:  types.StrategyParams{"trail_distance": 5.0, "activation_distance": 10.0},
+			side:    "buy",
+			candles: closeCandles(108, 103, 111, 106),
+			want:    types.ExitReasonTrailingStop,
+		},
+		{
+			name:    "Short percent distance",
+			params:  types.StrategyParams{"trail_distance": 2.0, "distance_type": "percent"},
+			side:    "sell",
+			candles: closeCandles(99, 90, 91.7, 91.8),
+			want:    types.ExitReasonTrailingStop,
+		},
+		{
+			name:   "ATR distance",
+			params: types.StrategyParams{"trail_distance": 1.0, "distance_type": "atr", "atr_period": float64(2)},
+			side:   "buy",
+			candles: fakeAnalyzer{
+				{Time: 1650000000, Open: d("100"), High: d("102"), Low: d("100"), Close: d("101")},
+				{Time: 1650000060, Open: d("101"), High: d("111"), Low: d("109"), Close: d("110")},
+				// true range 7 makes ATR 6.5, so stop is 110-6.5
+				{Time: 1650000120, Open: d("110"), High: d("106"), Low: d("103"), Close: d("103")},
+			},
+			want: types.ExitReasonTrailingStop,
+		},
+		{
+			name:    "Candles are over",
+			params:  types.StrategyParams{"trail_distance": 5.0},
+			side:    "buy",
+			candles: closeCandles(101, 102),
+			wantErr: true,
+		},
+		{
+			// in floats 0.3-0.1 is less than 0.2, so stop would be missed
+			name:     "Stop price is exact",
+			params:   types.StrategyParams{"trail_distance": 0.1},
+			side:     "buy",
+			buyPrice: d("0.3"),
+			candles:  fakeAnalyzer{{Time: 1650000000, Open: d("0.3"), High: d("0.3"), Low: d("0.2"), Close: d("0.2")}},
+			want:     types.ExitReasonTrailingStop,
+		},
+		{
+			name:    "Invalid side",
+			params:  types.StrategyParams{"trail_distance": 5.0},
+			side:    "long",
+			candles: closeCandles(101),
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			params := TrailingStopSchema.WithDefaults(test.params)
+			assert.NoError(t, TrailingStopSchema.Validate(params))
+
+			buyPrice := test.buyPrice
+			if buyPrice.IsZero() {
+				buyPrice = decimal.NewFromInt(100)
+			}
+
+			algo, err := NewTrailingStopAlgo(test.candles, params)
+			assert.NoError(t, err)
+			reason, err := algo.StartAnalyzing(context.Background(), time.Unix(1650000000, 0), types.TradingDetails{
+				Symbol:   "pi_xbtusd",
+				Side:     test.side,
+				BuyPrice: buyPrice,
+			})
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, reason)
+		})
+	}
+}
+
+func TestNewTrailingStopAlgo_InvalidATRPeriod(t *testing.T) {
+	params := TrailingStopSchema.WithDefaults(types.StrategyParams{"trail_distance": 1.0, "atr_period": 0.0})
+
+	_, err := NewTrailingStopAlgo(closeCandles(), params)
+	assert.ErrorIs(t, err, indicators.ErrInvalidPeriod)
+}
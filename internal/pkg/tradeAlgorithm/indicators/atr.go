@@ -0,0 +1,43 @@
+package indicators
+
+import "math"
+
+// ATR is Wilder's average true range. Until period bars are seen it is the average of seen true ranges
+type ATR struct {
+	period    int
+	count     int
+	prevClose float64
+	value     float64
+}
+
+func NewATR(period int) (*ATR, error) {
+	if err := checkPeriod(period); err != nil {
+		return nil, err
+	}
+	return &ATR{period: period}, nil
+}
+
+func (a *ATR) Update(bar Bar) float64 {
+	trueRange := bar.High - bar.Low
+	if a.count > 0 {
+		trueRange = math.Max(trueRange, math.Max(math.Abs(bar.High-a.prevClose), math.Abs(bar.Low-a.prevClose)))
+	}
+	a.prevClose = bar.Close
+
+	if a.count < a.period {
+		a.count++
+		a.value += (trueRange - a.value) / float64(a.count)
+	} else {
+		n := float64(a.period)
+		a.value = (a.value*(n-1) + trueRange) / n
+	}
+	return a.value
+}
+
+func (a *ATR) Value() float64 {
+	return a.value
+}
+
+func (a *ATR) Ready() bool {
+	return a.count >= a.period
+}
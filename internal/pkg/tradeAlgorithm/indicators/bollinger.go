@@ -0,0 +1,56 @@
+package indicators
+
+import "math"
+
+type BollingerValue struct {
+	Upper  float64
+	Middle float64
+	Lower  float64
+}
+
+// BollingerBands are SMA and bands at multiplier population standard deviations from it
+type BollingerBands struct {
+	sma        *SMA
+	window     []float64
+	next       int
+	sumSquares float64
+	multiplier float64
+	value      BollingerValue
+}
+
+func NewBollingerBands(period int, multiplier float64) (*BollingerBands, error) {
+	sma, err := NewSMA(period)
+	if err != nil {
+		return nil, err
+	}
+	return &BollingerBands{
+		sma:        sma,
+		window:     make([]float64, period),
+		multiplier: multiplier,
+	}, nil
+}
+
+func (b *BollingerBands) Update(value float64) BollingerValue {
+	if b.sma.Ready() {
+		old := b.window[b.next]
+		b.sumSquares -= old * old
+	}
+	b.window[b.next] = value
+	b.next = (b.next + 1) % len(b.window)
+	b.sumSquares += value * value
+
+	mean := b.sma.Update(value)
+	variance := b.sumSquares/float64(b.sma.count) - mean*mean
+	deviation := math.Sqrt(math.Max(variance, 0)) * b.multiplier
+
+	b.value = BollingerValue{Upper: mean + deviation, Middle: mean, Lower: mean - deviation}
+	return b.value
+}
+
+func (b *BollingerBands) Value() BollingerValue {
+	return b.value
+}
+
+func (b *BollingerBands) Ready() bool {
+	return b.sma.Ready()
+}
@@ -0,0 +1,34 @@
+package indicators
+
+// EMA is exponential moving average seeded with simple average of the first period values
+type EMA struct {
+	period int
+	alpha  float64
+	count  int
+	value  float64
+}
+
+func NewEMA(period int) (*EMA, error) {
+	if err := checkPeriod(period); err != nil {
+		return nil, err
+	}
+	return &EMA{period: period, alpha: 2 / float64(period+1)}, nil
+}
+
+func (e *EMA) Update(value float64) float64 {
+	if e.count < e.period {
+		e.count++
+		e.value += (value - e.value) / float64(e.count)
+	} else {
+		e.value += e.alpha * (value - e.value)
+	}
+	return e.value
+}
+
+func (e *EMA) Value() float64 {
+	return e.value
+}
+
+func (e *EMA) Ready() bool {
+	return e.count >= e.period
+}
@@ -0,0 +1,63 @@
+// Package indicators contains streaming technical indicators. Every indicator is updated
+// with one value or candle at a time in O(1) and keeps only state it needs, so it can be fed
+// directly from the candles channel of web.KrakenAnalyzer:
+//
+//	rsi, err := indicators.NewRSI(14)
+//	...
+//	for candle := range candles {
+//		bar := indicators.NewBar(candle)
+//		if value := rsi.Update(bar.Close); rsi.Ready() && value > 70 {
+//			...
+//		}
+//	}
+//
+// Indicators are defined on closed candles. Candle of period which is not over yet changes with every trade,
+// so it must not be fed until it is closed, otherwise indicator is updated with opening tick of the period
+// instead of its bar
+package indicators
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"trade-bot/pkg/krakenFuturesWSSDK"
+)
+
+var ErrInvalidPeriod = errors.New("period of indicator must be positive")
+
+// Bar is a closed candle with float prices. Candles have exact decimal prices like everywhere else, indicators
+// don't need exact prices and are computed much faster on floats
+type Bar struct {
+	Time   time.Time
+	Open   float64
+	High   float64
+	Low    float64
+	Close  float64
+	Volume float64
+}
+
+// TypicalPrice is the average of high, low and close
+func (b Bar) TypicalPrice() float64 {
+	return (b.High + b.Low + b.Close) / 3
+}
+
+// NewBar converts prices of candle to floats
+func NewBar(candle krakenFuturesWSSDK.Candle) Bar {
+	return Bar{
+		Time:   time.Unix(int64(candle.Time), 0),
+		Open:   candle.Open.InexactFloat64(),
+		High:   candle.High.InexactFloat64(),
+		Low:    candle.Low.InexactFloat64(),
+		Close:  candle.Close.InexactFloat64(),
+		Volume: candle.Volume.InexactFloat64(),
+	}
+}
+
+func checkPeriod(period int) error {
+	if period < 1 {
+		return fmt.Errorf("%w: %d", ErrInvalidPeriod, period)
+	}
+	return nil
+}
@@ -0,0 +1,302 @@
+package indicators
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/pkg/krakenFuturesWSSDK"
+)
+
+// closes are taken from Wilder's RSI example published by StockCharts
+var closes = []float64{44.34, 44.09, 44.15, 43.61, 44.33, 44.83, 45.10, 45.42, 45.84, 46.08, 45.89, 46.03, 45.61,
+	46.28, 46.28, 46.00, 46.03, 46.41, 46.22, 45.64}
+
+var bars = []Bar{
+	{High: 48.70, Low: 47.79, Close: 48.16, Volume: 100},
+	{High: 48.72, Low: 48.14, Close: 48.61, Volume: 200},
+	{High: 48.90, Low: 48.39, Close: 48.75, Volume: 150},
+	{High: 48.87, Low: 48.37, Close: 48.63, Volume: 120},
+	{High: 48.82, Low: 48.24, Close: 48.74, Volume: 300},
+	{High: 49.05, Low: 48.64, Close: 49.03, Volume: 250},
+	{High: 49.20, Low: 48.94, Close: 49.07, Volume: 80},
+	{High: 49.35, Low: 48.86, Close: 49.32, Volume: 90},
+	{High: 49.92, Low: 49.50, Close: 49.91, Volume: 400},
+	{High: 50.19, Low: 49.87, Close: 50.13, Volume: 310},
+}
+
+const delta = 1e-9
+
+func TestNewBar(t *testing.T) {
+	candle := krakenFuturesWSSDK.Candle{Time: 1650000000, Open: decimal.RequireFromString("1"), High: decimal.RequireFromString("2.5"),
+		Low: decimal.RequireFromString("0.5"), Close: decimal.RequireFromString("1.5"), Volume: decimal.NewFromInt(10)}
+	assert.Equal(t, Bar{Time: time.Unix(1650000000, 0), Open: 1, High: 2.5, Low: 0.5, Close: 1.5, Volume: 10}, NewBar(candle))
+}
+
+func TestPriceIndicators(t *testing.T) {
+	tests := []struct {
+		name      string
+		indicator func(t *testing.T) (update func(float64) float64, ready func() bool)
+		values    []float64
+		want      float64
+		wantReady bool
+	}{
+		{
+			name: "SMA",
+			indicator: func(t *testing.T) (func(float64) float64, func() bool) {
+				sma, err := NewSMA(5)
+				assert.NoError(t, err)
+				return sma.Update, sma.Ready
+			},
+			values:    closes,
+			want:      46.06,
+			wantReady: true,
+		},
+		{
+			name: "SMA warming up",
+			indicator: func(t *testing.T) (func(float64) float64, func() bool) {
+				sma, err := NewSMA(5)
+				assert.NoError(t, err)
+				return sma.Update, sma.Ready
+			},
+			values: []float64{1, 2, 3},
+			want:   2,
+		},
+		{
+			name: "EMA",
+			indicator: func(t *testing.T) (func(float64) float64, func() bool) {
+				ema, err := NewEMA(5)
+				assert.NoError(t, err)
+				return ema.Update, ema.Ready
+			},
+			values:    closes,
+			want:      45.996053619415065,
+			wantReady: true,
+		},
+		{
+			name: "EMA 10",
+			indicator: func(t *testing.T) (func(float64) float64, func() bool) {
+				ema, err := NewEMA(10)
+				assert.NoError(t, err)
+				return ema.Update, ema.Ready
+			},
+			values:    closes,
+			want:      45.87036561912813,
+			wantReady: true,
+		},
+		{
+			name: "RSI first value",
+			indicator: func(t *testing.T) (func(float64) float64, func() bool) {
+				rsi, err := NewRSI(14)
+				assert.NoError(t, err)
+				return rsi.Update, rsi.Ready
+			},
+			values:    closes[:15],
+			want:      70.46413502109705,
+			wantReady: true,
+		},
+		{
+			name: "RSI",
+			indicator: func(t *testing.T) (func(float64) float64, func() bool) {
+				rsi, err := NewRSI(14)
+				assert.NoError(t, err)
+				return rsi.Update, rsi.Ready
+			},
+			values:    closes,
+			want:      57.91502067008556,
+			wantReady: true,
+		},
+		{
+			name: "RSI not ready",
+			indicator: func(t *testing.T) (func(float64) float64, func() bool) {
+				rsi, err := NewRSI(14)
+				assert.NoError(t, err)
+				return rsi.Update, rsi.Ready
+			},
+			values: closes[:14],
+			want:   averagedRSI(closes[:14]),
+		},
+		{
+			name: "RSI without changes",
+			indicator: func(t *testing.T) (func(float64) float64, func() bool) {
+				rsi, err := NewRSI(2)
+				assert.NoError(t, err)
+				return rsi.Update, rsi.Ready
+			},
+			values:    []float64{10, 10, 10},
+			want:      50,
+			wantReady: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			update, ready := test.indicator(t)
+
+			var got float64
+			for _, value := range test.values {
+				got = update(value)
+			}
+			assert.InDelta(t, test.want, got, delta)
+			assert.Equal(t, test.wantReady, ready())
+		})
+	}
+}
+
+// averagedRSI returns RSI of values where all changes are averaged, it is used for not ready RSI
+func averagedRSI(values []float64) float64 {
+	var gain, loss float64
+	for i := 1; i < len(values); i++ {
+		if change := values[i] - values[i-1]; change > 0 {
+			gain += change
+		} else {
+			loss -= change
+		}
+	}
+	return 100 - 100/(1+gain/loss)
+}
+
+func TestMACD(t *testing.T) {
+	macd, err := NewMACD(3, 6, 4)
+	assert.NoError(t, err)
+
+	var got MACDValue
+	for i, value := range closes {
+		got = macd.Update(value)
+		// slow EMA is ready on 6th value and signal needs 4 more
+		assert.Equal(t, i >= 8, macd.Ready())
+	}
+
+	assert.InDelta(t, -0.06354852124444932, got.MACD, delta)
+	assert.InDelta(t, 0.04170427796485986, got.Signal, delta)
+	assert.InDelta(t, -0.10525279920930918, got.Histogram, delta)
+}
+
+func TestBollingerBands(t *testing.T) {
+	bollinger, err := NewBollingerBands(5, 2)
+	assert.NoError(t, err)
+
+	var got BollingerValue
+	for _, value := range closes {
+		got = bollinger.Update(value)
+	}
+
+	assert.True(t, bollinger.Ready())
+	assert.InDelta(t, 46.573030213535226, got.Upper, 1e-6)
+	assert.InDelta(t, 46.06, got.Middle, delta)
+	assert.InDelta(t, 45.54696978646478, got.Lower, 1e-6)
+}
+
+func TestBarIndicators(t *testing.T) {
+	tests := []struct {
+		name      string
+		indicator func(t *testing.T) (update func(Bar) float64, ready func() bool)
+		bars      []Bar
+		want      float64
+		wantReady bool
+	}{
+		{
+			name: "ATR",
+			indicator: func(t *testing.T) (func(Bar) float64, func() bool) {
+				atr, err := NewATR(3)
+				assert.NoError(t, err)
+				return atr.Update, atr.Ready
+			},
+			bars:      bars,
+			want:      0.44437738149672396,
+			wantReady: true,
+		},
+		{
+			name: "ATR warming up",
+			indicator: func(t *testing.T) (func(Bar) float64, func() bool) {
+				atr, err := NewATR(3)
+				assert.NoError(t, err)
+				return atr.Update, atr.Ready
+			},
+			bars: bars[:2],
+			// true ranges are 0.91 and 0.58
+			want: 0.745,
+		},
+		{
+			name: "VWAP",
+			indicator: func(t *testing.T) (func(Bar) float64, func() bool) {
+				vwap := NewVWAP()
+				return vwap.Update, vwap.Ready
+			},
+			bars:      bars,
+			want:      49.12246666666666,
+			wantReady: true,
+		},
+		{
+			name: "VWAP without volume",
+			indicator: func(t *testing.T) (func(Bar) float64, func() bool) {
+				vwap := NewVWAP()
+				return vwap.Update, vwap.Ready
+			},
+			bars: []Bar{{High: 2, Low: 1, Close: 1.5}},
+			want: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			update, ready := test.indicator(t)
+
+			var got float64
+			for _, bar := range test.bars {
+				got = update(bar)
+			}
+			assert.InDelta(t, test.want, got, delta)
+			assert.Equal(t, test.wantReady, ready())
+		})
+	}
+}
+
+func TestVWAP_Reset(t *testing.T) {
+	vwap := NewVWAP()
+	vwap.Update(Bar{High: 10, Low: 10, Close: 10, Volume: 1})
+	vwap.Reset()
+
+	assert.False(t, vwap.Ready())
+	assert.InDelta(t, 20, vwap.Update(Bar{High: 20, Low: 20, Close: 20, Volume: 5}), delta)
+}
+
+func TestStochastic(t *testing.T) {
+	stochastic, err := NewStochastic(5, 3)
+	assert.NoError(t, err)
+
+	var got StochasticValue
+	for i, bar := range bars {
+		got = stochastic.Update(bar)
+		// %K is ready on 5th bar and %D needs 2 more
+		assert.Equal(t, i >= 6, stochastic.Ready())
+	}
+
+	assert.InDelta(t, 96.12903225806481, got.K, 1e-6)
+	assert.InDelta(t, 97.6103638200412, got.D, 1e-6)
+}
+
+func TestStochastic_FlatRange(t *testing.T) {
+	stochastic, err := NewStochastic(2, 1)
+	assert.NoError(t, err)
+	stochastic.Update(Bar{High: 10, Low: 10, Close: 10})
+
+	assert.Equal(t, StochasticValue{K: 50, D: 50}, stochastic.Update(Bar{High: 10, Low: 10, Close: 10}))
+}
+
+func TestInvalidPeriod(t *testing.T) {
+	_, err := NewSMA(0)
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+	_, err = NewEMA(-1)
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+	_, err = NewStochastic(0, 3)
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+	_, err = NewStochastic(5, 0)
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+	_, err = NewMACD(3, 6, 0)
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+	_, err = NewBollingerBands(0, 2)
+	assert.ErrorIs(t, err, ErrInvalidPeriod)
+}
@@ -0,0 +1,52 @@
+package indicators
+
+type MACDValue struct {
+	MACD      float64
+	Signal    float64
+	Histogram float64
+}
+
+// MACD is moving average convergence divergence: difference of fast and slow EMA and EMA of that difference
+type MACD struct {
+	fast   *EMA
+	slow   *EMA
+	signal *EMA
+	value  MACDValue
+}
+
+func NewMACD(fastPeriod, slowPeriod, signalPeriod int) (*MACD, error) {
+	fast, err := NewEMA(fastPeriod)
+	if err != nil {
+		return nil, err
+	}
+	slow, err := NewEMA(slowPeriod)
+	if err != nil {
+		return nil, err
+	}
+	signal, err := NewEMA(signalPeriod)
+	if err != nil {
+		return nil, err
+	}
+	return &MACD{fast: fast, slow: slow, signal: signal}, nil
+}
+
+func (m *MACD) Update(value float64) MACDValue {
+	fast := m.fast.Update(value)
+	slow := m.slow.Update(value)
+	if !m.fast.Ready() || !m.slow.Ready() {
+		return m.value
+	}
+
+	m.value.MACD = fast - slow
+	m.value.Signal = m.signal.Update(m.value.MACD)
+	m.value.Histogram = m.value.MACD - m.value.Signal
+	return m.value
+}
+
+func (m *MACD) Value() MACDValue {
+	return m.value
+}
+
+func (m *MACD) Ready() bool {
+	return m.signal.Ready()
+}
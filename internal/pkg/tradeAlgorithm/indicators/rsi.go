@@ -0,0 +1,62 @@
+package indicators
+
+// RSI is Wilder's relative strength index, it is ready after period price changes
+type RSI struct {
+	period  int
+	count   int
+	prev    float64
+	hasPrev bool
+	avgGain float64
+	avgLoss float64
+}
+
+func NewRSI(period int) (*RSI, error) {
+	if err := checkPeriod(period); err != nil {
+		return nil, err
+	}
+	return &RSI{period: period}, nil
+}
+
+func (r *RSI) Update(value float64) float64 {
+	if !r.hasPrev {
+		r.prev, r.hasPrev = value, true
+		return r.Value()
+	}
+
+	change := value - r.prev
+	r.prev = value
+
+	var gain, loss float64
+	if change > 0 {
+		gain = change
+	} else {
+		loss = -change
+	}
+
+	if r.count < r.period {
+		r.count++
+		r.avgGain += (gain - r.avgGain) / float64(r.count)
+		r.avgLoss += (loss - r.avgLoss) / float64(r.count)
+	} else {
+		n := float64(r.period)
+		r.avgGain = (r.avgGain*(n-1) + gain) / n
+		r.avgLoss = (r.avgLoss*(n-1) + loss) / n
+	}
+
+	return r.Value()
+}
+
+// Value is in range [0, 100], it is 50 when price didn't change at all
+func (r *RSI) Value() float64 {
+	if r.avgLoss == 0 {
+		if r.avgGain == 0 {
+			return 50
+		}
+		return 100
+	}
+	return 100 - 100/(1+r.avgGain/r.avgLoss)
+}
+
+func (r *RSI) Ready() bool {
+	return r.count >= r.period
+}
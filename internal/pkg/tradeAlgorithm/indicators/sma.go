@@ -0,0 +1,42 @@
+package indicators
+
+// SMA is simple moving average. Until period values are seen it is the average of all seen values
+type SMA struct {
+	period int
+	window []float64
+	next   int
+	count  int
+	sum    float64
+}
+
+func NewSMA(period int) (*SMA, error) {
+	if err := checkPeriod(period); err != nil {
+		return nil, err
+	}
+	return &SMA{period: period, window: make([]float64, period)}, nil
+}
+
+func (s *SMA) Update(value float64) float64 {
+	if s.count == s.period {
+		s.sum -= s.window[s.next]
+	} else {
+		s.count++
+	}
+
+	s.window[s.next] = value
+	s.next = (s.next + 1) % s.period
+	s.sum += value
+
+	return s.Value()
+}
+
+func (s *SMA) Value() float64 {
+	if s.count == 0 {
+		return 0
+	}
+	return s.sum / float64(s.count)
+}
+
+func (s *SMA) Ready() bool {
+	return s.count == s.period
+}
@@ -0,0 +1,75 @@
+package indicators
+
+type StochasticValue struct {
+	K float64
+	D float64
+}
+
+type windowItem struct {
+	index int
+	value float64
+}
+
+// Stochastic is stochastic oscillator: %K is position of close within high-low range of kPeriod bars,
+// %D is SMA of %K over dPeriod. Highest high and lowest low are tracked with monotonic queues
+type Stochastic struct {
+	kPeriod int
+	index   int
+	highs   []windowItem
+	lows    []windowItem
+	d       *SMA
+	value   StochasticValue
+}
+
+func NewStochastic(kPeriod, dPeriod int) (*Stochastic, error) {
+	if err := checkPeriod(kPeriod); err != nil {
+		return nil, err
+	}
+	d, err := NewSMA(dPeriod)
+	if err != nil {
+		return nil, err
+	}
+	return &Stochastic{kPeriod: kPeriod, d: d}, nil
+}
+
+func (s *Stochastic) Update(bar Bar) StochasticValue {
+	s.highs = pushWindow(s.highs, windowItem{index: s.index, value: bar.High}, s.index-s.kPeriod,
+		func(last, new float64) bool { return last <= new })
+	s.lows = pushWindow(s.lows, windowItem{index: s.index, value: bar.Low}, s.index-s.kPeriod,
+		func(last, new float64) bool { return last >= new })
+	s.index++
+
+	if s.index < s.kPeriod {
+		return s.value
+	}
+
+	highest, lowest := s.highs[0].value, s.lows[0].value
+	s.value.K = 50
+	if highest > lowest {
+		s.value.K = (bar.Close - lowest) / (highest - lowest) * 100
+	}
+	s.value.D = s.d.Update(s.value.K)
+
+	return s.value
+}
+
+// pushWindow appends item to monotonic queue removing items which are dominated by it
+// or went out of window, so the front of queue is always extremum of the window
+func pushWindow(queue []windowItem, item windowItem, expiredIndex int, dominated func(last, new float64) bool) []windowItem {
+	for len(queue) > 0 && dominated(queue[len(queue)-1].value, item.value) {
+		queue = queue[:len(queue)-1]
+	}
+	queue = append(queue, item)
+	for queue[0].index <= expiredIndex {
+		queue = queue[1:]
+	}
+	return queue
+}
+
+func (s *Stochastic) Value() StochasticValue {
+	return s.value
+}
+
+func (s *Stochastic) Ready() bool {
+	return s.d.Ready()
+}
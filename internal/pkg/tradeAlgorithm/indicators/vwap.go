@@ -0,0 +1,35 @@
+package indicators
+
+// VWAP is volume weighted average of typical price since creation or the last Reset
+type VWAP struct {
+	priceVolume float64
+	volume      float64
+	value       float64
+}
+
+func NewVWAP() *VWAP {
+	return &VWAP{}
+}
+
+func (v *VWAP) Update(bar Bar) float64 {
+	v.priceVolume += bar.TypicalPrice() * bar.Volume
+	v.volume += bar.Volume
+
+	if v.volume > 0 {
+		v.value = v.priceVolume / v.volume
+	}
+	return v.value
+}
+
+// Reset starts new averaging session, e.g. at the start of a trading day
+func (v *VWAP) Reset() {
+	*v = VWAP{}
+}
+
+func (v *VWAP) Value() float64 {
+	return v.value
+}
+
+func (v *VWAP) Ready() bool {
+	return v.volume > 0
+}
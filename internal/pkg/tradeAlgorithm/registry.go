@@ -0,0 +1,138 @@
+package tradeAlgorithm
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"trade-bot/internal/pkg/tradeAlgorithm/types"
+	"trade-bot/internal/pkg/web"
+)
+
+var (
+	ErrUnknownStrategy          = errors.New("unknown strategy")
+	ErrStrategyAlreadyRegistred = errors.New("strategy already registered")
+	ErrNewTrader                = errors.New("new trader")
+	ErrNewEntrySignal           = errors.New("new entry signal")
+)
+
+// StrategyFactory creates Trader from params which have already been validated against strategy schema
+type StrategyFactory func(analyzer web.KrakenAnalyzer, params types.StrategyParams) (Trader, error)
+
+type Strategy struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Params      types.ParamsSchema `json:"params"`
+	New         StrategyFactory    `json:"-"`
+}
+
+// EntrySignalFactory creates EntrySignal from params which have already been validated against strategy schema
+type EntrySignalFactory func(analyzer web.KrakenAnalyzer, params types.StrategyParams) (EntrySignal, error)
+
+// EntryStrategy decides when position should be opened, position is managed by Strategy after that
+type EntryStrategy struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Params      types.ParamsSchema `json:"params"`
+	New         EntrySignalFactory `json:"-"`
+}
+
+// Registry keeps named strategies which can be selected to trade with
+type Registry struct {
+	analyzer        web.KrakenAnalyzer
+	strategies      map[string]Strategy
+	entryStrategies map[string]EntryStrategy
+}
+
+func NewRegistry(analyzer web.KrakenAnalyzer) *Registry {
+	return &Registry{
+		analyzer:        analyzer,
+		strategies:      make(map[string]Strategy),
+		entryStrategies: make(map[string]EntryStrategy),
+	}
+}
+
+func (r *Registry) Register(strategy Strategy) error {
+	if _, ok := r.strategies[strategy.Name]; ok {
+		return fmt.Errorf("%s: %s", ErrStrategyAlreadyRegistred, strategy.Name)
+	}
+	r.strategies[strategy.Name] = strategy
+	return nil
+}
+
+func (r *Registry) Strategies() []Strategy {
+	strategies := make([]Strategy, 0, len(r.strategies))
+	for _, strategy := range r.strategies {
+		strategies = append(strategies, strategy)
+	}
+	sort.Slice(strategies, func(i, j int) bool {
+		return strategies[i].Name < strategies[j].Name
+	})
+	return strategies
+}
+
+// Validate checks params against schema of the strategy and strategy's own validation
+func (r *Registry) Validate(name string, params types.StrategyParams) error {
+	_, err := r.NewTrader(name, params)
+	return err
+}
+
+func (r *Registry) NewTrader(name string, params types.StrategyParams) (Trader, error) {
+	strategy, ok := r.strategies[name]
+	if !ok {
+		return nil, fmt.Errorf("%s: %s", ErrUnknownStrategy, name)
+	}
+
+	if err := strategy.Params.Validate(params); err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrNewTrader, err)
+	}
+
+	trader, err := strategy.New(r.analyzer, strategy.Params.WithDefaults(params))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrNewTrader, err)
+	}
+	return trader, nil
+}
+
+func (r *Registry) RegisterEntry(strategy EntryStrategy) error {
+	if _, ok := r.entryStrategies[strategy.Name]; ok {
+		return fmt.Errorf("%s: %s", ErrStrategyAlreadyRegistred, strategy.Name)
+	}
+	r.entryStrategies[strategy.Name] = strategy
+	return nil
+}
+
+func (r *Registry) EntryStrategies() []EntryStrategy {
+	strategies := make([]EntryStrategy, 0, len(r.entryStrategies))
+	for _, strategy := range r.entryStrategies {
+		strategies = append(strategies, strategy)
+	}
+	sort.Slice(strategies, func(i, j int) bool {
+		return strategies[i].Name < strategies[j].Name
+	})
+	return strategies
+}
+
+// ValidateEntry checks params against schema of the entry strategy and strategy's own validation
+func (r *Registry) ValidateEntry(name string, params types.StrategyParams) error {
+	_, err := r.NewEntrySignal(name, params)
+	return err
+}
+
+func (r *Registry) NewEntrySignal(name string, params types.StrategyParams) (EntrySignal, error) {
+	strategy, ok := r.entryStrategies[name]
+	if !ok {
+		return nil, fmt.Errorf("%s: %s", ErrUnknownStrategy, name)
+	}
+
+	if err := strategy.Params.Validate(params); err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrNewEntrySignal, err)
+	}
+
+	signal, err := strategy.New(r.analyzer, strategy.Params.WithDefaults(params))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrNewEntrySignal, err)
+	}
+	return signal, nil
+}
@@ -9,13 +9,90 @@ import (
 )
 
 type Trader interface {
-	StartAnalyzing(ctx context.Context, buyTime time.Time, details types.TradingDetails) error
+	// StartAnalyzing blocks until position opened at details.BuyPrice should be closed and returns the reason
+	StartAnalyzing(ctx context.Context, buyTime time.Time, details types.TradingDetails) (types.ExitReason, error)
+}
+
+// EntrySignal waits for the moment to open position
+type EntrySignal interface {
+	// WaitForEntry blocks until strategy signals to open position of details.Side
+	WaitForEntry(ctx context.Context, details types.TradingDetails) error
+}
+
+type Strategies interface {
+	Strategies() []Strategy
+	EntryStrategies() []EntryStrategy
+	Validate(name string, params types.StrategyParams) error
+	ValidateEntry(name string, params types.StrategyParams) error
+	NewTrader(name string, params types.StrategyParams) (Trader, error)
+	NewEntrySignal(name string, params types.StrategyParams) (EntrySignal, error)
 }
 
 type TradeAlgorithm struct {
-	Trader
+	Strategies
 }
 
 func NewTradeAlgorithm(w *web.Web) *TradeAlgorithm {
-	return &TradeAlgorithm{Trader: algorithms.NewStopLossTakeProfitAlgo(w.KrakenAnalyzer)}
+	return &TradeAlgorithm{Strategies: NewDefaultRegistry(w.KrakenAnalyzer)}
+}
+
+// NewDefaultRegistry returns registry with all strategies supported by trade-bot
+func NewDefaultRegistry(analyzer web.KrakenAnalyzer) *Registry {
+	registry := NewRegistry(analyzer)
+
+	for _, strategy := range []Strategy{
+		{
+			Name:        algorithms.StopLossTakeProfitName,
+			Description: "closes position when price moves away from entry price by one of the borders",
+			Params:      algorithms.StopLossTakeProfitSchema,
+			New: func(analyzer web.KrakenAnalyzer, params types.StrategyParams) (Trader, error) {
+				return algorithms.NewStopLossTakeProfitAlgo(analyzer, params), nil
+			},
+		},
+		{
+			Name:        algorithms.TrailingStopName,
+			Description: "closes position when price retraces from the best price since entry by trail distance",
+			Params:      algorithms.TrailingStopSchema,
+			New: func(analyzer web.KrakenAnalyzer, params types.StrategyParams) (Trader, error) {
+				return algorithms.NewTrailingStopAlgo(analyzer, params)
+			},
+		},
+	} {
+		if err := registry.Register(strategy); err != nil {
+			panic(err)
+		}
+	}
+
+	for _, strategy := range []EntryStrategy{
+		{
+			Name:        algorithms.EMACrossoverName,
+			Description: "opens position when fast EMA crosses slow EMA in the direction of session side",
+			Params:      algorithms.EMACrossoverSchema,
+			New: func(analyzer web.KrakenAnalyzer, params types.StrategyParams) (EntrySignal, error) {
+				return algorithms.NewEMACrossoverEntry(analyzer, params)
+			},
+		},
+		{
+			Name:        algorithms.RSIName,
+			Description: "opens long position when RSI is oversold and short position when it is overbought",
+			Params:      algorithms.RSISchema,
+			New: func(analyzer web.KrakenAnalyzer, params types.StrategyParams) (EntrySignal, error) {
+				return algorithms.NewRSIEntry(analyzer, params)
+			},
+		},
+		{
+			Name:        algorithms.BollingerBreakoutName,
+			Description: "opens position when price breaks out of bollinger bands in the direction of session side",
+			Params:      algorithms.BollingerBreakoutSchema,
+			New: func(analyzer web.KrakenAnalyzer, params types.StrategyParams) (EntrySignal, error) {
+				return algorithms.NewBollingerBreakoutEntry(analyzer, params)
+			},
+		},
+	} {
+		if err := registry.RegisterEntry(strategy); err != nil {
+			panic(err)
+		}
+	}
+
+	return registry
 }
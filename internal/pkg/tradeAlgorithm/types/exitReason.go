@@ -0,0 +1,13 @@
+package types
+
+// ExitReason tells why trader decided to close position
+type ExitReason string
+
+const (
+	ExitReasonStopLoss     ExitReason = "stop_loss"
+	ExitReasonTakeProfit   ExitReason = "take_profit"
+	ExitReasonTrailingStop ExitReason = "trailing_stop"
+	ExitReasonCancelled    ExitReason = "cancelled"
+	// ExitReasonFailed is used when position is closed because trader failed
+	ExitReasonFailed ExitReason = "failed"
+)
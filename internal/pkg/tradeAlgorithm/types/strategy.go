@@ -0,0 +1,183 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrInvalidParams       = errors.New("invalid strategy params")
+	ErrUnknownParam        = errors.New("unknown param")
+	ErrMissingParam        = errors.New("missing required param")
+	ErrInvalidParamType    = errors.New("invalid param type")
+	ErrParamOutOfRange     = errors.New("param out of range")
+	ErrParamNotInEnum      = errors.New("param is not one of allowed values")
+	ErrUnsupportedDBParams = errors.New("unsupported db value for strategy params")
+)
+
+type ParamType string
+
+const (
+	NumberParam  ParamType = "number"
+	IntegerParam ParamType = "integer"
+	StringParam  ParamType = "string"
+	BoolParam    ParamType = "bool"
+)
+
+// ParamSpec describes single parameter of a strategy
+type ParamSpec struct {
+	Name        string      `json:"name"`
+	Type        ParamType   `json:"type"`
+	Required    bool        `json:"required"`
+	Description string      `json:"description,omitempty"`
+	Min         *float64    `json:"min,omitempty"`
+	Max         *float64    `json:"max,omitempty"`
+	Enum        []string    `json:"enum,omitempty"`
+	Default     interface{} `json:"default,omitempty"`
+}
+
+// ParamsSchema is declared by every strategy and used to validate params before trading is started
+type ParamsSchema []ParamSpec
+
+// StrategyParams are free-form strategy params as they come from json
+type StrategyParams map[string]interface{}
+
+// Float returns pointer to v, used to declare Min and Max of ParamSpec
+func Float(v float64) *float64 {
+	return &v
+}
+
+// Validate checks that params contain only declared params of valid type and range
+func (s ParamsSchema) Validate(params StrategyParams) error {
+	specs := make(map[string]ParamSpec, len(s))
+	for _, spec := range s {
+		specs[spec.Name] = spec
+	}
+
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		spec, ok := specs[name]
+		if !ok {
+			return fmt.Errorf("%s: %s: %s", ErrInvalidParams, ErrUnknownParam, name)
+		}
+		if err := spec.validate(params[name]); err != nil {
+			return fmt.Errorf("%s: %s: %w", ErrInvalidParams, name, err)
+		}
+	}
+
+	for _, spec := range s {
+		if _, ok := params[spec.Name]; spec.Required && !ok {
+			return fmt.Errorf("%s: %s: %s", ErrInvalidParams, ErrMissingParam, spec.Name)
+		}
+	}
+
+	return nil
+}
+
+// WithDefaults returns copy of params with defaults of not passed params
+func (s ParamsSchema) WithDefaults(params StrategyParams) StrategyParams {
+	result := make(StrategyParams, len(params))
+	for name, value := range params {
+		result[name] = value
+	}
+	for _, spec := range s {
+		if _, ok := result[spec.Name]; !ok && spec.Default != nil {
+			result[spec.Name] = spec.Default
+		}
+	}
+	return result
+}
+
+func (p ParamSpec) validate(value interface{}) error {
+	switch p.Type {
+	case NumberParam, IntegerParam:
+		number, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("%s: expected %s", ErrInvalidParamType, p.Type)
+		}
+		if p.Type == IntegerParam && number != math.Trunc(number) {
+			return fmt.Errorf("%s: expected %s", ErrInvalidParamType, p.Type)
+		}
+		if p.Min != nil && number < *p.Min {
+			return fmt.Errorf("%s: less than %v", ErrParamOutOfRange, *p.Min)
+		}
+		if p.Max != nil && number > *p.Max {
+			return fmt.Errorf("%s: greater than %v", ErrParamOutOfRange, *p.Max)
+		}
+	case StringParam:
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s: expected %s", ErrInvalidParamType, p.Type)
+		}
+		if len(p.Enum) == 0 {
+			return nil
+		}
+		for _, allowed := range p.Enum {
+			if str == allowed {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s: %s", ErrParamNotInEnum, strings.Join(p.Enum, ", "))
+	case BoolParam:
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s: expected %s", ErrInvalidParamType, p.Type)
+		}
+	}
+	return nil
+}
+
+func (p StrategyParams) Float(name string) float64 {
+	value, _ := p[name].(float64)
+	return value
+}
+
+func (p StrategyParams) Int(name string) int {
+	value, _ := p[name].(float64)
+	return int(value)
+}
+
+func (p StrategyParams) String(name string) string {
+	value, _ := p[name].(string)
+	return value
+}
+
+func (p StrategyParams) Bool(name string) bool {
+	value, _ := p[name].(bool)
+	return value
+}
+
+// Value makes StrategyParams storable as json in database
+func (p StrategyParams) Value() (driver.Value, error) {
+	if p == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(p)
+}
+
+// Scan makes StrategyParams readable from json column of database
+func (p *StrategyParams) Scan(src interface{}) error {
+	var data []byte
+	switch value := src.(type) {
+	case nil:
+		*p = StrategyParams{}
+		return nil
+	case []byte:
+		data = value
+	case string:
+		data = []byte(value)
+	default:
+		return fmt.Errorf("%s: %T", ErrUnsupportedDBParams, src)
+	}
+	return json.Unmarshal(data, p)
+}
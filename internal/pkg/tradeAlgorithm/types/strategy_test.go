@@ -0,0 +1,57 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParamsSchema_Validate(t *testing.T) {
+	schema := ParamsSchema{
+		{Name: "border", Type: NumberParam, Required: true, Min: Float(0)},
+		{Name: "period", Type: IntegerParam, Min: Float(1), Max: Float(100)},
+		{Name: "mode", Type: StringParam, Enum: []string{"absolute", "percent"}},
+		{Name: "enabled", Type: BoolParam},
+	}
+
+	tests := []struct {
+		name    string
+		params  StrategyParams
+		wantErr bool
+	}{
+		{name: "OK", params: StrategyParams{"border": 10.5, "period": float64(14), "mode": "percent", "enabled": true}},
+		{name: "Only required", params: StrategyParams{"border": float64(0)}},
+		{name: "Missing required", params: StrategyParams{"period": float64(14)}, wantErr: true},
+		{name: "Unknown param", params: StrategyParams{"border": float64(1), "unknown": float64(1)}, wantErr: true},
+		{name: "Invalid number type", params: StrategyParams{"border": "10"}, wantErr: true},
+		{name: "Number less than min", params: StrategyParams{"border": float64(-1)}, wantErr: true},
+		{name: "Not integer", params: StrategyParams{"border": float64(1), "period": 1.5}, wantErr: true},
+		{name: "Integer greater than max", params: StrategyParams{"border": float64(1), "period": float64(101)}, wantErr: true},
+		{name: "Not in enum", params: StrategyParams{"border": float64(1), "mode": "ticks"}, wantErr: true},
+		{name: "Invalid bool type", params: StrategyParams{"border": float64(1), "enabled": "true"}, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := schema.Validate(test.params)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestParamsSchema_WithDefaults(t *testing.T) {
+	schema := ParamsSchema{
+		{Name: "border", Type: NumberParam, Required: true},
+		{Name: "mode", Type: StringParam, Default: "absolute"},
+	}
+
+	params := StrategyParams{"border": float64(1)}
+	got := schema.WithDefaults(params)
+
+	assert.Equal(t, StrategyParams{"border": float64(1), "mode": "absolute"}, got)
+	assert.Equal(t, StrategyParams{"border": float64(1)}, params)
+}
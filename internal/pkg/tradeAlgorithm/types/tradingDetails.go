@@ -1,11 +1,22 @@
 package types
 
+import "github.com/shopspring/decimal"
+
 type TradingDetails struct {
-	OrderType        string  `json:"order_type" validate:"required"`
-	Symbol           string  `json:"symbol" validate:"required"`
-	Side             string  `json:"side" validate:"required"`
-	Size             uint    `json:"size" validate:"required,gte=0"`
-	StopLossBorder   float64 `json:"stop_loss_border" validate:"required,gte=0"`
-	TakeProfitBorder float64 `json:"take_profit_border" validate:"required,gte=0"`
-	BuyPrice         float64
+	OrderType string `json:"order_type" validate:"required"`
+	Symbol    string `json:"symbol" validate:"required"`
+	Side      string `json:"side" validate:"required"`
+	// Size is number of contracts, it may be fractional if instrument allows it
+	Size     decimal.Decimal `json:"size" validate:"required,gt=0" swaggertype:"string"`
+	Strategy string          `json:"strategy" validate:"required"`
+	Params   StrategyParams  `json:"params"`
+	// EntryStrategy makes session wait for its signal before every entry and trade round trips until stopped,
+	// without it session enters at once and finishes after the first exit
+	EntryStrategy string         `json:"entry_strategy,omitempty"`
+	EntryParams   StrategyParams `json:"entry_params,omitempty"`
+	// Paper makes session trade on simulated venue instead of kraken
+	Paper    bool `json:"paper"`
+	BuyPrice decimal.Decimal
+	// TickSize of the instrument, it is filled by caller before analyzing
+	TickSize decimal.Decimal `json:"-"`
 }
@@ -0,0 +1,61 @@
+package web
+
+import (
+	"sync"
+
+	"trade-bot/internal/pkg/web/webKraken"
+	"trade-bot/pkg/krakenFuturesSDK"
+)
+
+type cachedOrdersManager struct {
+	publicAPIKey  string
+	privateAPIKey string
+	manager       KrakenOrdersManager
+}
+
+// KrakenOrdersManagers creates KrakenOrdersManager signed with user's own kraken api keys
+// and caches them by user id
+type KrakenOrdersManagers struct {
+	apiURL string
+
+	mu       sync.RWMutex
+	managers map[int]cachedOrdersManager
+}
+
+func NewKrakenOrdersManagers(apiURL string) *KrakenOrdersManagers {
+	return &KrakenOrdersManagers{
+		apiURL:   apiURL,
+		managers: make(map[int]cachedOrdersManager),
+	}
+}
+
+// OrdersManager returns cached orders manager of user if it was created with the same api keys,
+// otherwise creates new one and caches it
+func (f *KrakenOrdersManagers) OrdersManager(userID int, publicAPIKey, privateAPIKey string) KrakenOrdersManager {
+	f.mu.RLock()
+	cached, ok := f.managers[userID]
+	f.mu.RUnlock()
+
+	if ok && cached.publicAPIKey == publicAPIKey && cached.privateAPIKey == privateAPIKey {
+		return cached.manager
+	}
+
+	manager := webKraken.NewKrakenOrdersManagerWebSDK(krakenFuturesSDK.NewAPI(publicAPIKey, privateAPIKey, f.apiURL))
+
+	f.mu.Lock()
+	f.managers[userID] = cachedOrdersManager{
+		publicAPIKey:  publicAPIKey,
+		privateAPIKey: privateAPIKey,
+		manager:       manager,
+	}
+	f.mu.Unlock()
+
+	return manager
+}
+
+// EvictOrdersManager removes orders manager of user from cache
+func (f *KrakenOrdersManagers) EvictOrdersManager(userID int) {
+	f.mu.Lock()
+	delete(f.managers, userID)
+	f.mu.Unlock()
+}
@@ -0,0 +1,22 @@
+package web
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKrakenOrdersManagers_OrdersManager(t *testing.T) {
+	f := NewKrakenOrdersManagers("")
+
+	manager := f.OrdersManager(1, "public", "private")
+	assert.Same(t, manager, f.OrdersManager(1, "public", "private"))
+
+	// manager signed with old keys is not reused after keys of user are changed
+	rotated := f.OrdersManager(1, "public", "rotated")
+	assert.NotSame(t, manager, rotated)
+	assert.Same(t, rotated, f.OrdersManager(1, "public", "rotated"))
+
+	f.EvictOrdersManager(1)
+	assert.NotSame(t, rotated, f.OrdersManager(1, "public", "rotated"))
+}
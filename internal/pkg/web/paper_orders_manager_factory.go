@@ -0,0 +1,52 @@
+package web
+
+import (
+	"sync"
+
+	"github.com/shopspring/decimal"
+
+	"trade-bot/configs"
+	"trade-bot/internal/pkg/models"
+	"trade-bot/internal/pkg/web/webKraken"
+)
+
+// KrakenPaperOrdersManagers keeps virtual account of every user trading on paper.
+// Accounts live in memory only and start from initial balance after restart
+type KrakenPaperOrdersManagers struct {
+	prices         *webKraken.KrakenPriceTracker
+	initialBalance decimal.Decimal
+	feeRate        decimal.Decimal
+
+	mu       sync.Mutex
+	managers map[int]*webKraken.KrakenPaperOrdersManager
+}
+
+func NewKrakenPaperOrdersManagers(ticker KrakenTicker, config configs.PaperTradingConfiguration) *KrakenPaperOrdersManagers {
+	return &KrakenPaperOrdersManagers{
+		prices:         webKraken.NewKrakenPriceTracker(ticker),
+		initialBalance: decimal.NewFromFloat(config.InitialBalance),
+		feeRate:        decimal.NewFromFloat(config.FeeRate),
+		managers:       make(map[int]*webKraken.KrakenPaperOrdersManager),
+	}
+}
+
+// PaperOrdersManager returns simulated orders manager of user, creating virtual account on first call
+func (f *KrakenPaperOrdersManagers) PaperOrdersManager(userID int) KrakenOrdersManager {
+	return f.paperOrdersManager(userID)
+}
+
+func (f *KrakenPaperOrdersManagers) PaperAccount(userID int) models.PaperAccount {
+	return f.paperOrdersManager(userID).Account()
+}
+
+func (f *KrakenPaperOrdersManagers) paperOrdersManager(userID int) *webKraken.KrakenPaperOrdersManager {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	manager, ok := f.managers[userID]
+	if !ok {
+		manager = webKraken.NewKrakenPaperOrdersManager(userID, f.prices, f.initialBalance, f.feeRate)
+		f.managers[userID] = manager
+	}
+	return manager
+}
@@ -2,7 +2,11 @@ package web
 
 import (
 	"context"
+	"time"
 
+	"github.com/shopspring/decimal"
+
+	"trade-bot/configs"
 	"trade-bot/internal/pkg/models"
 	"trade-bot/internal/pkg/web/webKraken"
 	"trade-bot/pkg/krakenFuturesSDK"
@@ -10,25 +14,71 @@ import (
 )
 
 type KrakenOrdersManager interface {
-	SendOrder(args krakenFuturesSDK.SendOrderArguments) (krakenFuturesSDK.SendStatus, error)
-	EditOrder(args krakenFuturesSDK.EditOrderArguments) (krakenFuturesSDK.EditStatus, error)
-	CancelOrder(args krakenFuturesSDK.CancelOrderArguments) (krakenFuturesSDK.CancelStatus, error)
-	CancelAllOrders(symbol string) (krakenFuturesSDK.CancelAllStatus, error)
+	SendOrder(ctx context.Context, args krakenFuturesSDK.SendOrderArguments) (krakenFuturesSDK.SendStatus, error)
+	EditOrder(ctx context.Context, args krakenFuturesSDK.EditOrderArguments) (krakenFuturesSDK.EditStatus, error)
+	CancelOrder(ctx context.Context, args krakenFuturesSDK.CancelOrderArguments) (krakenFuturesSDK.CancelStatus, error)
+	CancelAllOrders(ctx context.Context, symbol string) (krakenFuturesSDK.CancelAllStatus, error)
+	CancelAllOrdersAfter(ctx context.Context, timeout uint) (krakenFuturesSDK.DeadManSwitchStatus, error)
+	BatchOrder(ctx context.Context, args krakenFuturesSDK.BatchOrderArguments) ([]krakenFuturesSDK.BatchStatus, error)
+	// ExecutedOrder finds order which was executed with client order ID, it is used when response of order was lost
+	ExecutedOrder(ctx context.Context, userID int, cliOrdID string) (models.Order, error)
+	RateLimitUsage() krakenFuturesSDK.RateLimitUsage
 	ParseSendStatusToExecutedOrder(userID int, sendStatus krakenFuturesSDK.SendStatus) (models.Order, error)
+	ParseBatchStatusToOrders(userID int, statuses []krakenFuturesSDK.BatchStatus) []models.Order
+}
+
+type KrakenOrdersManagerFactory interface {
+	OrdersManager(userID int, publicAPIKey, privateAPIKey string) KrakenOrdersManager
+	EvictOrdersManager(userID int)
+}
+
+// KrakenPaperOrdersManagerFactory provides simulated orders managers which never send orders to kraken
+type KrakenPaperOrdersManagerFactory interface {
+	PaperOrdersManager(userID int) KrakenOrdersManager
+	PaperAccount(userID int) models.PaperAccount
+}
+
+// KrakenInstruments is cached catalog of kraken instruments which is refreshed between Start and Shutdown
+type KrakenInstruments interface {
+	Start()
+	Shutdown()
+	TickSize(ctx context.Context, symbol string) (decimal.Decimal, error)
+	Instrument(ctx context.Context, symbol string) (krakenFuturesSDK.Instrument, error)
 }
 
+// KrakenAnalyzer streams closed candles, candle of period is sent once the period is over
 type KrakenAnalyzer interface {
 	LookForCandles(ctx context.Context, feed string, productsIDs []string) (<-chan krakenFuturesWSSDK.Candle, error)
 }
 
+// KrakenTicker streams the current prices of products, it is used where price is needed before candle is closed
+type KrakenTicker interface {
+	LookForTicker(ctx context.Context, productsIDs []string) (<-chan *krakenFuturesWSSDK.TickerData, error)
+}
+
+// KrakenCandlesHistory loads the last closed candles of feed, so indicators are ready before live candles come.
+// Analyzers which implement it are used for warm up of strategies
+type KrakenCandlesHistory interface {
+	HistoricalCandles(ctx context.Context, feed, productID string, count int) ([]krakenFuturesWSSDK.Candle, error)
+}
+
 type Web struct {
-	KrakenOrdersManager
+	KrakenOrdersManagerFactory
+	KrakenPaperOrdersManagerFactory
+	KrakenInstruments
 	KrakenAnalyzer
 }
 
-func NewWeb(krakenAPISDK *krakenFuturesSDK.API, krakenWebsocketSDK *krakenFuturesWSSDK.WSAPI) *Web {
+func NewWeb(krakenConfig configs.KrakenConfiguration, paperConfig configs.PaperTradingConfiguration, krakenWebsocketSDK *krakenFuturesWSSDK.WSAPI) *Web {
+	publicAPI := krakenFuturesSDK.NewAPI("", "", krakenConfig.APIURL)
+	analyzer := webKraken.NewKrakenAnalyzerWebSDK(krakenWebsocketSDK, publicAPI)
+	instruments := webKraken.NewKrakenInstrumentsWebSDK(publicAPI,
+		time.Duration(krakenConfig.InstrumentsRefreshIntervalInSeconds)*time.Second)
+
 	return &Web{
-		KrakenOrdersManager: webKraken.NewKrakenOrdersManagerWebSDK(krakenAPISDK),
-		KrakenAnalyzer:      webKraken.NewKrakenAnalyzerWebSDK(krakenWebsocketSDK),
+		KrakenOrdersManagerFactory:      NewKrakenOrdersManagers(krakenConfig.APIURL),
+		KrakenPaperOrdersManagerFactory: NewKrakenPaperOrdersManagers(analyzer, paperConfig),
+		KrakenInstruments:               instruments,
+		KrakenAnalyzer:                  analyzer,
 	}
 }
@@ -4,43 +4,144 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 
+	"trade-bot/pkg/krakenFuturesSDK"
 	"trade-bot/pkg/krakenFuturesWSSDK"
 )
 
 var (
 	ErrConvertTradeDataToCandle = errors.New("convert trade data to candle")
 	ErrLookForCandles           = errors.New("look for candles")
+	ErrHistoricalCandles        = errors.New("historical candles")
+	ErrBackfillCandles          = errors.New("backfill missed candles")
+	ErrLookForTicker            = errors.New("look for ticker")
 )
 
 const unixTimeLen = 10
 
 type KrakenAnalyzerWebSDK struct {
 	krakenWebsocketAPI *krakenFuturesWSSDK.WSAPI
+	krakenAPI          *krakenFuturesSDK.API
 }
 
-func NewKrakenAnalyzerWebSDK(krakenWebsocketAPI *krakenFuturesWSSDK.WSAPI) *KrakenAnalyzerWebSDK {
-	return &KrakenAnalyzerWebSDK{krakenWebsocketAPI: krakenWebsocketAPI}
+func NewKrakenAnalyzerWebSDK(krakenWebsocketAPI *krakenFuturesWSSDK.WSAPI, krakenAPI *krakenFuturesSDK.API) *KrakenAnalyzerWebSDK {
+	return &KrakenAnalyzerWebSDK{krakenWebsocketAPI: krakenWebsocketAPI, krakenAPI: krakenAPI}
 }
 
+// HistoricalCandles loads count candles of feed which were closed before the current one from charts API
+func (k *KrakenAnalyzerWebSDK) HistoricalCandles(ctx context.Context, feed, productID string, count int) (
+	[]krakenFuturesWSSDK.Candle, error) {
+	tickType, resolution, err := krakenFuturesWSSDK.ParseCandlesFeed(feed)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrHistoricalCandles, err)
+	}
+
+	// candles opened before the start of the current period are closed
+	period, _ := krakenFuturesWSSDK.ResolutionDuration(resolution)
+	to := time.Now().Truncate(period)
+	candles, err := k.krakenAPI.Candles(ctx, krakenFuturesSDK.CandlesArguments{
+		TickType:   tickType,
+		Symbol:     productID,
+		Resolution: resolution,
+		From:       to.Add(-time.Duration(count) * period),
+		To:         to,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrHistoricalCandles, err)
+	}
+
+	if len(candles) > count {
+		candles = candles[len(candles)-count:]
+	}
+	return candles, nil
+}
+
+// LookForCandles streams closed candles of feed, like candles of HistoricalCandles, so they can be analyzed
+// as continuation of history. Candle of period is sent when the period is over
 func (k *KrakenAnalyzerWebSDK) LookForCandles(ctx context.Context, feed string, productsIDs []string) (<-chan krakenFuturesWSSDK.Candle, error) {
 	tradeDataCh, err := k.krakenWebsocketAPI.CandlesTrade(ctx, feed, productsIDs)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", ErrLookForCandles, err)
 	}
 
-	candleCh, errCh := convertTradeDataToCandle(tradeDataCh)
+	candleCh, errCh := convertTradeDataToCandle(ctx, tradeDataCh)
 	go logErrors(errCh)
 
-	filteredCandles := filterCandles(candleCh)
+	filteredCandles := filterCandles(ctx, candleCh)
 
-	filteredUnixTimeCandles, errCh := filterCandlesUnixTime(filteredCandles)
+	filteredUnixTimeCandles, errCh := filterCandlesUnixTime(ctx, filteredCandles)
 	go logErrors(errCh)
 
-	return filteredUnixTimeCandles, nil
+	if len(productsIDs) != 1 {
+		return filteredUnixTimeCandles, nil
+	}
+	return k.backfillCandles(ctx, feed, productsIDs[0], filteredUnixTimeCandles), nil
+}
+
+// LookForTicker streams ticker of products, it has the current prices of product unlike candles of closed periods
+func (k *KrakenAnalyzerWebSDK) LookForTicker(ctx context.Context, productsIDs []string) (<-chan *krakenFuturesWSSDK.TickerData, error) {
+	tickerCh, err := k.krakenWebsocketAPI.Ticker(ctx, productsIDs)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrLookForTicker, err)
+	}
+	return tickerCh, nil
+}
+
+// backfillCandles loads candles which were missed while connection to kraken was lost from charts API,
+// so consumer gets candle of every period. Candles of feed which charts API does not serve are passed as they are
+func (k *KrakenAnalyzerWebSDK) backfillCandles(ctx context.Context, feed, productID string,
+	candles <-chan krakenFuturesWSSDK.Candle) <-chan krakenFuturesWSSDK.Candle {
+	tickType, resolution, err := krakenFuturesWSSDK.ParseCandlesFeed(feed)
+	if err != nil {
+		return candles
+	}
+	resolutionDuration, _ := krakenFuturesWSSDK.ResolutionDuration(resolution)
+	period := int(resolutionDuration.Seconds())
+
+	candlesChan := make(chan krakenFuturesWSSDK.Candle)
+	go func() {
+		defer close(candlesChan)
+
+		var lastTime int
+		for candle := range candles {
+			if lastTime != 0 && candle.Time-lastTime > period {
+				missed, err := k.krakenAPI.Candles(ctx, krakenFuturesSDK.CandlesArguments{
+					TickType:   tickType,
+					Symbol:     productID,
+					Resolution: resolution,
+					From:       time.Unix(int64(lastTime+period), 0),
+					To:         time.Unix(int64(candle.Time), 0),
+				})
+				if err != nil {
+					log.Warnf("%s: %s", ErrBackfillCandles, err)
+				}
+
+				for _, missedCandle := range missed {
+					if missedCandle.Time <= lastTime || missedCandle.Time >= candle.Time {
+						continue
+					}
+					select {
+					case candlesChan <- missedCandle:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+
+			lastTime = candle.Time
+			select {
+			case candlesChan <- candle:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return candlesChan
 }
 
 func logErrors(errs <-chan error) {
@@ -49,7 +150,7 @@ func logErrors(errs <-chan error) {
 	}
 }
 
-func convertTradeDataToCandle(tradeData <-chan *krakenFuturesWSSDK.CandlesTradeData) (<-chan krakenFuturesWSSDK.Candle, <-chan error) {
+func convertTradeDataToCandle(ctx context.Context, tradeData <-chan *krakenFuturesWSSDK.CandlesTradeData) (<-chan krakenFuturesWSSDK.Candle, <-chan error) {
 	errCh := make(chan error, 1)
 	candlesChan := make(chan krakenFuturesWSSDK.Candle)
 
@@ -58,46 +159,64 @@ func convertTradeDataToCandle(tradeData <-chan *krakenFuturesWSSDK.CandlesTradeD
 		defer close(errCh)
 
 		for data := range tradeData {
+			// candles of snapshot were closed before subscription, only live candles are passed
+			if data.IsSnapshot() {
+				continue
+			}
 			if data.Feed == "error" {
-				errCh <- fmt.Errorf("%s: error feed sended", ErrConvertTradeDataToCandle)
+				select {
+				case errCh <- fmt.Errorf("%s: error feed sended", ErrConvertTradeDataToCandle):
+				case <-ctx.Done():
+					return
+				}
 				continue
 			}
 
-			candlesChan <- data.Candle
+			select {
+			case candlesChan <- data.Candle:
+			case <-ctx.Done():
+				return
+			}
 		}
 	}()
 
 	return candlesChan, errCh
 }
 
-func filterCandles(candles <-chan krakenFuturesWSSDK.Candle) <-chan krakenFuturesWSSDK.Candle {
+// filterCandles passes only closed candles. Kraken sends candle of the current period on every trade, so candle
+// is passed when candle of the next period arrives - it is its last update, the same bar charts API returns for
+// the closed period. Updates of already passed periods are dropped, candle of the current period is never passed
+func filterCandles(ctx context.Context, candles <-chan krakenFuturesWSSDK.Candle) <-chan krakenFuturesWSSDK.Candle {
 	candlesChan := make(chan krakenFuturesWSSDK.Candle)
 
 	go func() {
 		defer close(candlesChan)
 
-		var lastUpdateTime *int
-
+		var (
+			current krakenFuturesWSSDK.Candle
+			started bool
+		)
 		for candle := range candles {
-			if lastUpdateTime == nil {
-				t := candle.Time
-				lastUpdateTime = &t
-				candlesChan <- candle
-				continue
-			}
-			if candle.Time <= *lastUpdateTime {
+			switch {
+			case !started:
+				started = true
+			case candle.Time < current.Time:
 				continue
+			case candle.Time > current.Time:
+				select {
+				case candlesChan <- current:
+				case <-ctx.Done():
+					return
+				}
 			}
-
-			*lastUpdateTime = candle.Time
-			candlesChan <- candle
+			current = candle
 		}
 	}()
 
 	return candlesChan
 }
 
-func filterCandlesUnixTime(candles <-chan krakenFuturesWSSDK.Candle) (<-chan krakenFuturesWSSDK.Candle, <-chan error) {
+func filterCandlesUnixTime(ctx context.Context, candles <-chan krakenFuturesWSSDK.Candle) (<-chan krakenFuturesWSSDK.Candle, <-chan error) {
 	errCh := make(chan error, 1)
 	candlesChan := make(chan krakenFuturesWSSDK.Candle)
 
@@ -113,12 +232,20 @@ func filterCandlesUnixTime(candles <-chan krakenFuturesWSSDK.Candle) (<-chan kra
 
 				newTime, err := strconv.ParseInt(correctedTime, 10, 64)
 				if err != nil {
-					errCh <- err
+					select {
+					case errCh <- err:
+					case <-ctx.Done():
+						return
+					}
 					continue
 				}
 
 				candle.Time = int(newTime)
-				candlesChan <- candle
+				select {
+				case candlesChan <- candle:
+				case <-ctx.Done():
+					return
+				}
 			}
 		}
 	}()
@@ -0,0 +1,161 @@
+package webKraken
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/pkg/krakenFuturesSDK"
+	"trade-bot/pkg/krakenFuturesWSSDK"
+)
+
+func TestKrakenAnalyzerWebSDK_backfillCandles(t *testing.T) {
+	d := decimal.RequireFromString
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/charts/v1/trade/PI_XBTUSD/1m", r.URL.Path)
+		// missed candles are requested from the period after the last received one
+		assert.Equal(t, "1650000060", r.URL.Query().Get("from"))
+		assert.Equal(t, "1650000180", r.URL.Query().Get("to"))
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"candles":[{"time":1650000060000,"open":"1","high":"1","low":"1","close":"101","volume":1},` +
+			`{"time":1650000120000,"open":"1","high":"1","low":"1","close":"102","volume":1},` +
+			`{"time":1650000180000,"open":"1","high":"1","low":"1","close":"103","volume":1}],"more_candles":false}`))
+	}))
+	defer server.Close()
+
+	k := NewKrakenAnalyzerWebSDK(nil, krakenFuturesSDK.NewAPI("", "", server.URL))
+
+	live := make(chan krakenFuturesWSSDK.Candle)
+	go func() {
+		defer close(live)
+		live <- krakenFuturesWSSDK.Candle{Time: 1650000000, Close: d("100")}
+		// connection was lost for two periods
+		live <- krakenFuturesWSSDK.Candle{Time: 1650000180, Close: d("103")}
+		live <- krakenFuturesWSSDK.Candle{Time: 1650000240, Close: d("104")}
+	}()
+
+	var closes []string
+	for candle := range k.backfillCandles(context.Background(), krakenFuturesWSSDK.OneMinuteCandlesFeed, "PI_XBTUSD", live) {
+		closes = append(closes, candle.Close.String())
+	}
+	assert.Equal(t, []string{"100", "101", "102", "103", "104"}, closes)
+}
+
+func TestKrakenAnalyzerWebSDK_backfillCandles_UnknownFeed(t *testing.T) {
+	k := NewKrakenAnalyzerWebSDK(nil, nil)
+
+	live := make(chan krakenFuturesWSSDK.Candle)
+	assert.Equal(t, (<-chan krakenFuturesWSSDK.Candle)(live), k.backfillCandles(context.Background(), "trade", "PI_XBTUSD", live))
+}
+
+func TestKrakenAnalyzerWebSDK_stagesStopWhenCtxIsDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	tradeData := make(chan *krakenFuturesWSSDK.CandlesTradeData, 1)
+	tradeData <- &krakenFuturesWSSDK.CandlesTradeData{Feed: krakenFuturesWSSDK.OneMinuteCandlesFeed,
+		Candle: krakenFuturesWSSDK.Candle{Time: 1650000000000}}
+
+	candles, errs := convertTradeDataToCandle(ctx, tradeData)
+	candles, unixErrs := filterCandlesUnixTime(ctx, filterCandles(ctx, candles))
+	go logErrors(errs)
+	go logErrors(unixErrs)
+
+	// nobody reads candles, stages still stop without waiting for their input to be closed
+	cancel()
+	for range candles {
+	}
+}
+
+func TestFilterCandles(t *testing.T) {
+	d := decimal.RequireFromString
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// every trade updates candle of the current period, the first update is opening tick with O=H=L=C
+	updates := make(chan krakenFuturesWSSDK.Candle)
+	go func() {
+		defer close(updates)
+		for _, update := range []krakenFuturesWSSDK.Candle{
+			{Time: 1650000060000, Open: d("100"), High: d("100"), Low: d("100"), Close: d("100")},
+			{Time: 1650000060000, Open: d("100"), High: d("102"), Low: d("99"), Close: d("101")},
+			// late update of already closed period
+			{Time: 1650000000000, Open: d("90"), High: d("90"), Low: d("90"), Close: d("90")},
+			{Time: 1650000120000, Open: d("101"), High: d("101"), Low: d("101"), Close: d("101")},
+			{Time: 1650000120000, Open: d("101"), High: d("104"), Low: d("100"), Close: d("103")},
+			// candle of the current period is not closed yet
+			{Time: 1650000180000, Open: d("103"), High: d("103"), Low: d("103"), Close: d("103")},
+		} {
+			updates <- update
+		}
+	}()
+
+	var closed []krakenFuturesWSSDK.Candle
+	for candle := range filterCandles(ctx, updates) {
+		closed = append(closed, candle)
+	}
+	assert.Equal(t, []krakenFuturesWSSDK.Candle{
+		{Time: 1650000060000, Open: d("100"), High: d("102"), Low: d("99"), Close: d("101")},
+		{Time: 1650000120000, Open: d("101"), High: d("104"), Low: d("100"), Close: d("103")},
+	}, closed)
+}
+
+// TestKrakenAnalyzerWebSDK_liveCandlesContinueHistory checks that live candles have the same shape as warm up
+// candles of charts API, so indicators warmed up with history are continued with closed bars
+func TestKrakenAnalyzerWebSDK_liveCandlesContinueHistory(t *testing.T) {
+	d := decimal.RequireFromString
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"candles":[{"time":1650000000000,"open":"98","high":"100","low":"97","close":"99","volume":1}],` +
+			`"more_candles":false}`))
+	}))
+	defer server.Close()
+
+	k := NewKrakenAnalyzerWebSDK(nil, krakenFuturesSDK.NewAPI("", "", server.URL))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	history, err := k.krakenAPI.Candles(ctx, krakenFuturesSDK.CandlesArguments{
+		TickType:   krakenFuturesWSSDK.TradePriceSource,
+		Symbol:     "PI_XBTUSD",
+		Resolution: krakenFuturesWSSDK.OneMinuteResolution,
+		From:       time.Unix(1650000000, 0),
+		To:         time.Unix(1650000060, 0),
+	})
+	assert.NoError(t, err)
+
+	tradeData := make(chan *krakenFuturesWSSDK.CandlesTradeData)
+	go func() {
+		defer close(tradeData)
+		for _, candle := range []krakenFuturesWSSDK.Candle{
+			{Time: 1650000060000, Open: d("99"), High: d("99"), Low: d("99"), Close: d("99")},
+			{Time: 1650000060000, Open: d("99"), High: d("103"), Low: d("98"), Close: d("102")},
+			{Time: 1650000120000, Open: d("102"), High: d("102"), Low: d("102"), Close: d("102")},
+		} {
+			tradeData <- &krakenFuturesWSSDK.CandlesTradeData{Feed: krakenFuturesWSSDK.OneMinuteCandlesFeed, Candle: candle}
+		}
+	}()
+
+	converted, errs := convertTradeDataToCandle(ctx, tradeData)
+	go logErrors(errs)
+	live, unixErrs := filterCandlesUnixTime(ctx, filterCandles(ctx, converted))
+	go logErrors(unixErrs)
+
+	candles := history
+	for candle := range k.backfillCandles(ctx, krakenFuturesWSSDK.OneMinuteCandlesFeed, "PI_XBTUSD", live) {
+		candles = append(candles, candle)
+	}
+
+	// the live candle of the next period follows history and is closed bar, not its opening tick
+	assert.Len(t, candles, 2)
+	assert.Equal(t, 1650000000, candles[0].Time)
+	assert.Equal(t, 1650000060, candles[1].Time)
+	assert.Equal(t, "103", candles[1].High.String())
+	assert.Equal(t, "98", candles[1].Low.String())
+	assert.Equal(t, "102", candles[1].Close.String())
+}
@@ -0,0 +1,162 @@
+package webKraken
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+
+	"trade-bot/pkg/krakenFuturesSDK"
+)
+
+var ErrGetInstruments = errors.New("web sdk: get instruments")
+
+const (
+	defaultInstrumentsRefreshInterval = 10 * time.Minute
+	// unknown symbol makes catalog reload at most once per this interval, so typos don't flood kraken
+	minInstrumentsReloadInterval = 30 * time.Second
+)
+
+// KrakenInstrumentsWebSDK is cached catalog of kraken futures instruments. It is refreshed on every interval
+// after Start, so listings, delistings and changed tick sizes are picked up without restart.
+// Unknown symbol makes catalog reload right away, but not more often than once per minInstrumentsReloadInterval
+type KrakenInstrumentsWebSDK struct {
+	api      *krakenFuturesSDK.API
+	interval time.Duration
+	now      func() time.Time
+
+	mu          sync.RWMutex
+	instruments map[string]krakenFuturesSDK.Instrument
+	// requestedAt is when catalog was requested last time, failed requests included,
+	// so kraken which is down is not asked on every unknown symbol
+	requestedAt time.Time
+
+	// ctx is cancelled on Shutdown, so refresh in progress is interrupted
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewKrakenInstrumentsWebSDK(api *krakenFuturesSDK.API, refreshInterval time.Duration) *KrakenInstrumentsWebSDK {
+	if refreshInterval <= 0 {
+		refreshInterval = defaultInstrumentsRefreshInterval
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &KrakenInstrumentsWebSDK{
+		api:         api,
+		interval:    refreshInterval,
+		now:         time.Now,
+		instruments: make(map[string]krakenFuturesSDK.Instrument),
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+// Start loads catalog right away and then refreshes it on every interval until Shutdown
+func (k *KrakenInstrumentsWebSDK) Start() {
+	k.wg.Add(1)
+	go func() {
+		defer k.wg.Done()
+
+		ticker := time.NewTicker(k.interval)
+		defer ticker.Stop()
+
+		for {
+			if err := k.Refresh(k.ctx); err != nil && k.ctx.Err() == nil {
+				log.Error(err)
+			}
+
+			select {
+			case <-k.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Shutdown stops refreshing, catalog keeps serving instruments it has loaded
+func (k *KrakenInstrumentsWebSDK) Shutdown() {
+	k.cancel()
+	k.wg.Wait()
+}
+
+func (k *KrakenInstrumentsWebSDK) TickSize(ctx context.Context, symbol string) (decimal.Decimal, error) {
+	instrument, err := k.Instrument(ctx, symbol)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return instrument.TickSize, nil
+}
+
+// Instrument returns cached instrument, symbol which kraken does not list is reported with
+// krakenFuturesSDK.ValidationError, so orders for it are rejected like other invalid orders
+func (k *KrakenInstrumentsWebSDK) Instrument(ctx context.Context, symbol string) (krakenFuturesSDK.Instrument, error) {
+	key := strings.ToLower(symbol)
+	if instrument, ok := k.lookup(key); ok {
+		return instrument, nil
+	}
+
+	k.mu.Lock()
+	reload := k.now().Sub(k.requestedAt) >= minInstrumentsReloadInterval
+	if reload {
+		k.requestedAt = k.now()
+	}
+	k.mu.Unlock()
+
+	if reload {
+		if err := k.load(ctx); err != nil {
+			return krakenFuturesSDK.Instrument{}, err
+		}
+		if instrument, ok := k.lookup(key); ok {
+			return instrument, nil
+		}
+	}
+
+	return krakenFuturesSDK.Instrument{}, krakenFuturesSDK.NewValidationError(symbol, krakenFuturesSDK.CodeUnknownInstrument,
+		"kraken futures does not list instrument")
+}
+
+// Refresh replaces catalog with instruments kraken lists now
+func (k *KrakenInstrumentsWebSDK) Refresh(ctx context.Context) error {
+	k.mu.Lock()
+	k.requestedAt = k.now()
+	k.mu.Unlock()
+
+	return k.load(ctx)
+}
+
+func (k *KrakenInstrumentsWebSDK) load(ctx context.Context) error {
+	response, err := k.api.Instruments(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrGetInstruments, err)
+	}
+
+	if err := response.Err(); err != nil {
+		return fmt.Errorf("%s: %w", ErrGetInstruments, err)
+	}
+
+	instruments := make(map[string]krakenFuturesSDK.Instrument, len(response.Instruments))
+	for _, instrument := range response.Instruments {
+		instruments[strings.ToLower(instrument.Symbol)] = instrument
+	}
+
+	k.mu.Lock()
+	k.instruments = instruments
+	k.mu.Unlock()
+	return nil
+}
+
+func (k *KrakenInstrumentsWebSDK) lookup(key string) (krakenFuturesSDK.Instrument, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	instrument, ok := k.instruments[key]
+	return instrument, ok
+}
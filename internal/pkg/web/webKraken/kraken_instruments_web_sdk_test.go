@@ -0,0 +1,73 @@
+package webKraken
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/pkg/krakenFuturesSDK"
+)
+
+func TestKrakenInstrumentsWebSDK_Instrument(t *testing.T) {
+	var (
+		requests int32
+		failing  bool
+	)
+	listed := `{"symbol":"PI_XBTUSD","tradeable":true,"tickSize":0.5}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if failing {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"result":"success","instruments":[` + listed + `]}`))
+	}))
+	defer server.Close()
+
+	now := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+	k := NewKrakenInstrumentsWebSDK(krakenFuturesSDK.NewAPI("", "", server.URL,
+		krakenFuturesSDK.WithRetryPolicy(krakenFuturesSDK.NoRetry)), time.Hour)
+	k.now = func() time.Time { return now }
+
+	// catalog is loaded on first request
+	instrument, err := k.Instrument(context.Background(), "pi_xbtusd")
+	assert.NoError(t, err)
+	assert.Equal(t, "0.5", instrument.TickSize.String())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	// unknown symbol right after load does not reload catalog
+	_, err = k.Instrument(context.Background(), "pi_dogeusd")
+	var validationErr *krakenFuturesSDK.ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, krakenFuturesSDK.CodeUnknownInstrument, validationErr.Code)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	// new listing is picked up by reload on unknown symbol
+	listed = `{"symbol":"PI_DOGEUSD","tradeable":true,"tickSize":0.0001}`
+	now = now.Add(minInstrumentsReloadInterval)
+	instrument, err = k.Instrument(context.Background(), "PI_DOGEUSD")
+	assert.NoError(t, err)
+	assert.Equal(t, "0.0001", instrument.TickSize.String())
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+
+	// delisted instrument is dropped from catalog
+	_, err = k.Instrument(context.Background(), "pi_xbtusd")
+	assert.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+
+	// failed reload is throttled too
+	failing = true
+	now = now.Add(minInstrumentsReloadInterval)
+	_, err = k.Instrument(context.Background(), "pi_xbtusd")
+	assert.Contains(t, err.Error(), ErrGetInstruments.Error())
+	_, err = k.Instrument(context.Background(), "pi_xbtusd")
+	assert.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+}
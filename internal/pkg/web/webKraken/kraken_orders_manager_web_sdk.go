@@ -1,9 +1,11 @@
 package webKraken
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
 
 	"trade-bot/internal/pkg/models"
 	"trade-bot/pkg/krakenFuturesSDK"
@@ -14,7 +16,10 @@ var (
 	ErrEditOrder             = errors.New("web sdk: edit order")
 	ErrCancelOrder           = errors.New("web sdk: cancel order")
 	ErrCancelAllOrders       = errors.New("web sdk: cancel all orders")
-	ErrInvalidStatus         = errors.New("invalid status")
+	ErrBatchOrder            = errors.New("web sdk: batch order")
+	ErrCancelAllOrdersAfter  = errors.New("web sdk: cancel all orders after")
+	ErrExecutedOrder         = errors.New("web sdk: executed order")
+	ErrOrderNotFound         = errors.New("order with client order id is not found")
 	ErrUnknownSendStatusType = errors.New("unknown send status type")
 )
 
@@ -26,100 +31,265 @@ func NewKrakenOrdersManagerWebSDK(api *krakenFuturesSDK.API) *KrakenOrdersManage
 	return &KrakenOrdersManagerWebSDK{api: api}
 }
 
-func (k *KrakenOrdersManagerWebSDK) SendOrder(args krakenFuturesSDK.SendOrderArguments) (krakenFuturesSDK.SendStatus, error) {
-	response, err := k.api.SendOrder(args)
+func (k *KrakenOrdersManagerWebSDK) SendOrder(ctx context.Context, args krakenFuturesSDK.SendOrderArguments) (krakenFuturesSDK.SendStatus, error) {
+	response, err := k.api.SendOrder(ctx, args)
 	if err != nil {
 		return krakenFuturesSDK.SendStatus{}, fmt.Errorf("%s: %w", ErrSendOrder, err)
 	}
 
-	if response.Error != "" {
-		err := fmt.Errorf("err: %s, server time: %s, result: %s", response.Error, response.ServerTime, response.Result)
+	if err := response.Err(); err != nil {
 		return krakenFuturesSDK.SendStatus{}, fmt.Errorf("%s: %w", ErrSendOrder, err)
 	}
 
 	if !response.SendStatus.Status.IsSuccessStatus() {
-		err := fmt.Errorf("%s: status: %s", ErrInvalidStatus, response.SendStatus.Status)
+		err := krakenFuturesSDK.NewStatusError(string(response.SendStatus.Status))
 		return krakenFuturesSDK.SendStatus{}, fmt.Errorf("%s: %w", ErrSendOrder, err)
 	}
 
 	return response.SendStatus, nil
 }
 
-func (k *KrakenOrdersManagerWebSDK) EditOrder(args krakenFuturesSDK.EditOrderArguments) (krakenFuturesSDK.EditStatus, error) {
-	response, err := k.api.EditOrder(args)
+func (k *KrakenOrdersManagerWebSDK) EditOrder(ctx context.Context, args krakenFuturesSDK.EditOrderArguments) (krakenFuturesSDK.EditStatus, error) {
+	response, err := k.api.EditOrder(ctx, args)
 	if err != nil {
 		return krakenFuturesSDK.EditStatus{}, fmt.Errorf("%s: %w", ErrEditOrder, err)
 	}
 
-	if response.Error != "" {
-		err := fmt.Errorf("err: %s, server time: %s, result: %s", response.Error, response.ServerTime, response.Result)
+	if err := response.Err(); err != nil {
 		return krakenFuturesSDK.EditStatus{}, fmt.Errorf("%s: %w", ErrEditOrder, err)
 	}
 
 	if !response.EditStatus.Status.IsSuccessStatus() {
-		err := fmt.Errorf("%s: status: %s", ErrInvalidStatus, response.EditStatus.Status)
+		err := krakenFuturesSDK.NewStatusError(string(response.EditStatus.Status))
 		return krakenFuturesSDK.EditStatus{}, fmt.Errorf("%s: %w", ErrEditOrder, err)
 	}
 
 	return response.EditStatus, nil
 }
 
-func (k *KrakenOrdersManagerWebSDK) CancelOrder(args krakenFuturesSDK.CancelOrderArguments) (krakenFuturesSDK.CancelStatus, error) {
-	response, err := k.api.CancelOrder(args)
+func (k *KrakenOrdersManagerWebSDK) CancelOrder(ctx context.Context, args krakenFuturesSDK.CancelOrderArguments) (krakenFuturesSDK.CancelStatus, error) {
+	response, err := k.api.CancelOrder(ctx, args)
 	if err != nil {
 		return krakenFuturesSDK.CancelStatus{}, fmt.Errorf("%s: %w", ErrCancelOrder, err)
 	}
 
-	if response.Error != "" {
-		err := fmt.Errorf("err: %s, server time: %s, result: %s", response.Error, response.ServerTime, response.Result)
+	if err := response.Err(); err != nil {
 		return krakenFuturesSDK.CancelStatus{}, fmt.Errorf("%s: %w", ErrCancelOrder, err)
 	}
 
 	if !response.CancelStatus.Status.IsSuccessStatus() {
-		err := fmt.Errorf("%s: status: %s", ErrInvalidStatus, response.CancelStatus.Status)
+		err := krakenFuturesSDK.NewStatusError(string(response.CancelStatus.Status))
 		return krakenFuturesSDK.CancelStatus{}, fmt.Errorf("%s: %w", ErrCancelOrder, err)
 	}
 
 	return response.CancelStatus, nil
 }
 
-func (k *KrakenOrdersManagerWebSDK) CancelAllOrders(symbol string) (krakenFuturesSDK.CancelAllStatus, error) {
-	response, err := k.api.CancelAllOrders(symbol)
+func (k *KrakenOrdersManagerWebSDK) CancelAllOrders(ctx context.Context, symbol string) (krakenFuturesSDK.CancelAllStatus, error) {
+	response, err := k.api.CancelAllOrders(ctx, symbol)
 	if err != nil {
 		return krakenFuturesSDK.CancelAllStatus{}, fmt.Errorf("%s: %w", ErrCancelAllOrders, err)
 	}
 
-	if response.Error != "" {
-		err := fmt.Errorf("err: %s, server time: %s, result: %s", response.Error, response.ServerTime, response.Result)
+	if err := response.Err(); err != nil {
 		return krakenFuturesSDK.CancelAllStatus{}, fmt.Errorf("%s: %w", ErrCancelAllOrders, err)
 	}
 
 	if !response.CancelStatus.Status.IsSuccessStatus() {
-		err := fmt.Errorf("%s: status: %s", ErrInvalidStatus, response.CancelStatus.Status)
+		err := krakenFuturesSDK.NewStatusError(string(response.CancelStatus.Status))
 		return krakenFuturesSDK.CancelAllStatus{}, fmt.Errorf("%s: %w", ErrCancelAllOrders, err)
 	}
 
 	return response.CancelStatus, nil
 }
 
+func (k *KrakenOrdersManagerWebSDK) CancelAllOrdersAfter(ctx context.Context, timeout uint) (krakenFuturesSDK.DeadManSwitchStatus, error) {
+	response, err := k.api.CancelAllOrdersAfter(ctx, timeout)
+	if err != nil {
+		return krakenFuturesSDK.DeadManSwitchStatus{}, fmt.Errorf("%s: %w", ErrCancelAllOrdersAfter, err)
+	}
+
+	if err := response.Err(); err != nil {
+		return krakenFuturesSDK.DeadManSwitchStatus{}, fmt.Errorf("%s: %w", ErrCancelAllOrdersAfter, err)
+	}
+
+	return response.Status, nil
+}
+
+// BatchOrder returns status of every instruction, batch is not failed if only some of instructions are rejected
+func (k *KrakenOrdersManagerWebSDK) BatchOrder(ctx context.Context, args krakenFuturesSDK.BatchOrderArguments) ([]krakenFuturesSDK.BatchStatus, error) {
+	response, err := k.api.BatchOrder(ctx, args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrBatchOrder, err)
+	}
+
+	if err := response.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrBatchOrder, err)
+	}
+
+	return response.BatchStatus, nil
+}
+
+// ExecutedOrder looks for order among the last fills of account, order filled by several fills has their sum
+// and average price
+func (k *KrakenOrdersManagerWebSDK) ExecutedOrder(ctx context.Context, userID int, cliOrdID string) (models.Order, error) {
+	response, err := k.api.Fills(ctx, "")
+	if err != nil {
+		return models.Order{}, fmt.Errorf("%s: %w", ErrExecutedOrder, err)
+	}
+
+	if err := response.Err(); err != nil {
+		return models.Order{}, fmt.Errorf("%s: %w", ErrExecutedOrder, err)
+	}
+
+	order, ok := orderFromFills(userID, cliOrdID, response.Fills)
+	if !ok {
+		return models.Order{}, fmt.Errorf("%s: %w: %s", ErrExecutedOrder, ErrOrderNotFound, cliOrdID)
+	}
+	return order, nil
+}
+
+func (k *KrakenOrdersManagerWebSDK) RateLimitUsage() krakenFuturesSDK.RateLimitUsage {
+	return k.api.RateLimitUsage()
+}
+
+func (k *KrakenOrdersManagerWebSDK) ParseBatchStatusToOrders(userID int, statuses []krakenFuturesSDK.BatchStatus) []models.Order {
+	return parseBatchStatusToOrders(userID, statuses)
+}
+
 func (k *KrakenOrdersManagerWebSDK) ParseSendStatusToExecutedOrder(userID int, sendStatus krakenFuturesSDK.SendStatus) (models.Order, error) {
-	orderEvent := sendStatus.OrderEvents[0]
-
-	if orderEvent.Type == "EXECUTION" {
-		return models.Order{
-			ID:                  orderEvent.OrderPriorExecution.OrderID,
-			UserID:              userID,
-			ClientOrderID:       orderEvent.OrderPriorExecution.CliOrderID,
-			Type:                orderEvent.Type,
-			Symbol:              orderEvent.OrderPriorExecution.Symbol,
-			Quantity:            orderEvent.OrderPriorExecution.Quantity,
-			Side:                orderEvent.OrderPriorExecution.Side,
-			Price:               orderEvent.Price,
-			Filled:              orderEvent.OrderPriorExecution.Filled,
-			Timestamp:           orderEvent.OrderPriorExecution.Timestamp,
-			LastUpdateTimestamp: orderEvent.OrderPriorExecution.LastUpdateTimestamp,
-		}, nil
-	}
-
-	return models.Order{}, ErrUnknownSendStatusType
+	return parseSendStatusToExecutedOrder(userID, sendStatus)
+}
+
+func parseSendStatusToExecutedOrder(userID int, sendStatus krakenFuturesSDK.SendStatus) (models.Order, error) {
+	orders := ordersFromEvents(userID, sendStatus.OrderEvents)
+	if len(orders) == 0 || orders[0].Type != executionEventType {
+		return models.Order{}, ErrUnknownSendStatusType
+	}
+	return orders[0], nil
+}
+
+// parseBatchStatusToOrders returns orders which were placed or executed by batch,
+// edited and cancelled orders already exist, so they are skipped
+func parseBatchStatusToOrders(userID int, statuses []krakenFuturesSDK.BatchStatus) []models.Order {
+	orders := make([]models.Order, 0, len(statuses))
+	for _, status := range statuses {
+		orders = append(orders, ordersFromEvents(userID, status.OrderEvents)...)
+	}
+	return orders
+}
+
+// ordersFromEvents merges place and execution events into one order per order ID. Order which was filled
+// has sum of all its fills and their average price, so it is stored once however many executions it had
+func ordersFromEvents(userID int, events []krakenFuturesSDK.OrderEvent) []models.Order {
+	orders := make([]models.Order, 0, 1)
+	indexes := make(map[string]int)
+	executed := make(map[string]decimal.Decimal)
+	notional := make(map[string]decimal.Decimal)
+
+	for _, event := range events {
+		var order krakenFuturesSDK.Order
+		switch event.Type {
+		case executionEventType:
+			order = event.OrderPriorExecution
+		case placeEventType:
+			order = event.Order
+		default:
+			continue
+		}
+
+		i, ok := indexes[order.OrderID]
+		if !ok {
+			price := order.LimitPrice
+			if price.IsZero() {
+				price = order.StopPrice
+			}
+			i = len(orders)
+			indexes[order.OrderID] = i
+			orders = append(orders, orderFromEvent(userID, event.Type, order, price))
+		}
+		if order.LastUpdateTimestamp != "" {
+			orders[i].LastUpdateTimestamp = order.LastUpdateTimestamp
+		}
+		if event.Type != executionEventType {
+			continue
+		}
+
+		merged := &orders[i]
+		if merged.Type != executionEventType {
+			merged.Type = executionEventType
+			merged.Filled = order.Filled
+		}
+		merged.Filled = merged.Filled.Add(event.Amount)
+		executed[order.OrderID] = executed[order.OrderID].Add(event.Amount)
+		notional[order.OrderID] = notional[order.OrderID].Add(event.Amount.Mul(event.Price))
+
+		// execution without amount can't be weighted, its price is taken as is
+		merged.Price = event.Price
+		if amount := executed[order.OrderID]; amount.IsPositive() {
+			merged.Price = notional[order.OrderID].Div(amount)
+		}
+	}
+	return orders
+}
+
+func orderFromEvent(userID int, eventType string, order krakenFuturesSDK.Order, price decimal.Decimal) models.Order {
+	return models.Order{
+		ID:                  order.OrderID,
+		UserID:              userID,
+		ClientOrderID:       order.CliOrderID,
+		Type:                eventType,
+		Symbol:              order.Symbol,
+		Quantity:            order.Quantity,
+		Side:                order.Side,
+		Price:               price,
+		Filled:              order.Filled,
+		Timestamp:           order.Timestamp,
+		LastUpdateTimestamp: order.LastUpdateTimestamp,
+	}
+}
+
+// orderFromFills merges fills of order with client order ID, order is timestamped with its first fill
+func orderFromFills(userID int, cliOrdID string, fills []krakenFuturesSDK.Fill) (models.Order, bool) {
+	var (
+		order    models.Order
+		notional decimal.Decimal
+		found    bool
+	)
+	for _, fill := range fills {
+		if fill.CliOrdID != cliOrdID {
+			continue
+		}
+		if !found {
+			found = true
+			order = models.Order{
+				ID:                  fill.OrderID,
+				UserID:              userID,
+				ClientOrderID:       fill.CliOrdID,
+				Type:                executionEventType,
+				Symbol:              fill.Symbol,
+				Side:                fill.Side,
+				Timestamp:           fill.FillTime,
+				LastUpdateTimestamp: fill.FillTime,
+			}
+		}
+		// fill times have the same ISO8601 layout, so they are ordered as strings
+		if fill.FillTime < order.Timestamp {
+			order.Timestamp = fill.FillTime
+		}
+		if fill.FillTime > order.LastUpdateTimestamp {
+			order.LastUpdateTimestamp = fill.FillTime
+		}
+		order.Quantity = order.Quantity.Add(fill.Size)
+		order.Filled = order.Filled.Add(fill.Size)
+		notional = notional.Add(fill.Size.Mul(fill.Price))
+	}
+	if !found {
+		return models.Order{}, false
+	}
+
+	if order.Filled.IsPositive() {
+		order.Price = notional.Div(order.Filled)
+	}
+	return order, true
 }
@@ -0,0 +1,153 @@
+package webKraken
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/internal/pkg/models"
+	"trade-bot/pkg/krakenFuturesSDK"
+)
+
+func TestParseBatchStatusToOrders(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []krakenFuturesSDK.BatchStatus
+		want     []models.Order
+	}{
+		{
+			name: "Executed entry and placed stop",
+			statuses: []krakenFuturesSDK.BatchStatus{
+				{
+					Status:   "placed",
+					OrderTag: "0",
+					OrderID:  "entry",
+					OrderEvents: []krakenFuturesSDK.OrderEvent{{
+						Type:  executionEventType,
+						Price: decimal.NewFromInt(100),
+						OrderPriorExecution: krakenFuturesSDK.Order{
+							OrderID: "entry", Symbol: "pi_xbtusd", Side: "buy", Quantity: decimal.NewFromInt(1), Timestamp: "t",
+						},
+					}},
+				},
+				{
+					Status:   "placed",
+					OrderTag: "1",
+					OrderID:  "stop",
+					OrderEvents: []krakenFuturesSDK.OrderEvent{{
+						Type: placeEventType,
+						Order: krakenFuturesSDK.Order{
+							OrderID: "stop", Symbol: "pi_xbtusd", Side: "sell", Quantity: decimal.NewFromInt(1), StopPrice: decimal.NewFromInt(95), ReduceOnly: true,
+						},
+					}},
+				},
+			},
+			want: []models.Order{
+				{ID: "entry", UserID: 1, Type: executionEventType, Symbol: "pi_xbtusd", Side: "buy", Quantity: decimal.NewFromInt(1), Price: decimal.NewFromInt(100), Timestamp: "t"},
+				{ID: "stop", UserID: 1, Type: placeEventType, Symbol: "pi_xbtusd", Side: "sell", Quantity: decimal.NewFromInt(1), Price: decimal.NewFromInt(95)},
+			},
+		},
+		{
+			name: "Placed and filled order is one order with sum of fills",
+			statuses: []krakenFuturesSDK.BatchStatus{
+				{
+					Status:   "placed",
+					OrderTag: "0",
+					OrderID:  "entry",
+					OrderEvents: []krakenFuturesSDK.OrderEvent{
+						{
+							Type: placeEventType,
+							Order: krakenFuturesSDK.Order{
+								OrderID: "entry", Symbol: "pi_xbtusd", Side: "buy", Quantity: decimal.NewFromInt(4), LimitPrice: decimal.NewFromInt(101),
+								Timestamp: "t1", LastUpdateTimestamp: "t1",
+							},
+						},
+						{
+							Type:   executionEventType,
+							Amount: decimal.NewFromInt(1),
+							Price:  decimal.NewFromInt(100),
+							OrderPriorExecution: krakenFuturesSDK.Order{
+								OrderID: "entry", Symbol: "pi_xbtusd", Side: "buy", Quantity: decimal.NewFromInt(4), LimitPrice: decimal.NewFromInt(101),
+								Timestamp: "t1", LastUpdateTimestamp: "t1",
+							},
+						},
+						{
+							Type:   executionEventType,
+							Amount: decimal.NewFromInt(3),
+							Price:  decimal.NewFromInt(101),
+							OrderPriorExecution: krakenFuturesSDK.Order{
+								OrderID: "entry", Symbol: "pi_xbtusd", Side: "buy", Quantity: decimal.NewFromInt(4), LimitPrice: decimal.NewFromInt(101),
+								Filled: decimal.NewFromInt(1), Timestamp: "t1", LastUpdateTimestamp: "t2",
+							},
+						},
+					},
+				},
+			},
+			want: []models.Order{
+				{ID: "entry", UserID: 1, Type: executionEventType, Symbol: "pi_xbtusd", Side: "buy", Quantity: decimal.NewFromInt(4),
+					Filled: decimal.NewFromInt(4), Price: decimal.RequireFromString("100.75"), Timestamp: "t1", LastUpdateTimestamp: "t2"},
+			},
+		},
+		{
+			name: "Cancelled and rejected orders are skipped",
+			statuses: []krakenFuturesSDK.BatchStatus{
+				{
+					Status:      "cancelled",
+					OrderID:     "old",
+					OrderEvents: []krakenFuturesSDK.OrderEvent{{Type: "CANCEL", UID: "old"}},
+				},
+				{Status: "invalidSize", OrderTag: "1"},
+			},
+			want: []models.Order{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assertOrdersEqual(t, test.want, parseBatchStatusToOrders(1, test.statuses))
+		})
+	}
+}
+
+// assertOrdersEqual compares decimals of orders by value, so their representation does not matter
+func assertOrdersEqual(t *testing.T, want, got []models.Order) {
+	if !assert.Len(t, got, len(want)) {
+		return
+	}
+	for i := range want {
+		for _, pair := range [][2]*decimal.Decimal{
+			{&want[i].Quantity, &got[i].Quantity},
+			{&want[i].Filled, &got[i].Filled},
+			{&want[i].Price, &got[i].Price},
+		} {
+			assert.Truef(t, pair[0].Equal(*pair[1]), "order %s: want %s, got %s", want[i].ID, pair[0], pair[1])
+			*pair[0], *pair[1] = decimal.Zero, decimal.Zero
+		}
+		assert.Equal(t, want[i], got[i])
+	}
+}
+
+func TestOrderFromFills(t *testing.T) {
+	d := decimal.RequireFromString
+	fills := []krakenFuturesSDK.Fill{
+		{OrderID: "exit", CliOrdID: "session-exit-1", Symbol: "pi_xbtusd", Side: "sell", Size: d("3"), Price: d("102"),
+			FillTime: "2022-04-15T10:00:01.000Z"},
+		{OrderID: "other", CliOrdID: "other", Symbol: "pi_xbtusd", Side: "buy", Size: d("1"), Price: d("90"),
+			FillTime: "2022-04-15T10:00:00.500Z"},
+		{OrderID: "exit", CliOrdID: "session-exit-1", Symbol: "pi_xbtusd", Side: "sell", Size: d("1"), Price: d("100"),
+			FillTime: "2022-04-15T10:00:00.000Z"},
+	}
+
+	order, ok := orderFromFills(1, "session-exit-1", fills)
+	assert.True(t, ok)
+	assert.Equal(t, "exit", order.ID)
+	assert.Equal(t, executionEventType, order.Type)
+	assert.Equal(t, "4", order.Filled.String())
+	assert.Equal(t, "101.5", order.Price.String())
+	assert.Equal(t, "2022-04-15T10:00:00.000Z", order.Timestamp)
+	assert.Equal(t, "2022-04-15T10:00:01.000Z", order.LastUpdateTimestamp)
+
+	_, ok = orderFromFills(1, "unknown", fills)
+	assert.False(t, ok)
+}
@@ -0,0 +1,331 @@
+package webKraken
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+
+	"trade-bot/internal/pkg/models"
+	"trade-bot/pkg/krakenFuturesSDK"
+)
+
+var (
+	ErrPaperSendOrder            = errors.New("paper: send order")
+	ErrPaperEditOrder            = errors.New("paper: edit order")
+	ErrPaperCancelOrder          = errors.New("paper: cancel order")
+	ErrPaperCancelAllOrders      = errors.New("paper: cancel all orders")
+	ErrPaperBatchOrder           = errors.New("paper: batch order")
+	ErrPaperExecutedOrder        = errors.New("paper: executed order")
+	ErrBatchIsNotSupported       = errors.New("batch orders are not supported by paper trading")
+	ErrInvalidOrderSide          = errors.New("invalid order side")
+	ErrInvalidOrderSize          = errors.New("invalid order size")
+	ErrUnsupportedPaperOrderType = errors.New("order type is not supported by paper trading")
+	ErrOrderIsNotMarketable      = errors.New("limit order can't be executed at current price")
+	ErrReduceOnlyOrder           = errors.New("reduce only order would increase position")
+)
+
+const (
+	marketOrderType            = "mkt"
+	limitOrderType             = "lmt"
+	immediateOrCancelOrderType = "ioc"
+
+	executionEventType = "EXECUTION"
+	placeEventType     = "PLACE"
+	placedStatus       = "placed"
+	notFoundStatus     = "notFound"
+	noOrdersStatus     = "noOrdersToCancel"
+	// insufficientFundsStatus is status kraken rejects order with when margin of account can't cover it
+	insufficientFundsStatus = "insufficientAvailableFunds"
+
+	krakenTimeLayout = "2006-01-02T15:04:05.000Z07:00"
+)
+
+type priceSource interface {
+	LastPrice(ctx context.Context, symbol string) (decimal.Decimal, error)
+	CachedPrice(symbol string) (decimal.Decimal, bool)
+}
+
+type paperPosition struct {
+	size       decimal.Decimal
+	entryPrice decimal.Decimal
+}
+
+// KrakenPaperOrdersManager simulates kraken futures venue for one user: orders are filled immediately
+// at the last seen market price and change virtual balance and positions instead of real ones.
+// Every fill is linear - pnl and fees are counted in quote currency. Positions are margined by their entry
+// notional without leverage, so order is rejected when balance can't cover it
+type KrakenPaperOrdersManager struct {
+	userID  int
+	prices  priceSource
+	feeRate decimal.Decimal
+
+	mu          sync.Mutex
+	balance     decimal.Decimal
+	realizedPnL decimal.Decimal
+	fees        decimal.Decimal
+	positions   map[string]*paperPosition
+}
+
+func NewKrakenPaperOrdersManager(userID int, prices priceSource, initialBalance, feeRate decimal.Decimal) *KrakenPaperOrdersManager {
+	return &KrakenPaperOrdersManager{
+		userID:    userID,
+		prices:    prices,
+		feeRate:   feeRate,
+		balance:   initialBalance,
+		positions: make(map[string]*paperPosition),
+	}
+}
+
+func (k *KrakenPaperOrdersManager) SendOrder(ctx context.Context, args krakenFuturesSDK.SendOrderArguments) (krakenFuturesSDK.SendStatus, error) {
+	direction, err := orderDirection(args)
+	if err != nil {
+		return krakenFuturesSDK.SendStatus{}, fmt.Errorf("%s: %w", ErrPaperSendOrder, err)
+	}
+
+	price, err := k.prices.LastPrice(ctx, args.Symbol)
+	if err != nil {
+		return krakenFuturesSDK.SendStatus{}, fmt.Errorf("%s: %w", ErrPaperSendOrder, err)
+	}
+
+	switch args.OrderType {
+	case marketOrderType:
+	case limitOrderType, immediateOrCancelOrderType:
+		if direction.IsPositive() && price.GreaterThan(args.LimitPrice) || direction.IsNegative() && price.LessThan(args.LimitPrice) {
+			return krakenFuturesSDK.SendStatus{}, fmt.Errorf("%s: %w", ErrPaperSendOrder, ErrOrderIsNotMarketable)
+		}
+	default:
+		err := fmt.Errorf("%s: %s", ErrUnsupportedPaperOrderType, args.OrderType)
+		return krakenFuturesSDK.SendStatus{}, fmt.Errorf("%s: %w", ErrPaperSendOrder, err)
+	}
+
+	size := args.Size.Mul(direction)
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	position := k.positions[args.Symbol]
+	if args.ReduceOnly && (position == nil || position.size.Mul(size).IsPositive() || size.Abs().GreaterThan(position.size.Abs())) {
+		return krakenFuturesSDK.SendStatus{}, fmt.Errorf("%s: %w", ErrPaperSendOrder, ErrReduceOnlyOrder)
+	}
+
+	if !k.canCover(args.Symbol, size, price) {
+		err := krakenFuturesSDK.NewStatusError(insufficientFundsStatus)
+		return krakenFuturesSDK.SendStatus{}, fmt.Errorf("%s: %w", ErrPaperSendOrder, err)
+	}
+
+	k.fill(args.Symbol, size, price)
+
+	return newPaperSendStatus(args, price)
+}
+
+// canCover reports whether balance left by margin of other positions covers margin of position of symbol after
+// fill of signed size and fee of the fill. Order which only reduces position is always covered
+func (k *KrakenPaperOrdersManager) canCover(symbol string, size, price decimal.Decimal) bool {
+	var margin decimal.Decimal
+	position, ok := k.positions[symbol]
+	switch {
+	case !ok:
+		margin = size.Abs().Mul(price)
+	case position.size.Mul(size).IsPositive():
+		margin = position.size.Abs().Mul(position.entryPrice).Add(size.Abs().Mul(price))
+	case size.Abs().LessThanOrEqual(position.size.Abs()):
+		return true
+	default:
+		margin = size.Abs().Sub(position.size.Abs()).Mul(price)
+	}
+
+	available := k.balance
+	for positionSymbol, position := range k.positions {
+		if positionSymbol != symbol {
+			available = available.Sub(position.size.Abs().Mul(position.entryPrice))
+		}
+	}
+
+	fee := size.Abs().Mul(price).Mul(k.feeRate)
+	return margin.Add(fee).LessThanOrEqual(available)
+}
+
+// fill changes position by signed size, realizing pnl of its closed part
+func (k *KrakenPaperOrdersManager) fill(symbol string, size, price decimal.Decimal) {
+	fee := size.Abs().Mul(price).Mul(k.feeRate)
+	k.fees = k.fees.Add(fee)
+	k.balance = k.balance.Sub(fee)
+
+	position, ok := k.positions[symbol]
+	if !ok {
+		position = &paperPosition{}
+		k.positions[symbol] = position
+	}
+
+	newSize := position.size.Add(size)
+	switch {
+	case position.size.Mul(size).Sign() >= 0:
+		cost := position.entryPrice.Mul(position.size.Abs()).Add(price.Mul(size.Abs()))
+		position.entryPrice = cost.Div(newSize.Abs())
+	default:
+		closed := decimal.Min(size.Abs(), position.size.Abs())
+		pnl := price.Sub(position.entryPrice).Mul(closed)
+		if position.size.IsNegative() {
+			pnl = pnl.Neg()
+		}
+		k.realizedPnL = k.realizedPnL.Add(pnl)
+		k.balance = k.balance.Add(pnl)
+
+		if newSize.Mul(position.size).IsNegative() {
+			position.entryPrice = price
+		}
+	}
+	position.size = newSize
+
+	if position.size.IsZero() {
+		delete(k.positions, symbol)
+	}
+}
+
+func (k *KrakenPaperOrdersManager) EditOrder(_ context.Context, args krakenFuturesSDK.EditOrderArguments) (krakenFuturesSDK.EditStatus, error) {
+	err := krakenFuturesSDK.NewStatusError(notFoundStatus)
+	return krakenFuturesSDK.EditStatus{}, fmt.Errorf("%s: %w", ErrPaperEditOrder, err)
+}
+
+func (k *KrakenPaperOrdersManager) CancelOrder(_ context.Context, args krakenFuturesSDK.CancelOrderArguments) (krakenFuturesSDK.CancelStatus, error) {
+	err := krakenFuturesSDK.NewStatusError(notFoundStatus)
+	return krakenFuturesSDK.CancelStatus{}, fmt.Errorf("%s: %w", ErrPaperCancelOrder, err)
+}
+
+// CancelAllOrders behaves like kraken without open orders, because paper orders never rest in the book
+func (k *KrakenPaperOrdersManager) CancelAllOrders(_ context.Context, symbol string) (krakenFuturesSDK.CancelAllStatus, error) {
+	err := krakenFuturesSDK.NewStatusError(noOrdersStatus)
+	return krakenFuturesSDK.CancelAllStatus{}, fmt.Errorf("%s: %w", ErrPaperCancelAllOrders, err)
+}
+
+// CancelAllOrdersAfter does nothing, paper account never keeps resting orders which could be left behind
+func (k *KrakenPaperOrdersManager) CancelAllOrdersAfter(_ context.Context, timeout uint) (krakenFuturesSDK.DeadManSwitchStatus, error) {
+	return krakenFuturesSDK.DeadManSwitchStatus{CurrentTime: time.Now().UTC().Format(krakenTimeLayout)}, nil
+}
+
+// BatchOrder is rejected, paper account fills orders at once, so it can't keep resting orders of the batch
+func (k *KrakenPaperOrdersManager) BatchOrder(_ context.Context, args krakenFuturesSDK.BatchOrderArguments) ([]krakenFuturesSDK.BatchStatus, error) {
+	return nil, fmt.Errorf("%s: %w", ErrPaperBatchOrder, ErrBatchIsNotSupported)
+}
+
+// ExecutedOrder never finds order, paper order is filled in the same call which sends it, so its response is never lost
+func (k *KrakenPaperOrdersManager) ExecutedOrder(_ context.Context, _ int, cliOrdID string) (models.Order, error) {
+	return models.Order{}, fmt.Errorf("%s: %w: %s", ErrPaperExecutedOrder, ErrOrderNotFound, cliOrdID)
+}
+
+// RateLimitUsage is empty, paper orders never reach kraken
+func (k *KrakenPaperOrdersManager) RateLimitUsage() krakenFuturesSDK.RateLimitUsage {
+	return krakenFuturesSDK.RateLimitUsage{}
+}
+
+func (k *KrakenPaperOrdersManager) ParseBatchStatusToOrders(userID int, statuses []krakenFuturesSDK.BatchStatus) []models.Order {
+	orders := parseBatchStatusToOrders(userID, statuses)
+	for i := range orders {
+		orders[i].Paper = true
+	}
+	return orders
+}
+
+func (k *KrakenPaperOrdersManager) ParseSendStatusToExecutedOrder(userID int, sendStatus krakenFuturesSDK.SendStatus) (models.Order, error) {
+	order, err := parseSendStatusToExecutedOrder(userID, sendStatus)
+	if err != nil {
+		return models.Order{}, err
+	}
+
+	order.Paper = true
+	return order, nil
+}
+
+// Account returns snapshot of virtual account, positions are marked to the last seen price
+func (k *KrakenPaperOrdersManager) Account() models.PaperAccount {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	account := models.PaperAccount{
+		UserID:      k.userID,
+		Balance:     k.balance,
+		RealizedPnL: k.realizedPnL,
+		Fees:        k.fees,
+		Positions:   make([]models.PaperPosition, 0, len(k.positions)),
+	}
+
+	for symbol, position := range k.positions {
+		paperPosition := models.PaperPosition{
+			Symbol:     symbol,
+			Size:       position.size,
+			EntryPrice: position.entryPrice,
+		}
+		if price, ok := k.prices.CachedPrice(symbol); ok {
+			paperPosition.MarkPrice = price
+			paperPosition.UnrealizedPnL = price.Sub(position.entryPrice).Mul(position.size)
+		}
+		account.Positions = append(account.Positions, paperPosition)
+	}
+	sort.Slice(account.Positions, func(i, j int) bool {
+		return account.Positions[i].Symbol < account.Positions[j].Symbol
+	})
+
+	return account
+}
+
+func orderDirection(args krakenFuturesSDK.SendOrderArguments) (decimal.Decimal, error) {
+	if !args.Size.IsPositive() {
+		return decimal.Zero, ErrInvalidOrderSize
+	}
+
+	switch args.Side {
+	case krakenFuturesSDK.BuySide:
+		return decimal.NewFromInt(1), nil
+	case krakenFuturesSDK.SellSide:
+		return decimal.NewFromInt(-1), nil
+	default:
+		return decimal.Zero, fmt.Errorf("%s: %s", ErrInvalidOrderSide, args.Side)
+	}
+}
+
+// newPaperSendStatus builds send status of immediately executed order the same way kraken does
+func newPaperSendStatus(args krakenFuturesSDK.SendOrderArguments, price decimal.Decimal) (krakenFuturesSDK.SendStatus, error) {
+	orderID, err := uuid.NewV4()
+	if err != nil {
+		return krakenFuturesSDK.SendStatus{}, fmt.Errorf("%s: %w", ErrPaperSendOrder, err)
+	}
+	executionID, err := uuid.NewV4()
+	if err != nil {
+		return krakenFuturesSDK.SendStatus{}, fmt.Errorf("%s: %w", ErrPaperSendOrder, err)
+	}
+
+	now := time.Now().UTC().Format(krakenTimeLayout)
+
+	return krakenFuturesSDK.SendStatus{
+		OrderID:      orderID.String(),
+		CliOrderID:   args.CliOrderID,
+		Status:       placedStatus,
+		ReceivedTime: now,
+		OrderEvents: []krakenFuturesSDK.OrderEvent{
+			{
+				Type:        executionEventType,
+				Price:       price,
+				Amount:      args.Size,
+				ExecutionID: executionID.String(),
+				OrderPriorExecution: krakenFuturesSDK.Order{
+					OrderID:             orderID.String(),
+					CliOrderID:          args.CliOrderID,
+					ReduceOnly:          args.ReduceOnly,
+					Symbol:              args.Symbol,
+					Quantity:            args.Size,
+					Side:                args.Side,
+					LimitPrice:          args.LimitPrice,
+					Type:                args.OrderType,
+					Timestamp:           now,
+					LastUpdateTimestamp: now,
+				},
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,198 @@
+package webKraken
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/internal/pkg/models"
+	"trade-bot/pkg/krakenFuturesSDK"
+)
+
+type fakePrices map[string]decimal.Decimal
+
+func (f fakePrices) LastPrice(_ context.Context, symbol string) (decimal.Decimal, error) {
+	price, ok := f[symbol]
+	if !ok {
+		return decimal.Zero, ErrNoMarketPrice
+	}
+	return price, nil
+}
+
+func (f fakePrices) CachedPrice(symbol string) (decimal.Decimal, bool) {
+	price, ok := f[symbol]
+	return price, ok
+}
+
+func TestKrakenPaperOrdersManager_SendOrder(t *testing.T) {
+	d := decimal.RequireFromString
+
+	type order struct {
+		args  krakenFuturesSDK.SendOrderArguments
+		price string
+	}
+
+	tests := []struct {
+		name    string
+		orders  []order
+		want    models.PaperAccount
+		wantErr bool
+	}{
+		{
+			name: "Open and close long",
+			orders: []order{
+				{args: krakenFuturesSDK.SendOrderArguments{OrderType: "mkt", Symbol: "pi_xbtusd", Side: "buy", Size: d("2")}, price: "100"},
+				{args: krakenFuturesSDK.SendOrderArguments{OrderType: "mkt", Symbol: "pi_xbtusd", Side: "sell", Size: d("2")}, price: "110"},
+			},
+			want: models.PaperAccount{UserID: 1, Balance: d("1019.58"), RealizedPnL: d("20"), Fees: d("0.42"), Positions: []models.PaperPosition{}},
+		},
+		{
+			name: "Average entry and flip to short",
+			orders: []order{
+				{args: krakenFuturesSDK.SendOrderArguments{OrderType: "mkt", Symbol: "pi_xbtusd", Side: "buy", Size: d("1")}, price: "100"},
+				{args: krakenFuturesSDK.SendOrderArguments{OrderType: "mkt", Symbol: "pi_xbtusd", Side: "buy", Size: d("1")}, price: "120"},
+				{args: krakenFuturesSDK.SendOrderArguments{OrderType: "mkt", Symbol: "pi_xbtusd", Side: "sell", Size: d("3")}, price: "100"},
+			},
+			want: models.PaperAccount{UserID: 1, Balance: d("979.48"), RealizedPnL: d("-20"), Fees: d("0.52"), Positions: []models.PaperPosition{
+				{Symbol: "pi_xbtusd", Size: d("-1"), EntryPrice: d("100"), MarkPrice: d("100"), UnrealizedPnL: d("0")},
+			}},
+		},
+		{
+			name: "Fractional contracts at fractional price",
+			orders: []order{
+				{args: krakenFuturesSDK.SendOrderArguments{OrderType: "mkt", Symbol: "pf_xbtusd", Side: "buy", Size: d("0.0015")}, price: "41234.5"},
+				{args: krakenFuturesSDK.SendOrderArguments{OrderType: "mkt", Symbol: "pf_xbtusd", Side: "sell", Size: d("0.0005")}, price: "41300.1"},
+			},
+			want: models.PaperAccount{UserID: 1, Balance: d("999.9502982"), RealizedPnL: d("0.0328"), Fees: d("0.0825018"), Positions: []models.PaperPosition{
+				{Symbol: "pf_xbtusd", Size: d("0.001"), EntryPrice: d("41234.5"), MarkPrice: d("41300.1"), UnrealizedPnL: d("0.0656")},
+			}},
+		},
+		{
+			name: "Marketable limit order",
+			orders: []order{
+				{args: krakenFuturesSDK.SendOrderArguments{OrderType: "lmt", Symbol: "pi_xbtusd", Side: "sell", Size: d("1"), LimitPrice: d("90")}, price: "100"},
+			},
+			want: models.PaperAccount{UserID: 1, Balance: d("999.9"), Fees: d("0.1"), Positions: []models.PaperPosition{
+				{Symbol: "pi_xbtusd", Size: d("-1"), EntryPrice: d("100"), MarkPrice: d("100")},
+			}},
+		},
+		{
+			name: "Not marketable limit order",
+			orders: []order{
+				{args: krakenFuturesSDK.SendOrderArguments{OrderType: "lmt", Symbol: "pi_xbtusd", Side: "buy", Size: d("1"), LimitPrice: d("90")}, price: "100"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Reduce only order without position",
+			orders: []order{
+				{args: krakenFuturesSDK.SendOrderArguments{OrderType: "mkt", Symbol: "pi_xbtusd", Side: "sell", Size: d("1"), ReduceOnly: true}, price: "100"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Balance can't cover order",
+			orders: []order{
+				{args: krakenFuturesSDK.SendOrderArguments{OrderType: "mkt", Symbol: "pi_xbtusd", Side: "buy", Size: d("10")}, price: "100"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Unsupported order type",
+			orders: []order{
+				{args: krakenFuturesSDK.SendOrderArguments{OrderType: "stp", Symbol: "pi_xbtusd", Side: "buy", Size: d("1")}, price: "100"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "No market price",
+			orders: []order{
+				{args: krakenFuturesSDK.SendOrderArguments{OrderType: "mkt", Symbol: "pi_ethusd", Side: "buy", Size: d("1")}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			prices := fakePrices{}
+			manager := NewKrakenPaperOrdersManager(1, prices, d("1000"), d("0.001"))
+
+			var err error
+			for _, o := range test.orders {
+				if o.price != "" {
+					prices[o.args.Symbol] = d(o.price)
+				}
+
+				var status krakenFuturesSDK.SendStatus
+				status, err = manager.SendOrder(context.Background(), o.args)
+				if err != nil {
+					break
+				}
+
+				order, parseErr := manager.ParseSendStatusToExecutedOrder(1, status)
+				assert.NoError(t, parseErr)
+				assert.True(t, order.Paper)
+				assert.Equal(t, o.price, order.Price.String())
+				assert.Equal(t, o.args.Size.String(), order.Quantity.String())
+				assert.Equal(t, o.args.Side, order.Side)
+			}
+
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			account := manager.Account()
+			assert.Equal(t, test.want.UserID, account.UserID)
+			assertDecimal(t, test.want.Balance, account.Balance)
+			assertDecimal(t, test.want.RealizedPnL, account.RealizedPnL)
+			assertDecimal(t, test.want.Fees, account.Fees)
+			if assert.Len(t, account.Positions, len(test.want.Positions)) {
+				for i, want := range test.want.Positions {
+					got := account.Positions[i]
+					assert.Equal(t, want.Symbol, got.Symbol)
+					assertDecimal(t, want.Size, got.Size)
+					assertDecimal(t, want.EntryPrice, got.EntryPrice)
+					assertDecimal(t, want.MarkPrice, got.MarkPrice)
+					assertDecimal(t, want.UnrealizedPnL, got.UnrealizedPnL)
+				}
+			}
+		})
+	}
+}
+
+// assertDecimal compares values of decimals, their exponents may differ
+func assertDecimal(t *testing.T, want, got decimal.Decimal) {
+	t.Helper()
+	assert.True(t, want.Equal(got), "want %s, got %s", want, got)
+}
+
+func TestKrakenPaperOrdersManager_SendOrder_InsufficientFunds(t *testing.T) {
+	d := decimal.RequireFromString
+	prices := fakePrices{"pi_xbtusd": d("100"), "pi_ethusd": d("10")}
+	manager := NewKrakenPaperOrdersManager(1, prices, d("1000"), d("0.001"))
+	send := func(symbol, side, size string) error {
+		_, err := manager.SendOrder(context.Background(), krakenFuturesSDK.SendOrderArguments{
+			OrderType: "mkt", Symbol: symbol, Side: side, Size: d(size)})
+		return err
+	}
+
+	assert.NoError(t, send("pi_xbtusd", "buy", "9"))
+
+	// margin of open position is not available for other orders
+	err := send("pi_ethusd", "buy", "10")
+	var apiErr *krakenFuturesSDK.APIError
+	if assert.True(t, errors.As(err, &apiErr)) {
+		assert.Equal(t, krakenFuturesSDK.KindInsufficientFunds, apiErr.Kind())
+	}
+	assert.Error(t, send("pi_xbtusd", "sell", "19"))
+
+	// order which reduces position does not need margin
+	assert.NoError(t, send("pi_xbtusd", "sell", "9"))
+	assert.NoError(t, send("pi_ethusd", "buy", "10"))
+}
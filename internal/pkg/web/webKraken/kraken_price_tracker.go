@@ -0,0 +1,123 @@
+package webKraken
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+
+	"trade-bot/pkg/krakenFuturesWSSDK"
+)
+
+var (
+	ErrNoMarketPrice = errors.New("no market price for symbol")
+)
+
+const defaultPriceWaitTimeout = 10 * time.Second
+
+type tickerSource interface {
+	LookForTicker(ctx context.Context, productsIDs []string) (<-chan *krakenFuturesWSSDK.TickerData, error)
+}
+
+// KrakenPriceTracker keeps the last traded price of every symbol it was asked about, mark price is used
+// until symbol is traded. Symbol is subscribed to ticker feed on first request and stays subscribed.
+// Symbols are upper case as kraken sends them, so symbol is found whatever case it is asked in
+type KrakenPriceTracker struct {
+	ticker      tickerSource
+	waitTimeout time.Duration
+
+	mu      sync.Mutex
+	prices  map[string]decimal.Decimal
+	tracked map[string]chan struct{}
+}
+
+func NewKrakenPriceTracker(ticker tickerSource) *KrakenPriceTracker {
+	return &KrakenPriceTracker{
+		ticker:      ticker,
+		waitTimeout: defaultPriceWaitTimeout,
+		prices:      make(map[string]decimal.Decimal),
+		tracked:     make(map[string]chan struct{}),
+	}
+}
+
+// LastPrice returns the last seen price of symbol, waiting for the first ticker if symbol isn't tracked yet
+func (t *KrakenPriceTracker) LastPrice(ctx context.Context, symbol string) (decimal.Decimal, error) {
+	symbol = strings.ToUpper(symbol)
+
+	t.mu.Lock()
+	ready, ok := t.tracked[symbol]
+	if !ok {
+		ready = make(chan struct{})
+		t.tracked[symbol] = ready
+		go t.track(symbol, ready)
+	}
+	price, known := t.prices[symbol]
+	t.mu.Unlock()
+
+	if known {
+		return price, nil
+	}
+
+	select {
+	case <-ready:
+	case <-ctx.Done():
+		return decimal.Zero, fmt.Errorf("%s: %s: %w", ErrNoMarketPrice, symbol, ctx.Err())
+	case <-time.After(t.waitTimeout):
+		return decimal.Zero, fmt.Errorf("%s: %s", ErrNoMarketPrice, symbol)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if price, ok := t.prices[symbol]; ok {
+		return price, nil
+	}
+	return decimal.Zero, fmt.Errorf("%s: %s", ErrNoMarketPrice, symbol)
+}
+
+// CachedPrice returns the last seen price of symbol without subscribing to it
+func (t *KrakenPriceTracker) CachedPrice(symbol string) (decimal.Decimal, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	price, ok := t.prices[strings.ToUpper(symbol)]
+	return price, ok
+}
+
+// track updates price of symbol until ticker feed is closed, then symbol is resubscribed on next request
+func (t *KrakenPriceTracker) track(symbol string, ready chan struct{}) {
+	var once sync.Once
+	markReady := func() { once.Do(func() { close(ready) }) }
+
+	defer func() {
+		t.mu.Lock()
+		delete(t.tracked, symbol)
+		t.mu.Unlock()
+		markReady()
+	}()
+
+	tickers, err := t.ticker.LookForTicker(context.Background(), []string{symbol})
+	if err != nil {
+		log.Warnf("%s: %s: %s", ErrNoMarketPrice, symbol, err)
+		return
+	}
+
+	for ticker := range tickers {
+		price := ticker.Last
+		if price.IsZero() {
+			price = ticker.MarkPrice
+		}
+		if !price.IsPositive() {
+			continue
+		}
+
+		t.mu.Lock()
+		t.prices[symbol] = price
+		t.mu.Unlock()
+		markReady()
+	}
+}
@@ -0,0 +1,59 @@
+package webKraken
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/pkg/krakenFuturesWSSDK"
+)
+
+type fakeTicker chan *krakenFuturesWSSDK.TickerData
+
+func (f fakeTicker) LookForTicker(_ context.Context, _ []string) (<-chan *krakenFuturesWSSDK.TickerData, error) {
+	return f, nil
+}
+
+func TestKrakenPriceTracker_LastPrice(t *testing.T) {
+	ticker := make(fakeTicker, 3)
+	// mark price is used until product is traded, then the last traded price is used
+	ticker <- &krakenFuturesWSSDK.TickerData{ProductID: "PI_XBTUSD", MarkPrice: decimal.RequireFromString("100.5")}
+	tracker := NewKrakenPriceTracker(ticker)
+
+	price, err := tracker.LastPrice(context.Background(), "PI_XBTUSD")
+	assert.NoError(t, err)
+	assert.Equal(t, "100.5", price.String())
+
+	ticker <- &krakenFuturesWSSDK.TickerData{ProductID: "PI_XBTUSD", Last: decimal.RequireFromString("101.25"), MarkPrice: decimal.RequireFromString("101")}
+	close(ticker)
+	assert.Eventually(t, func() bool {
+		price, ok := tracker.CachedPrice("PI_XBTUSD")
+		return ok && price.String() == "101.25"
+	}, time.Second, time.Millisecond)
+}
+
+func TestKrakenPriceTracker_LastPrice_LowerCaseSymbol(t *testing.T) {
+	ticker := make(fakeTicker, 1)
+	ticker <- &krakenFuturesWSSDK.TickerData{ProductID: "PI_XBTUSD", Last: decimal.RequireFromString("100")}
+	tracker := NewKrakenPriceTracker(ticker)
+
+	price, err := tracker.LastPrice(context.Background(), "pi_xbtusd")
+	assert.NoError(t, err)
+	assert.Equal(t, "100", price.String())
+
+	price, ok := tracker.CachedPrice("PI_XBTUSD")
+	assert.True(t, ok)
+	assert.Equal(t, "100", price.String())
+}
+
+func TestKrakenPriceTracker_LastPrice_CtxDone(t *testing.T) {
+	tracker := NewKrakenPriceTracker(make(fakeTicker))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := tracker.LastPrice(ctx, "PI_XBTUSD")
+	assert.ErrorIs(t, err, context.Canceled)
+}
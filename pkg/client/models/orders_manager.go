@@ -3,29 +3,31 @@ package models
 import (
 	"fmt"
 	"time"
+
+	"github.com/shopspring/decimal"
 )
 
 type SendOrderInput struct {
-	OrderType string `json:"order_type"`
-	Symbol    string `json:"symbol"`
-	Side      string `json:"side"`
-	Size      uint   `json:"size"`
+	OrderType string          `json:"order_type"`
+	Symbol    string          `json:"symbol"`
+	Side      string          `json:"side"`
+	Size      decimal.Decimal `json:"size"`
 	JWTToken  string
 }
 
 type SendOrderResponse struct {
-	ID                  string    `json:"id"`
-	UserID              int       `json:"user_id"`
-	ClientOrderID       string    `json:"client_order_id"`
-	Type                string    `json:"type"`
-	Symbol              string    `json:"symbol"`
-	Quantity            int       `json:"quantity"`
-	Side                string    `json:"side"`
-	Filled              int       `json:"filled"`
-	Timestamp           time.Time `json:"timestamp"`
-	LastUpdateTimestamp time.Time `json:"last_update_timestamp"`
-	Price               float64   `json:"price"`
-	Message             string    `json:"message,omitempty"`
+	ID                  string          `json:"id"`
+	UserID              int             `json:"user_id"`
+	ClientOrderID       string          `json:"client_order_id"`
+	Type                string          `json:"type"`
+	Symbol              string          `json:"symbol"`
+	Quantity            decimal.Decimal `json:"quantity"`
+	Side                string          `json:"side"`
+	Filled              decimal.Decimal `json:"filled"`
+	Timestamp           time.Time       `json:"timestamp"`
+	LastUpdateTimestamp time.Time       `json:"last_update_timestamp"`
+	Price               decimal.Decimal `json:"price"`
+	Message             string          `json:"message,omitempty"`
 }
 
 func (r *SendOrderResponse) String() string {
@@ -37,11 +39,11 @@ func (r *SendOrderResponse) String() string {
 		order_id:   %s,
 		type:       %s,
 		symbol:     %s,
-		quantity:   %d,
+		quantity:   %s,
 		side:       %s,
-		filled:     %d,
+		filled:     %s,
 		timestamp:  %s,
-		price:      %f,
+		price:      %s,
 	`, r.ID, r.Type, r.Symbol, r.Quantity, r.Side, r.Filled, r.Timestamp, r.Price)
 }
 
@@ -51,14 +53,43 @@ type StartTradingInput struct {
 	JWTToken       string
 }
 
+const StopLossTakeProfitStrategy = "stop_loss_take_profit"
+
 type StartTradingDetails struct {
 	SendOrderInput
-	StopLossBorder   uint `json:"stop_loss_border"`
-	TakeProfitBorder uint `json:"take_profit_border"`
+	Strategy string                 `json:"strategy"`
+	Params   map[string]interface{} `json:"params"`
+}
+
+const (
+	TradingSessionExitOrderEvent = "exit_order"
+	TradingSessionFailedState    = "failed"
+)
+
+type TradingSession struct {
+	ID    string `json:"id"`
+	State string `json:"state"`
+	Error string `json:"error,omitempty"`
 }
 
 type StartTradingResponse struct {
-	SendOrderResponse
+	Event   string         `json:"event"`
+	Session TradingSession `json:"session"`
+	Order   Order          `json:"order"`
+	Message string         `json:"message,omitempty"`
+}
+
+func (r *StartTradingResponse) String() string {
+	if r.Message != "" {
+		return fmt.Sprintf("Message: %s", r.Message)
+	}
+	if r.Session.Error != "" {
+		return fmt.Sprintf("Session %s %s: %s", r.Session.ID, r.Session.State, r.Session.Error)
+	}
+	if r.Event == TradingSessionExitOrderEvent {
+		return r.Order.String()
+	}
+	return fmt.Sprintf("Session %s: %s", r.Session.ID, r.Session.State)
 }
 
 type GetUserOrdersInput struct {
@@ -83,17 +114,17 @@ func (r *GetUserOrdersResponse) String() string {
 }
 
 type Order struct {
-	ID                  string    `json:"id"`
-	UserID              int       `json:"user_id"`
-	ClientOrderID       string    `json:"client_order_id"`
-	Type                string    `json:"type"`
-	Symbol              string    `json:"symbol"`
-	Quantity            int       `json:"quantity"`
-	Side                string    `json:"side"`
-	Filled              int       `json:"filled"`
-	Timestamp           time.Time `json:"timestamp"`
-	LastUpdateTimestamp time.Time `json:"last_update_timestamp"`
-	Price               float64   `json:"price"`
+	ID                  string          `json:"id"`
+	UserID              int             `json:"user_id"`
+	ClientOrderID       string          `json:"client_order_id"`
+	Type                string          `json:"type"`
+	Symbol              string          `json:"symbol"`
+	Quantity            decimal.Decimal `json:"quantity"`
+	Side                string          `json:"side"`
+	Filled              decimal.Decimal `json:"filled"`
+	Timestamp           time.Time       `json:"timestamp"`
+	LastUpdateTimestamp time.Time       `json:"last_update_timestamp"`
+	Price               decimal.Decimal `json:"price"`
 }
 
 func (o *Order) String() string {
@@ -101,10 +132,10 @@ func (o *Order) String() string {
 		order_id:   %s,
 		type:       %s,
 		symbol:     %s,
-		quantity:   %d,
+		quantity:   %s,
 		side:       %s,
-		filled:     %d,
+		filled:     %s,
 		timestamp:  %s,
-		price:      %f,
+		price:      %s,
 	`, o.ID, o.Type, o.Symbol, o.Quantity, o.Side, o.Filled, o.Timestamp, o.Price)
 }
@@ -0,0 +1,121 @@
+package krakenFuturesSDK
+
+import "fmt"
+
+// ErrorKind groups kraken error codes and statuses by what caller can do about them
+type ErrorKind string
+
+const (
+	// KindTransient errors may disappear if request is repeated later
+	KindTransient         ErrorKind = "transient"
+	KindRateLimited       ErrorKind = "rate_limited"
+	KindAuthentication    ErrorKind = "authentication"
+	KindInsufficientFunds ErrorKind = "insufficient_funds"
+	KindInvalidArgument   ErrorKind = "invalid_argument"
+	KindMarketClosed      ErrorKind = "market_closed"
+	KindNotFound          ErrorKind = "not_found"
+	// KindRejected is order which is valid but can't be executed now, like post only order which would execute
+	KindRejected ErrorKind = "rejected"
+	KindUnknown  ErrorKind = "unknown"
+)
+
+const (
+	// CodeServerUnavailable is code of APIError made from http status of response, kraken does not send it
+	CodeServerUnavailable = "serverUnavailable"
+	// CodeAPILimitExceeded is sent by kraken, APIError made from http status 429 has it too
+	CodeAPILimitExceeded = "apiLimitExceeded"
+	// CodeClientOrderIDAlreadyExist is status of order which repeats cliOrdId of order already sent to kraken
+	CodeClientOrderIDAlreadyExist = "clientOrderIdAlreadyExist"
+)
+
+var errorKinds = map[string]ErrorKind{
+	// error field of response
+	CodeAPILimitExceeded:      KindRateLimited,
+	"authenticationError":     KindAuthentication,
+	"accountInactive":         KindAuthentication,
+	"nonceBelowThreshold":     KindTransient,
+	"nonceDuplicate":          KindTransient,
+	"Server Error":            KindTransient,
+	"Unavailable":             KindTransient,
+	CodeServerUnavailable:     KindTransient,
+	"requiredArgumentMissing": KindInvalidArgument,
+	"invalidArgument":         KindInvalidArgument,
+	"invalidAmount":           KindInvalidArgument,
+	"invalidUnit":             KindInvalidArgument,
+	"insufficientFunds":       KindInsufficientFunds,
+	"marketUnavailable":       KindMarketClosed,
+
+	// statuses of send, edit and cancel
+	"insufficientAvailableFunds":  KindInsufficientFunds,
+	"invalidOrderType":            KindInvalidArgument,
+	"invalidSide":                 KindInvalidArgument,
+	"invalidSize":                 KindInvalidArgument,
+	"invalidPrice":                KindInvalidArgument,
+	CodeClientOrderIDAlreadyExist: KindInvalidArgument,
+	"clientOrderIdTooLong":        KindInvalidArgument,
+	"tooManySmallOrders":          KindInvalidArgument,
+	"maxPositionViolation":        KindInvalidArgument,
+	"orderForEditNotAStop":        KindInvalidArgument,
+	"marketSuspended":             KindMarketClosed,
+	"marketInactive":              KindMarketClosed,
+	"notFound":                    KindNotFound,
+	"orderForEditNotFound":        KindNotFound,
+	"noOrdersToCancel":            KindNotFound,
+	"filled":                      KindRejected,
+	"selfFill":                    KindRejected,
+	"outsidePriceCollar":          KindRejected,
+	"postWouldExecute":            KindRejected,
+	"iocWouldNotExecute":          KindRejected,
+	"wouldCauseLiquidation":       KindRejected,
+	"wouldNotReducePosition":      KindRejected,
+}
+
+// APIError is error reported by kraken: error of response or unsuccessful status of order.
+// It is inspectable with errors.As through all layers wrapping it
+type APIError struct {
+	// Code is kraken error code or status
+	Code string
+	// IsStatus is true when Code is status of send, edit or cancel rather than error of request
+	IsStatus   bool
+	Result     string
+	ServerTime string
+	// HTTPStatus is set when error is made from http status of response
+	HTTPStatus int
+}
+
+func (e *APIError) Error() string {
+	switch {
+	case e.IsStatus:
+		return fmt.Sprintf("kraken: invalid status: %s", e.Code)
+	case e.HTTPStatus != 0:
+		return fmt.Sprintf("kraken: %s: http status %d", e.Code, e.HTTPStatus)
+	default:
+		return fmt.Sprintf("kraken: err: %s, server time: %s, result: %s", e.Code, e.ServerTime, e.Result)
+	}
+}
+
+func (e *APIError) Kind() ErrorKind {
+	if kind, ok := errorKinds[e.Code]; ok {
+		return kind
+	}
+	return KindUnknown
+}
+
+// Temporary reports whether the same request may succeed later
+func (e *APIError) Temporary() bool {
+	kind := e.Kind()
+	return kind == KindTransient || kind == KindRateLimited
+}
+
+// Err returns error of response or nil if kraken did not report it
+func (r KrakenErrorResponse) Err() error {
+	if r.Error == "" {
+		return nil
+	}
+	return &APIError{Code: r.Error, Result: r.Result, ServerTime: r.ServerTime}
+}
+
+// NewStatusError makes error of unsuccessful status of send, edit or cancel
+func NewStatusError(status string) *APIError {
+	return &APIError{Code: status, IsStatus: true}
+}
@@ -0,0 +1,70 @@
+package krakenFuturesSDK
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIError_Kind(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           *APIError
+		wantKind      ErrorKind
+		wantTemporary bool
+	}{
+		{
+			name:          "Rate limited",
+			err:           &APIError{Code: "apiLimitExceeded", HTTPStatus: http.StatusTooManyRequests},
+			wantKind:      KindRateLimited,
+			wantTemporary: true,
+		},
+		{
+			name:          "Server unavailable",
+			err:           &APIError{Code: CodeServerUnavailable, HTTPStatus: http.StatusBadGateway},
+			wantKind:      KindTransient,
+			wantTemporary: true,
+		},
+		{
+			name:     "Insufficient funds status",
+			err:      NewStatusError("insufficientAvailableFunds"),
+			wantKind: KindInsufficientFunds,
+		},
+		{
+			name:     "Post only order would execute",
+			err:      NewStatusError("postWouldExecute"),
+			wantKind: KindRejected,
+		},
+		{
+			name:     "Unknown code",
+			err:      &APIError{Code: "somethingNew"},
+			wantKind: KindUnknown,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.wantKind, test.err.Kind())
+			assert.Equal(t, test.wantTemporary, test.err.Temporary())
+		})
+	}
+}
+
+func TestKrakenErrorResponse_Err(t *testing.T) {
+	assert.NoError(t, KrakenErrorResponse{Result: "success"}.Err())
+
+	err := KrakenErrorResponse{Result: "error", Error: "marketUnavailable", ServerTime: "2022-01-01T00:00:00.000Z"}.Err()
+	wrapped := fmt.Errorf("%s: %w", errors.New("send order"), err)
+
+	var apiErr *APIError
+	if assert.True(t, errors.As(wrapped, &apiErr)) {
+		assert.Equal(t, "marketUnavailable", apiErr.Code)
+		assert.Equal(t, KindMarketClosed, apiErr.Kind())
+		assert.False(t, apiErr.IsStatus)
+	}
+	assert.Equal(t, "send order: kraken: err: marketUnavailable, server time: 2022-01-01T00:00:00.000Z, result: error",
+		wrapped.Error())
+}
@@ -1,25 +1,32 @@
 package krakenFuturesSDK
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"crypto/sha512"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"mime"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
+
+	"trade-bot/pkg/krakenFuturesWSSDK"
 )
 
 var (
 	ErrDoRequest                = errors.New("do request")
 	ErrNilTyp                   = errors.New("nil typ")
+	ErrCreateSignature          = errors.New("create signature")
+	ErrDecodePrivateKey         = errors.New("unable to decode private api key")
 	ErrCouldNotCreateRequest    = errors.New("could not create request")
 	ErrCouldNotExecuteRequest   = errors.New("could not execute request")
 	ErrCouldNotReadBody         = errors.New("could not read body")
@@ -28,10 +35,19 @@ var (
 	ErrCouldNotUnmarshalBody    = errors.New("could not unmarshal body")
 	ErrValidateSendStatus       = errors.New("validate send status")
 	ErrEmptyOrderEvents         = errors.New("empty order events")
+	ErrBatchOrder               = errors.New("batch order")
+	ErrEmptyBatch               = errors.New("empty batch")
+	ErrUnknownBatchInstruction  = errors.New("unknown batch instruction")
+	ErrInvalidCandlesArguments  = errors.New("invalid candles arguments")
+	ErrCandles                  = errors.New("candles")
 )
 
 const (
 	apiUserAgent = "Kraken GO API Agent"
+
+	defaultTimeout = 10 * time.Second
+
+	formContentType = "application/x-www-form-urlencoded"
 )
 
 type API struct {
@@ -39,71 +55,163 @@ type API struct {
 	apiPrivateKey string
 	apiURL        string
 	client        *http.Client
+	timeout       time.Duration
+	userAgent     string
+	retry         RetryPolicy
+	rateLimit     RateLimit
+	limiter       *tokenBucket
+	nonces        *nonceGenerator
 }
 
-func NewAPI(apiPublicKey, apiPrivateKey string, apiURL string) *API {
-	return &API{
+func NewAPI(apiPublicKey, apiPrivateKey string, apiURL string, opts ...Option) *API {
+	api := &API{
 		apiPublicKey:  apiPublicKey,
 		apiPrivateKey: apiPrivateKey,
 		apiURL:        apiURL,
-		client:        http.DefaultClient,
+		client:        &http.Client{},
+		timeout:       defaultTimeout,
+		userAgent:     apiUserAgent,
+		retry:         DefaultRetryPolicy,
+		rateLimit:     DefaultRateLimit,
+		nonces:        nonceGeneratorFor(apiPublicKey),
+	}
+
+	for _, opt := range opts {
+		opt(api)
 	}
+	api.limiter = rateLimiterFor(apiPublicKey, api.rateLimit)
+	return api
+}
+
+// RateLimitUsage returns state of budget of private endpoints shared by all clients of api key
+func (a *API) RateLimitUsage() RateLimitUsage {
+	return a.limiter.usage()
 }
 
 // -------------------------- PUBLIC KRAKEN API ENDPOINTS -------------------------- //
 
-func (a *API) FeeSchedules() (*FeeSchedulesResponse, error) {
-	resp, err := a.queryPublic(http.MethodGet, "/derivatives/api/v3/feeschedules", nil, &FeeSchedulesResponse{})
+func (a *API) FeeSchedules(ctx context.Context) (*FeeSchedulesResponse, error) {
+	resp, err := a.queryPublic(ctx, http.MethodGet, "/derivatives/api/v3/feeschedules", nil, &FeeSchedulesResponse{})
 	if err != nil {
 		return nil, err
 	}
 	return resp.(*FeeSchedulesResponse), nil
 }
 
-func (a *API) OrderBook(symbol string) (*OrderBookResponse, error) {
+func (a *API) OrderBook(ctx context.Context, symbol string) (*OrderBookResponse, error) {
 	values := url.Values{}
 	values.Add("symbol", symbol)
-	resp, err := a.queryPublic(http.MethodGet, "/derivatives/api/v3/orderbook", values, &OrderBookResponse{})
+	resp, err := a.queryPublic(ctx, http.MethodGet, "/derivatives/api/v3/orderbook", values, &OrderBookResponse{})
 	if err != nil {
 		return nil, err
 	}
 	return resp.(*OrderBookResponse), nil
 }
 
-func (a *API) Tickers() (*TickersResponse, error) {
-	resp, err := a.queryPublic(http.MethodGet, "/derivatives/api/v3/tickers", nil, &TickersResponse{})
+func (a *API) Tickers(ctx context.Context) (*TickersResponse, error) {
+	resp, err := a.queryPublic(ctx, http.MethodGet, "/derivatives/api/v3/tickers", nil, &TickersResponse{})
 	if err != nil {
 		return nil, err
 	}
 	return resp.(*TickersResponse), nil
 }
 
-func (a *API) Instruments() (*InstrumentsResponse, error) {
-	resp, err := a.queryPublic(http.MethodGet, "/derivatives/api/v3/instruments", nil, &InstrumentsResponse{})
+func (a *API) Instruments(ctx context.Context) (*InstrumentsResponse, error) {
+	resp, err := a.queryPublic(ctx, http.MethodGet, "/derivatives/api/v3/instruments", nil, &InstrumentsResponse{})
 	if err != nil {
 		return nil, err
 	}
 	return resp.(*InstrumentsResponse), nil
 }
 
+// HistoricalFunding returns funding rates of perpetual contract, the most recent rate is the last one
+func (a *API) HistoricalFunding(ctx context.Context, symbol string) (*HistoricalFundingResponse, error) {
+	values := url.Values{}
+	values.Add("symbol", symbol)
+	resp, err := a.queryPublic(ctx, http.MethodGet, "/derivatives/api/v3/historicalfundingrates", values, &HistoricalFundingResponse{})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*HistoricalFundingResponse), nil
+}
+
+// CandlesPage returns the first page of candles in range, kraken limits number of candles in one response
+func (a *API) CandlesPage(ctx context.Context, args CandlesArguments) (*CandlesResponse, error) {
+	if err := args.validate(); err != nil {
+		return nil, err
+	}
+
+	to := args.To
+	if to.IsZero() {
+		to = time.Now()
+	}
+
+	values := url.Values{}
+	values.Add("from", strconv.FormatInt(args.From.Unix(), 10))
+	values.Add("to", strconv.FormatInt(to.Unix(), 10))
+	endpoint := fmt.Sprintf("/api/charts/v1/%s/%s/%s", args.TickType, url.PathEscape(args.Symbol), args.Resolution)
+	resp, err := a.queryPublic(ctx, http.MethodGet, endpoint, values, &CandlesResponse{})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*CandlesResponse), nil
+}
+
+// Candles returns all candles in range sorted by time, pages are requested until kraken has no more candles.
+// Candles are the same as candles of websocket feed, except their Time is in seconds
+func (a *API) Candles(ctx context.Context, args CandlesArguments) ([]krakenFuturesWSSDK.Candle, error) {
+	if args.To.IsZero() {
+		args.To = time.Now()
+	}
+
+	var candles []krakenFuturesWSSDK.Candle
+	for {
+		page, err := a.CandlesPage(ctx, args)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", ErrCandles, err)
+		}
+
+		for _, chartCandle := range page.Candles {
+			candle := chartCandle.toCandle()
+			// pages may overlap and the last page may end after range
+			if len(candles) > 0 && candle.Time <= candles[len(candles)-1].Time ||
+				!time.Unix(int64(candle.Time), 0).Before(args.To) {
+				continue
+			}
+			candles = append(candles, candle)
+		}
+
+		if !page.MoreCandles || len(page.Candles) == 0 {
+			return candles, nil
+		}
+
+		// next page starts right after the last candle, kraken sets MoreCandles even if it is out of range
+		next := time.Unix(page.Candles[len(page.Candles)-1].Time/1000+1, 0)
+		if !next.After(args.From) || !next.Before(args.To) {
+			return candles, nil
+		}
+		args.From = next
+	}
+}
+
 // --------------------------------------------------------------------------------- //
 
 // -------------------------- PRIVATE KRAKEN API ENDPOINTS -------------------------- //
 
-func (a *API) SendOrder(args SendOrderArguments) (*SendOrderResponse, error) {
+func (a *API) SendOrder(ctx context.Context, args SendOrderArguments) (*SendOrderResponse, error) {
 	values := url.Values{}
 	values.Add("orderType", args.OrderType)
 	values.Add("symbol", args.Symbol)
 	values.Add("side", args.Side)
-	values.Add("size", strconv.Itoa(int(args.Size)))
+	values.Add("size", args.Size.String())
 
-	if args.LimitPrice != 0 {
-		values.Add("limitPrice", strconv.FormatFloat(args.LimitPrice, 'f', 2, 64))
+	if !args.LimitPrice.IsZero() {
+		values.Add("limitPrice", args.LimitPrice.String())
 	}
 
 	if args.OrderType == "stp" || args.OrderType == "take_profit" {
-		if args.StopPrice != 0 {
-			values.Add("stopPrice", strconv.FormatFloat(args.StopPrice, 'f', 2, 64))
+		if !args.StopPrice.IsZero() {
+			values.Add("stopPrice", args.StopPrice.String())
 		}
 		if args.TriggerSignal != "" {
 			values.Add("triggerSignal", args.TriggerSignal)
@@ -118,41 +226,46 @@ func (a *API) SendOrder(args SendOrderArguments) (*SendOrderResponse, error) {
 		values.Add("reduceOnly", "true")
 	}
 
-	resp, err := a.queryPrivate(http.MethodPost, "/derivatives/api/v3/sendorder", values, &SendOrderResponse{})
+	resp, err := a.queryPrivate(ctx, http.MethodPost, "/derivatives/api/v3/sendorder", values, args.CliOrderID != "", &SendOrderResponse{})
 	if err != nil {
 		return nil, err
 	}
 
-	if err := resp.(*SendOrderResponse).SendStatus.ValidateSendStatus(); err != nil {
-		return nil, fmt.Errorf("%s: send status - %s", err, resp.(*SendOrderResponse).SendStatus.Status)
+	// rejected order has no events, its status is checked by caller
+	response := resp.(*SendOrderResponse)
+	if response.Error == "" && response.SendStatus.Status.IsSuccessStatus() {
+		if err := response.SendStatus.ValidateSendStatus(); err != nil {
+			return nil, fmt.Errorf("%s: send status - %s", err, response.SendStatus.Status)
+		}
 	}
-	return resp.(*SendOrderResponse), nil
+	return response, nil
 }
 
-func (a *API) EditOrder(args EditOrderArguments) (*EditOrderResponse, error) {
+func (a *API) EditOrder(ctx context.Context, args EditOrderArguments) (*EditOrderResponse, error) {
 	values := url.Values{}
 	values.Add("orderId", args.OrderID)
-	if args.Size != 0 {
-		values.Add("size", strconv.Itoa(int(args.Size)))
+	if !args.Size.IsZero() {
+		values.Add("size", args.Size.String())
 	}
-	if args.LimitPrice != 0 {
-		values.Add("limitPrice", strconv.FormatFloat(args.LimitPrice, 'f', 2, 64))
+	if !args.LimitPrice.IsZero() {
+		values.Add("limitPrice", args.LimitPrice.String())
 	}
-	if args.StopPrice != 0 {
-		values.Add("stopPrice", strconv.FormatFloat(args.StopPrice, 'f', 2, 64))
+	if !args.StopPrice.IsZero() {
+		values.Add("stopPrice", args.StopPrice.String())
 	}
 	if args.CliOrdID != "" {
 		values.Add("cliOrdId", args.CliOrdID)
 	}
 
-	resp, err := a.queryPrivate(http.MethodPost, "/derivatives/api/v3/editorder", values, &EditOrderResponse{})
+	// edit sets absolute size and prices, so repeating it is safe
+	resp, err := a.queryPrivate(ctx, http.MethodPost, "/derivatives/api/v3/editorder", values, true, &EditOrderResponse{})
 	if err != nil {
 		return nil, err
 	}
 	return resp.(*EditOrderResponse), nil
 }
 
-func (a *API) CancelOrder(args CancelOrderArguments) (*CancelOrderResponse, error) {
+func (a *API) CancelOrder(ctx context.Context, args CancelOrderArguments) (*CancelOrderResponse, error) {
 	values := url.Values{}
 	if args.OrderID != "" {
 		values.Add("order_id", args.OrderID)
@@ -161,89 +274,256 @@ func (a *API) CancelOrder(args CancelOrderArguments) (*CancelOrderResponse, erro
 		values.Add("cliOrdId", args.CliOrdID)
 	}
 
-	resp, err := a.queryPrivate(http.MethodPost, "/derivatives/api/v3/cancelorder", values, &CancelOrderResponse{})
+	resp, err := a.queryPrivate(ctx, http.MethodPost, "/derivatives/api/v3/cancelorder", values, true, &CancelOrderResponse{})
 	if err != nil {
 		return nil, err
 	}
 	return resp.(*CancelOrderResponse), nil
 }
 
-func (a *API) CancelAllOrders(symbol string) (*CancelAllOrdersResponse, error) {
+func (a *API) CancelAllOrders(ctx context.Context, symbol string) (*CancelAllOrdersResponse, error) {
 	values := url.Values{}
 	if symbol != "" {
 		values.Add("symbol", symbol)
 	}
-	resp, err := a.queryPrivate(http.MethodPost, "/derivatives/api/v3/cancelallorders", values, &CancelAllOrdersResponse{})
+	resp, err := a.queryPrivate(ctx, http.MethodPost, "/derivatives/api/v3/cancelallorders", values, true, &CancelAllOrdersResponse{})
 	if err != nil {
 		return nil, err
 	}
 	return resp.(*CancelAllOrdersResponse), nil
 }
 
-// ---------------------------------------------------------------------------------- //
-
-func (s SendStatus) ValidateSendStatus() error {
-	if len(s.OrderEvents) == 0 {
-		return fmt.Errorf("%s: %s", ErrValidateSendStatus, ErrEmptyOrderEvents)
+// CancelAllOrdersAfter arms dead man's switch: all orders are cancelled when timeout (in seconds) expires
+// unless it is called again before that. Zero timeout disarms the switch
+func (a *API) CancelAllOrdersAfter(ctx context.Context, timeout uint) (*CancelAllOrdersAfterResponse, error) {
+	values := url.Values{}
+	values.Add("timeout", strconv.Itoa(int(timeout)))
+	resp, err := a.queryPrivate(ctx, http.MethodPost, "/derivatives/api/v3/cancelallordersafter", values, true, &CancelAllOrdersAfterResponse{})
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	return resp.(*CancelAllOrdersAfterResponse), nil
 }
 
-// queryPublic make request to public KrakenAPI endpoint
-func (a *API) queryPublic(reqType string, endpoint string, values url.Values, typ interface{}) (interface{}, error) {
-	urlPath := fmt.Sprintf("%s%s?%s", a.apiURL, endpoint, values.Encode())
-	return a.doRequest(reqType, urlPath, nil, typ)
+// BatchOrder places, edits and cancels several orders in one request. Every instruction gets own status,
+// statuses of send instructions are matched by order tag which is set to instruction index if it is empty
+func (a *API) BatchOrder(ctx context.Context, args BatchOrderArguments) (*BatchOrderResponse, error) {
+	if len(args.Instructions) == 0 {
+		return nil, fmt.Errorf("%s: %s", ErrBatchOrder, ErrEmptyBatch)
+	}
+
+	instructions := make([]batchInstruction, 0, len(args.Instructions))
+	for i, instruction := range args.Instructions {
+		wire := batchInstruction{
+			Order:    instruction.Order,
+			OrderID:  instruction.OrderID,
+			CliOrdID: instruction.CliOrdID,
+		}
+
+		switch instruction.Order {
+		case BatchSend:
+			wire.OrderTag = instruction.OrderTag
+			if wire.OrderTag == "" {
+				wire.OrderTag = strconv.Itoa(i)
+			}
+			wire.OrderType = instruction.OrderType
+			wire.Symbol = instruction.Symbol
+			wire.Side = instruction.Side
+			wire.Size = jsonNumber(instruction.Size)
+			wire.LimitPrice = jsonNumber(instruction.LimitPrice)
+			wire.StopPrice = jsonNumber(instruction.StopPrice)
+			wire.TriggerSignal = instruction.TriggerSignal
+			wire.ReduceOnly = instruction.ReduceOnly
+		case BatchEdit:
+			wire.Size = jsonNumber(instruction.Size)
+			wire.LimitPrice = jsonNumber(instruction.LimitPrice)
+			wire.StopPrice = jsonNumber(instruction.StopPrice)
+		case BatchCancel:
+		default:
+			return nil, fmt.Errorf("%s: %s: %s", ErrBatchOrder, ErrUnknownBatchInstruction, instruction.Order)
+		}
+
+		instructions = append(instructions, wire)
+	}
+
+	data, err := json.Marshal(struct {
+		BatchOrder []batchInstruction `json:"batchOrder"`
+	}{BatchOrder: instructions})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrBatchOrder, err)
+	}
+
+	values := url.Values{}
+	values.Add("json", string(data))
+
+	resp, err := a.queryPrivate(ctx, http.MethodPost, "/derivatives/api/v3/batchorder", values, batchHasClientIDs(args), &BatchOrderResponse{})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*BatchOrderResponse), nil
 }
 
-// queryPrivate make request to private KrakenAPI endpoint
-func (a *API) queryPrivate(reqType string, endpoint string, values url.Values, typ interface{}) (interface{}, error) {
-	urlPath := fmt.Sprintf("%s%s?%s", a.apiURL, endpoint, values.Encode())
-	authent, err := a.createSignature(endpoint, values.Encode(), "")
+// Accounts returns balances and margin of all user's cash and margin accounts keyed by account name
+func (a *API) Accounts(ctx context.Context) (*AccountsResponse, error) {
+	resp, err := a.queryPrivate(ctx, http.MethodGet, "/derivatives/api/v3/accounts", nil, true, &AccountsResponse{})
 	if err != nil {
 		return nil, err
 	}
+	return resp.(*AccountsResponse), nil
+}
 
-	headers := map[string]string{
-		"Authent": authent,
-		"APIKey":  a.apiPublicKey,
+func (a *API) OpenPositions(ctx context.Context) (*OpenPositionsResponse, error) {
+	resp, err := a.queryPrivate(ctx, http.MethodGet, "/derivatives/api/v3/openpositions", nil, true, &OpenPositionsResponse{})
+	if err != nil {
+		return nil, err
 	}
+	return resp.(*OpenPositionsResponse), nil
+}
 
-	return a.doRequest(reqType, urlPath, headers, typ)
+func (a *API) OpenOrders(ctx context.Context) (*OpenOrdersResponse, error) {
+	resp, err := a.queryPrivate(ctx, http.MethodGet, "/derivatives/api/v3/openorders", nil, true, &OpenOrdersResponse{})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*OpenOrdersResponse), nil
 }
 
-// doRequest executes HTTP Request to the KrakenAPI and returns the result
-func (a *API) doRequest(reqType string, reqURL string, headers map[string]string, typ interface{}) (interface{}, error) {
-	if typ == nil {
-		return nil, fmt.Errorf("%s: %s", ErrDoRequest, ErrNilTyp)
+// Fills returns the last 100 fills, lastFillTime (ISO8601) makes it return fills before that time
+func (a *API) Fills(ctx context.Context, lastFillTime string) (*FillsResponse, error) {
+	values := url.Values{}
+	if lastFillTime != "" {
+		values.Add("lastFillTime", lastFillTime)
+	}
+	resp, err := a.queryPrivate(ctx, http.MethodGet, "/derivatives/api/v3/fills", values, true, &FillsResponse{})
+	if err != nil {
+		return nil, err
 	}
+	return resp.(*FillsResponse), nil
+}
 
-	// Create request
-	req, err := http.NewRequest(reqType, reqURL, nil)
+// RecentOrders returns recent order events, of all instruments if symbol is empty
+func (a *API) RecentOrders(ctx context.Context, symbol string) (*RecentOrdersResponse, error) {
+	values := url.Values{}
+	if symbol != "" {
+		values.Add("symbol", symbol)
+	}
+	resp, err := a.queryPrivate(ctx, http.MethodGet, "/derivatives/api/v3/recentorders", values, true, &RecentOrdersResponse{})
 	if err != nil {
-		return nil, fmt.Errorf("%s: %s: %w", ErrDoRequest, ErrCouldNotCreateRequest, err)
+		return nil, err
 	}
+	return resp.(*RecentOrdersResponse), nil
+}
 
-	req.Header.Add("User-Agent", apiUserAgent)
+// ---------------------------------------------------------------------------------- //
 
-	for key, value := range headers {
-		req.Header.Add(key, value)
+// batchHasClientIDs reports whether retry of batch is safe: kraken rejects order with already used cliOrdId
+func batchHasClientIDs(args BatchOrderArguments) bool {
+	for _, instruction := range args.Instructions {
+		if instruction.Order == BatchSend && instruction.CliOrdID == "" {
+			return false
+		}
 	}
+	return true
+}
 
-	// Execute request
-	resp, err := a.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("%s: %s: %w", ErrDoRequest, ErrCouldNotExecuteRequest, err)
+func (s SendStatus) ValidateSendStatus() error {
+	if len(s.OrderEvents) == 0 {
+		return fmt.Errorf("%s: %s", ErrValidateSendStatus, ErrEmptyOrderEvents)
 	}
-	defer resp.Body.Close()
+	return nil
+}
 
-	// Read request
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("%s: %s: %w", ErrDoRequest, ErrCouldNotReadBody, err)
+// queryPublic make request to public KrakenAPI endpoint, public endpoints are always safe to retry
+func (a *API) queryPublic(ctx context.Context, reqType string, endpoint string, values url.Values, typ interface{}) (interface{}, error) {
+	urlPath := fmt.Sprintf("%s%s?%s", a.apiURL, endpoint, values.Encode())
+	return a.doRequest(ctx, true, func() (*http.Request, error) {
+		return http.NewRequest(reqType, urlPath, nil)
+	}, typ)
+}
+
+// queryPrivate make request to private KrakenAPI endpoint. Values are sent in query string of GET request
+// and as form-encoded body of POST request, in both cases they are signed exactly as they are sent.
+// Request is retried only if it is safe - it is idempotent or order has cliOrdId, so retry never places the same order twice
+func (a *API) queryPrivate(ctx context.Context, reqType string, endpoint string, values url.Values, safeToRetry bool,
+	typ interface{}) (interface{}, error) {
+	postData := values.Encode()
+	cost := requestCost(endpoint, values)
+	urlPath := a.apiURL + endpoint
+	if reqType == http.MethodGet && postData != "" {
+		urlPath += "?" + postData
+	}
+
+	return a.doRequest(ctx, safeToRetry, func() (*http.Request, error) {
+		var body io.Reader
+		if reqType != http.MethodGet {
+			body = strings.NewReader(postData)
+		}
+
+		req, err := http.NewRequest(reqType, urlPath, body)
+		if err != nil {
+			return nil, err
+		}
+
+		// every attempt spends budget and is signed with own nonce after waiting for it,
+		// kraken rejects the reused nonce or the one which is less than nonce of already sent request
+		if err := a.limiter.wait(ctx, cost); err != nil {
+			return nil, fmt.Errorf("%s: %w", ErrRateLimitWait, err)
+		}
+		nonce := a.nonces.next()
+		authent, err := a.createSignature(endpoint, postData, nonce)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Add("Authent", authent)
+		req.Header.Add("APIKey", a.apiPublicKey)
+		req.Header.Add("Nonce", nonce)
+		if body != nil {
+			req.Header.Add("Content-Type", formContentType)
+		}
+		return req, nil
+	}, typ)
+}
+
+// doRequest executes HTTP Request to the KrakenAPI and returns the result. Request created by newRequest
+// is retried with backoff on network errors and overloaded server if it is safe to retry and ctx does not disable retries
+func (a *API) doRequest(ctx context.Context, retryable bool, newRequest func() (*http.Request, error),
+	typ interface{}) (interface{}, error) {
+	if typ == nil {
+		return nil, fmt.Errorf("%s: %s", ErrDoRequest, ErrNilTyp)
+	}
+
+	attempts := 1
+	if retryable && a.retry.MaxAttempts > 1 && !retryDisabled(ctx) {
+		attempts = a.retry.MaxAttempts
+	}
+
+	var (
+		body        []byte
+		contentType string
+	)
+	for attempt := 0; ; attempt++ {
+		var (
+			retry bool
+			err   error
+		)
+		body, contentType, retry, err = a.attempt(ctx, newRequest)
+		if err == nil {
+			break
+		}
+		if !retry || attempt+1 >= attempts || ctx.Err() != nil {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%s: %w", ErrDoRequest, ctx.Err())
+		case <-time.After(a.retry.backoff(attempt)):
+		}
 	}
 
 	// validate content type
-	contentType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	contentType, _, err := mime.ParseMediaType(contentType)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %s: %w", ErrDoRequest, ErrCouldNotParseContentType, err)
 	}
@@ -262,6 +542,46 @@ func (a *API) doRequest(reqType string, reqURL string, headers map[string]string
 	return typ, nil
 }
 
+// attempt executes request once and reports whether it failed in the way which is worth retrying
+func (a *API) attempt(ctx context.Context, newRequest func() (*http.Request, error)) ([]byte, string, bool, error) {
+	// Create request
+	req, err := newRequest()
+	if err != nil {
+		return nil, "", false, fmt.Errorf("%s: %s: %w", ErrDoRequest, ErrCouldNotCreateRequest, err)
+	}
+
+	if a.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.timeout)
+		defer cancel()
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("User-Agent", a.userAgent)
+
+	// Execute request
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, "", true, fmt.Errorf("%s: %s: %w", ErrDoRequest, ErrCouldNotExecuteRequest, err)
+	}
+	defer resp.Body.Close()
+
+	// Read request
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", true, fmt.Errorf("%s: %s: %w", ErrDoRequest, ErrCouldNotReadBody, err)
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError {
+		apiErr := &APIError{Code: CodeServerUnavailable, HTTPStatus: resp.StatusCode, Result: string(body)}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			apiErr.Code = CodeAPILimitExceeded
+		}
+		return nil, "", true, fmt.Errorf("%s: %w", ErrDoRequest, apiErr)
+	}
+
+	return body, resp.Header.Get("Content-Type"), false, nil
+}
+
 // getSha256 creates a sha256 hash for given []byte
 func getSha256(input []byte) []byte {
 	sha := sha256.New()
@@ -276,7 +596,9 @@ func getHMacSha512(message, secret []byte) []byte {
 	return mac.Sum(nil)
 }
 
-// createSignature creates value for krakenAPI request Authent header
+// createSignature creates value for krakenAPI request Authent header:
+// base64(hmac-sha512(base64decode(privateKey), sha256(postData + nonce + endpointPath))),
+// where endpointPath is path of endpoint without "/derivatives" prefix
 func (a API) createSignature(endPoint, postData, nonce string) (string, error) {
 	endPoint = strings.TrimPrefix(endPoint, "/derivatives")
 
@@ -285,7 +607,7 @@ func (a API) createSignature(endPoint, postData, nonce string) (string, error) {
 
 	macKey, err := base64.StdEncoding.DecodeString(a.apiPrivateKey)
 	if err != nil {
-		return "", fmt.Errorf("create Signature: unable to create macKey")
+		return "", fmt.Errorf("%s: %s: %w", ErrCreateSignature, ErrDecodePrivateKey, err)
 	}
 	macSum := getHMacSha512(shaSum, macKey)
 	return base64.StdEncoding.EncodeToString(macSum), nil
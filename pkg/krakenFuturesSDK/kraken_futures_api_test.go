@@ -0,0 +1,359 @@
+package krakenFuturesSDK
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/pkg/krakenFuturesWSSDK"
+)
+
+// golden vectors are produced by independent implementation of kraken futures signing spec
+const testPrivateKey = "a3Jha2VuLWZ1dHVyZXMtdGVzdC1zZWNyZXQta2V5LTAxMjM0NTY3ODk="
+
+func fixedNonces(nonce int64) *nonceGenerator {
+	return &nonceGenerator{now: func() time.Time { return time.UnixMilli(nonce) }}
+}
+
+func TestAPI_createSignature(t *testing.T) {
+	tests := []struct {
+		name       string
+		privateKey string
+		endpoint   string
+		postData   string
+		nonce      string
+		want       string
+		wantErr    bool
+	}{
+		{
+			name:       "POST with nonce",
+			privateKey: testPrivateKey,
+			endpoint:   "/derivatives/api/v3/sendorder",
+			postData:   "orderType=mkt&side=buy&size=1&symbol=pi_xbtusd",
+			nonce:      "1650000000000",
+			want:       "5e3Ha6HaRrZ+LxhPIvIBlu/qsN67OTY1AKZ4W7gtGPDQvUjDnitmfL3iFoKP12mw5eYjTZzgfepjtx0RsIwoKQ==",
+		},
+		{
+			name:       "GET without params",
+			privateKey: testPrivateKey,
+			endpoint:   "/derivatives/api/v3/openpositions",
+			nonce:      "1650000000001",
+			want:       "/TeIdwE8rI2UjvC70LVPa7tZbd5HnhyURO7v6PL3Py1sqghp6GqMOTjAbCVaxY801595+zL69xzAr8mEhV9zQw==",
+		},
+		{
+			name:       "GET with escaped query",
+			privateKey: testPrivateKey,
+			endpoint:   "/derivatives/api/v3/fills",
+			postData:   "lastFillTime=2022-04-15T10%3A00%3A00.000Z",
+			nonce:      "1650000000002",
+			want:       "wTwHaSmQu0x2n4tr5CN5a6a+4H/usUCY273h1JGMAIeT5MjkjoQhuuceeOi80YcFiOiBwAXC2iBMgeyDpA9loQ==",
+		},
+		{
+			name:       "Without nonce",
+			privateKey: testPrivateKey,
+			endpoint:   "/derivatives/api/v3/cancelallordersafter",
+			postData:   "timeout=60",
+			want:       "FVI9W81LIL49Ny4BBglHCqajG722YdEb7a0z73es0R+PRFYYXESvVV/HU5K6L2MGYYiaj1aKc87v5DtAN3e+qA==",
+		},
+		{
+			name:       "Invalid private key",
+			privateKey: "not base64!",
+			endpoint:   "/derivatives/api/v3/openpositions",
+			wantErr:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			api := NewAPI("public", test.privateKey, "")
+
+			got, err := api.createSignature(test.endpoint, test.postData, test.nonce)
+			if test.wantErr {
+				var cause base64.CorruptInputError
+				assert.Contains(t, err.Error(), ErrDecodePrivateKey.Error())
+				assert.ErrorAs(t, err, &cause)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestAPI_queryPrivate(t *testing.T) {
+	tests := []struct {
+		name            string
+		nonce           int64
+		call            func(api *API) error
+		wantMethod      string
+		wantPath        string
+		wantQuery       string
+		wantBody        string
+		wantContentType string
+		wantAuthent     string
+	}{
+		{
+			name:  "POST params are sent as form body",
+			nonce: 1650000000000,
+			call: func(api *API) error {
+				_, err := api.SendOrder(context.Background(), SendOrderArguments{OrderType: "mkt", Symbol: "pi_xbtusd", Side: "buy", Size: decimal.NewFromInt(1)})
+				return err
+			},
+			wantMethod:      http.MethodPost,
+			wantPath:        "/derivatives/api/v3/sendorder",
+			wantBody:        "orderType=mkt&side=buy&size=1&symbol=pi_xbtusd",
+			wantContentType: formContentType,
+			wantAuthent:     "5e3Ha6HaRrZ+LxhPIvIBlu/qsN67OTY1AKZ4W7gtGPDQvUjDnitmfL3iFoKP12mw5eYjTZzgfepjtx0RsIwoKQ==",
+		},
+		{
+			name:  "Fractional size and prices are sent exactly",
+			nonce: 1650000000001,
+			call: func(api *API) error {
+				_, err := api.SendOrder(context.Background(), SendOrderArguments{OrderType: "lmt", Symbol: "pf_xbtusd",
+					Side: "sell", Size: decimal.RequireFromString("0.0015"), LimitPrice: decimal.RequireFromString("41234.5")})
+				return err
+			},
+			wantMethod:      http.MethodPost,
+			wantPath:        "/derivatives/api/v3/sendorder",
+			wantBody:        "limitPrice=41234.5&orderType=lmt&side=sell&size=0.0015&symbol=pf_xbtusd",
+			wantContentType: formContentType,
+		},
+		{
+			name:  "GET params are sent in query",
+			nonce: 1650000000002,
+			call: func(api *API) error {
+				_, err := api.Fills(context.Background(), "2022-04-15T10:00:00.000Z")
+				return err
+			},
+			wantMethod:  http.MethodGet,
+			wantPath:    "/derivatives/api/v3/fills",
+			wantQuery:   "lastFillTime=2022-04-15T10%3A00%3A00.000Z",
+			wantAuthent: "wTwHaSmQu0x2n4tr5CN5a6a+4H/usUCY273h1JGMAIeT5MjkjoQhuuceeOi80YcFiOiBwAXC2iBMgeyDpA9loQ==",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := ioutil.ReadAll(r.Body)
+				assert.NoError(t, err)
+
+				assert.Equal(t, test.wantMethod, r.Method)
+				assert.Equal(t, test.wantPath, r.URL.Path)
+				assert.Equal(t, test.wantQuery, r.URL.RawQuery)
+				assert.Equal(t, test.wantBody, string(body))
+				assert.Equal(t, test.wantContentType, r.Header.Get("Content-Type"))
+				assert.Equal(t, "public", r.Header.Get("APIKey"))
+				assert.Equal(t, strconv.FormatInt(test.nonce, 10), r.Header.Get("Nonce"))
+				if test.wantAuthent != "" {
+					assert.Equal(t, test.wantAuthent, r.Header.Get("Authent"))
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"result":"success","sendStatus":{"status":"placed","orderEvents":[{"type":"EXECUTION"}]}}`))
+			}))
+			defer server.Close()
+
+			api := NewAPI("public", testPrivateKey, server.URL)
+			api.nonces = fixedNonces(test.nonce)
+
+			assert.NoError(t, test.call(api))
+		})
+	}
+}
+
+func TestNonceGenerator(t *testing.T) {
+	now := time.UnixMilli(1650000000000)
+	generator := &nonceGenerator{now: func() time.Time { return now }}
+
+	assert.Equal(t, "1650000000000", generator.next())
+	// clock did not move or went back
+	assert.Equal(t, "1650000000001", generator.next())
+	now = now.Add(-time.Second)
+	assert.Equal(t, "1650000000002", generator.next())
+	now = now.Add(time.Minute)
+	assert.Equal(t, "1650000059000", generator.next())
+
+	assert.Same(t, NewAPI("shared", "", "").nonces, NewAPI("shared", "", "").nonces)
+	assert.NotSame(t, NewAPI("shared", "", "").nonces, NewAPI("other", "", "").nonces)
+}
+
+func TestAPI_doRequestRetries(t *testing.T) {
+	sendOrder := func(cliOrdID string) func(api *API) error {
+		return func(api *API) error {
+			_, err := api.SendOrder(context.Background(), SendOrderArguments{
+				OrderType: "mkt", Symbol: "pi_xbtusd", Side: "buy", Size: decimal.NewFromInt(1), CliOrderID: cliOrdID,
+			})
+			return err
+		}
+	}
+	tickers := func(api *API) error {
+		_, err := api.Tickers(context.Background())
+		return err
+	}
+	tickersWithoutRetry := func(api *API) error {
+		_, err := api.Tickers(WithoutRetry(context.Background()))
+		return err
+	}
+
+	tests := []struct {
+		name         string
+		failures     int32
+		call         func(api *API) error
+		wantAttempts int32
+		wantErr      bool
+	}{
+		{
+			name:         "Public request is retried",
+			failures:     2,
+			call:         tickers,
+			wantAttempts: 3,
+		},
+		{
+			name:         "Public request gives up after max attempts",
+			failures:     3,
+			call:         tickers,
+			wantAttempts: 3,
+			wantErr:      true,
+		},
+		{
+			name:         "Order with cliOrdId is retried",
+			failures:     1,
+			call:         sendOrder("client-id"),
+			wantAttempts: 2,
+		},
+		{
+			name:         "Order without cliOrdId is not retried",
+			failures:     1,
+			call:         sendOrder(""),
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+		{
+			name:         "Request is not retried if ctx disables retries",
+			failures:     1,
+			call:         tickersWithoutRetry,
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var attempts int32
+			nonces := make(map[string]struct{})
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "test-agent", r.Header.Get("User-Agent"))
+				if nonce := r.Header.Get("Nonce"); nonce != "" {
+					_, reused := nonces[nonce]
+					assert.False(t, reused, "nonce is reused on retry")
+					nonces[nonce] = struct{}{}
+				}
+
+				if atomic.AddInt32(&attempts, 1) <= test.failures {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"result":"success","sendStatus":{"status":"placed","orderEvents":[{"type":"EXECUTION"}]}}`))
+			}))
+			defer server.Close()
+
+			api := NewAPI("retry", testPrivateKey, server.URL,
+				WithHTTPClient(server.Client()),
+				WithUserAgent("test-agent"),
+				WithRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond}))
+
+			err := test.call(api)
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.wantAttempts, atomic.LoadInt32(&attempts))
+		})
+	}
+}
+
+func TestAPI_doRequestContext(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	api := NewAPI("", "", server.URL,
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 5, BaseDelay: time.Hour, MaxDelay: time.Hour}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := api.Tickers(ctx)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&attempts))
+}
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	for attempt, max := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond,
+		400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second} {
+		delay := policy.backoff(attempt)
+		assert.GreaterOrEqual(t, delay, max/2)
+		assert.LessOrEqual(t, delay, max)
+	}
+}
+
+func TestAPI_Candles(t *testing.T) {
+	d := decimal.RequireFromString
+	pages := map[string]string{
+		"1650000000": `{"candles":[{"time":1650000000000,"open":"100","high":"102.5","low":"99","close":"101","volume":10},` +
+			`{"time":1650000060000,"open":"101","high":"103","low":"100.5","close":"102","volume":"0.5"}],"more_candles":true}`,
+		// second page starts with the last candle of the first one
+		"1650000061": `{"candles":[{"time":1650000060000,"open":"101","high":"103","low":"100.5","close":"102","volume":1},` +
+			`{"time":1650000120000,"open":"102","high":"104","low":"101","close":"103","volume":3},` +
+			`{"time":1650000180000,"open":"103","high":"104","low":"102","close":"102.5","volume":4}],"more_candles":true}`,
+	}
+
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/charts/v1/trade/PI_XBTUSD/1m", r.URL.Path)
+		assert.Equal(t, "1650000180", r.URL.Query().Get("to"))
+		requests = append(requests, r.URL.Query().Get("from"))
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(pages[r.URL.Query().Get("from")]))
+	}))
+	defer server.Close()
+
+	api := NewAPI("", "", server.URL)
+	candles, err := api.Candles(context.Background(), CandlesArguments{
+		TickType:   krakenFuturesWSSDK.TradePriceSource,
+		Symbol:     "PI_XBTUSD",
+		Resolution: "1m",
+		From:       time.Unix(1650000000, 0),
+		To:         time.Unix(1650000180, 0),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1650000000", "1650000061"}, requests)
+	// candle opened at the end of range is not included
+	assert.Equal(t, []krakenFuturesWSSDK.Candle{
+		{Time: 1650000000, Open: d("100"), High: d("102.5"), Low: d("99"), Close: d("101"), Volume: decimal.RequireFromString("10")},
+		{Time: 1650000060, Open: d("101"), High: d("103"), Low: d("100.5"), Close: d("102"), Volume: decimal.RequireFromString("0.5")},
+		{Time: 1650000120, Open: d("102"), High: d("104"), Low: d("101"), Close: d("103"), Volume: decimal.RequireFromString("3")},
+	}, candles)
+
+	_, err = api.Candles(context.Background(), CandlesArguments{TickType: "last", Symbol: "PI_XBTUSD", Resolution: "1m"})
+	assert.Contains(t, err.Error(), ErrInvalidCandlesArguments.Error())
+}
@@ -0,0 +1,46 @@
+package krakenFuturesSDK
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+// nonceGenerator issues strictly increasing nonces based on current time in milliseconds.
+// Kraken rejects nonce which is not greater than the previous one of the same api key,
+// so generator is shared by all API clients of the key
+type nonceGenerator struct {
+	mu   sync.Mutex
+	last int64
+	now  func() time.Time
+}
+
+func (g *nonceGenerator) next() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	nonce := g.now().UnixMilli()
+	if nonce <= g.last {
+		nonce = g.last + 1
+	}
+	g.last = nonce
+	return strconv.FormatInt(nonce, 10)
+}
+
+var (
+	noncesMu sync.Mutex
+	nonces   = make(map[string]*nonceGenerator)
+)
+
+// nonceGeneratorFor returns generator shared by all clients of api key
+func nonceGeneratorFor(apiPublicKey string) *nonceGenerator {
+	noncesMu.Lock()
+	defer noncesMu.Unlock()
+
+	generator, ok := nonces[apiPublicKey]
+	if !ok {
+		generator = &nonceGenerator{now: time.Now}
+		nonces[apiPublicKey] = generator
+	}
+	return generator
+}
@@ -0,0 +1,85 @@
+package krakenFuturesSDK
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy sets how idempotent requests are retried, delay before retry grows exponentially
+// from BaseDelay up to MaxDelay and is randomized, so clients don't retry in lockstep
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   200 * time.Millisecond,
+	MaxDelay:    2 * time.Second,
+}
+
+// NoRetry makes every request to be sent only once
+var NoRetry = RetryPolicy{MaxAttempts: 1}
+
+type noRetryKey struct{}
+
+// WithoutRetry makes requests made with ctx to be sent only once whatever the policy of api is,
+// it is used by caller which retries request itself, so request is not repeated by both
+func WithoutRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noRetryKey{}, true)
+}
+
+func retryDisabled(ctx context.Context) bool {
+	disabled, _ := ctx.Value(noRetryKey{}).(bool)
+	return disabled
+}
+
+// backoff returns delay before retry which follows attempt, it is random value in [delay/2, delay]
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay << attempt
+	if delay > p.MaxDelay || delay <= 0 {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+type Option func(api *API)
+
+func WithHTTPClient(client *http.Client) Option {
+	return func(api *API) {
+		api.client = client
+	}
+}
+
+// WithTimeout limits every attempt of request, zero timeout leaves it to context and http client
+func WithTimeout(timeout time.Duration) Option {
+	return func(api *API) {
+		api.timeout = timeout
+	}
+}
+
+func WithUserAgent(userAgent string) Option {
+	return func(api *API) {
+		api.userAgent = userAgent
+	}
+}
+
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(api *API) {
+		api.retry = policy
+	}
+}
+
+// WithRateLimit sets budget of private endpoints, budget is shared by all clients of api key,
+// so it is taken from the first client created for the key
+func WithRateLimit(limit RateLimit) Option {
+	return func(api *API) {
+		api.rateLimit = limit
+	}
+}
@@ -0,0 +1,179 @@
+package krakenFuturesSDK
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var ErrRateLimitWait = errors.New("wait for rate limit")
+
+// RateLimit is budget of private endpoints of one api key: Capacity tokens are replenished every Window.
+// Zero RateLimit disables limiting
+type RateLimit struct {
+	Capacity float64
+	Window   time.Duration
+}
+
+// DefaultRateLimit is kraken futures budget of derivatives endpoints
+var DefaultRateLimit = RateLimit{Capacity: 500, Window: 10 * time.Second}
+
+// costs of private endpoints in tokens of RateLimit
+const (
+	defaultEndpointCost   = 1
+	fillsWithTimeCost     = 25
+	batchOrderBaseCost    = 9
+	batchOrderEndpoint    = "/derivatives/api/v3/batchorder"
+	fillsEndpoint         = "/derivatives/api/v3/fills"
+	fillsLastFillTimeName = "lastFillTime"
+)
+
+var endpointCosts = map[string]float64{
+	"/derivatives/api/v3/sendorder":            10,
+	"/derivatives/api/v3/editorder":            10,
+	"/derivatives/api/v3/cancelorder":          10,
+	"/derivatives/api/v3/cancelallorders":      25,
+	"/derivatives/api/v3/cancelallordersafter": 25,
+	"/derivatives/api/v3/accounts":             2,
+	"/derivatives/api/v3/openpositions":        2,
+	"/derivatives/api/v3/openorders":           2,
+	"/derivatives/api/v3/recentorders":         2,
+	fillsEndpoint:                              2,
+}
+
+// requestCost returns cost of private request, batch costs base cost plus one token for every instruction
+// and fills are more expensive when they are requested from the given time
+func requestCost(endpoint string, values url.Values) float64 {
+	switch endpoint {
+	case batchOrderEndpoint:
+		var batch struct {
+			BatchOrder []json.RawMessage `json:"batchOrder"`
+		}
+		_ = json.Unmarshal([]byte(values.Get("json")), &batch)
+		return batchOrderBaseCost + float64(len(batch.BatchOrder))
+	case fillsEndpoint:
+		if values.Get(fillsLastFillTimeName) != "" {
+			return fillsWithTimeCost
+		}
+	}
+
+	if cost, ok := endpointCosts[endpoint]; ok {
+		return cost
+	}
+	return defaultEndpointCost
+}
+
+// RateLimitUsage is current state of budget of api key
+type RateLimitUsage struct {
+	Capacity float64 `json:"capacity"`
+	// Available tokens, negative value is debt of queued requests
+	Available float64 `json:"available"`
+	Waiting   int     `json:"waiting"`
+}
+
+// tokenBucket lets request go when its cost is covered by budget. Cost is reserved on arrival,
+// so requests are served in order of arrival and cancelled request returns its reservation
+type tokenBucket struct {
+	mu       sync.Mutex
+	capacity float64
+	perSec   float64
+	tokens   float64
+	waiting  int
+	last     time.Time
+	now      func() time.Time
+}
+
+func newTokenBucket(limit RateLimit) *tokenBucket {
+	if limit.Capacity <= 0 || limit.Window <= 0 {
+		return nil
+	}
+	return &tokenBucket{
+		capacity: limit.Capacity,
+		perSec:   limit.Capacity / limit.Window.Seconds(),
+		tokens:   limit.Capacity,
+		last:     time.Now(),
+		now:      time.Now,
+	}
+}
+
+func (b *tokenBucket) refillLocked() {
+	now := b.now()
+	b.tokens += now.Sub(b.last).Seconds() * b.perSec
+	if b.tokens > b.capacity {
+		b.tokens = b.capacity
+	}
+	b.last = now
+}
+
+// wait blocks until request of given cost can be sent, nil bucket never blocks
+func (b *tokenBucket) wait(ctx context.Context, cost float64) error {
+	if b == nil {
+		return nil
+	}
+	if cost > b.capacity {
+		cost = b.capacity
+	}
+
+	b.mu.Lock()
+	b.refillLocked()
+	b.tokens -= cost
+	deficit := -b.tokens
+	if deficit <= 0 {
+		b.mu.Unlock()
+		return nil
+	}
+	b.waiting++
+	b.mu.Unlock()
+
+	timer := time.NewTimer(time.Duration(deficit / b.perSec * float64(time.Second)))
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		b.mu.Lock()
+		b.waiting--
+		b.mu.Unlock()
+		return nil
+	case <-ctx.Done():
+		b.mu.Lock()
+		b.waiting--
+		b.tokens += cost
+		b.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+func (b *tokenBucket) usage() RateLimitUsage {
+	if b == nil {
+		return RateLimitUsage{}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refillLocked()
+	return RateLimitUsage{Capacity: b.capacity, Available: b.tokens, Waiting: b.waiting}
+}
+
+var (
+	limitersMu sync.Mutex
+	limiters   = make(map[string]*tokenBucket)
+)
+
+// rateLimiterFor returns bucket shared by all clients of api key, kraken counts budget per key.
+// Limit of the first client of the key is used
+func rateLimiterFor(apiPublicKey string, limit RateLimit) *tokenBucket {
+	limitersMu.Lock()
+	defer limitersMu.Unlock()
+
+	limiter, ok := limiters[apiPublicKey]
+	if !ok {
+		limiter = newTokenBucket(limit)
+		limiters[apiPublicKey] = limiter
+	}
+	return limiter
+}
@@ -0,0 +1,100 @@
+package krakenFuturesSDK
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestCost(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint string
+		values   url.Values
+		want     float64
+	}{
+		{
+			name:     "Send order",
+			endpoint: "/derivatives/api/v3/sendorder",
+			want:     10,
+		},
+		{
+			name:     "Batch of two instructions",
+			endpoint: batchOrderEndpoint,
+			values:   url.Values{"json": {`{"batchOrder":[{"order":"send"},{"order":"cancel"}]}`}},
+			want:     11,
+		},
+		{
+			name:     "Fills from time",
+			endpoint: fillsEndpoint,
+			values:   url.Values{fillsLastFillTimeName: {"2022-04-15T10:00:00.000Z"}},
+			want:     25,
+		},
+		{
+			name:     "Last fills",
+			endpoint: fillsEndpoint,
+			want:     2,
+		},
+		{
+			name:     "Unknown endpoint",
+			endpoint: "/derivatives/api/v3/unknown",
+			want:     1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, requestCost(test.endpoint, test.values))
+		})
+	}
+}
+
+func TestTokenBucket_wait(t *testing.T) {
+	bucket := newTokenBucket(RateLimit{Capacity: 20, Window: 100 * time.Millisecond})
+
+	start := time.Now()
+	assert.NoError(t, bucket.wait(context.Background(), 10))
+	assert.NoError(t, bucket.wait(context.Background(), 10))
+	assert.Less(t, time.Since(start), 40*time.Millisecond)
+
+	// the third request waits until 10 tokens are replenished - 50ms
+	assert.NoError(t, bucket.wait(context.Background(), 10))
+	assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+
+	usage := bucket.usage()
+	assert.Equal(t, float64(20), usage.Capacity)
+	assert.Equal(t, 0, usage.Waiting)
+}
+
+func TestTokenBucket_waitCancelled(t *testing.T) {
+	bucket := newTokenBucket(RateLimit{Capacity: 10, Window: time.Hour})
+	assert.NoError(t, bucket.wait(context.Background(), 10))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	done := make(chan error)
+	go func() {
+		done <- bucket.wait(ctx, 5)
+	}()
+
+	time.Sleep(5 * time.Millisecond)
+	assert.Equal(t, 1, bucket.usage().Waiting)
+
+	assert.ErrorIs(t, <-done, context.DeadlineExceeded)
+	usage := bucket.usage()
+	assert.Equal(t, 0, usage.Waiting)
+	// reservation of cancelled request is returned
+	assert.InDelta(t, 0, usage.Available, 0.1)
+}
+
+func TestRateLimiterFor(t *testing.T) {
+	api := NewAPI("limited", "", "", WithRateLimit(RateLimit{Capacity: 100, Window: time.Second}))
+
+	assert.Same(t, api.limiter, NewAPI("limited", "", "").limiter)
+	assert.Equal(t, float64(100), NewAPI("limited", "", "").RateLimitUsage().Capacity)
+	assert.Equal(t, RateLimitUsage{}, NewAPI("unlimited", "", "", WithRateLimit(RateLimit{})).RateLimitUsage())
+}
@@ -1,5 +1,15 @@
 package krakenFuturesSDK
 
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"trade-bot/pkg/krakenFuturesWSSDK"
+)
+
 const SellSide = "sell"
 const BuySide = "buy"
 
@@ -33,6 +43,16 @@ func (s CancelOrderStatus) IsSuccessStatus() bool {
 	return false
 }
 
+type BatchOrderStatus string
+
+func (s BatchOrderStatus) IsSuccessStatus() bool {
+	statuses := map[string]struct{}{"placed": {}, "edited": {}, "cancelled": {}}
+	if _, ok := statuses[string(s)]; ok {
+		return true
+	}
+	return false
+}
+
 type CancelAllOrdersStatus string
 
 func (s CancelAllOrdersStatus) IsSuccessStatus() bool {
@@ -76,6 +96,29 @@ type InstrumentsResponse struct {
 	Instruments []Instrument `json:"instruments,omitempty"`
 }
 
+// HistoricalFundingResponse wraps the Kraken API JSON HistoricalFunding method
+type HistoricalFundingResponse struct {
+	KrakenErrorResponse
+	Rates []FundingRate `json:"rates,omitempty"`
+}
+
+// CandlesResponse wraps the Kraken charts API JSON, kraken sets MoreCandles when range has candles after the last one
+type CandlesResponse struct {
+	Candles     []ChartCandle `json:"candles"`
+	MoreCandles bool          `json:"more_candles"`
+}
+
+// CandlesArguments selects candles of symbol which were opened in [From, To), zero To means now
+// CandlesArguments selects candles of charts API, tick type and resolution take the values of candles feeds
+// of krakenFuturesWSSDK, e.g. krakenFuturesWSSDK.MarkPriceSource and krakenFuturesWSSDK.OneHourResolution
+type CandlesArguments struct {
+	TickType   string
+	Symbol     string
+	Resolution string
+	From       time.Time
+	To         time.Time
+}
+
 // --------------------------------------------------------------------------------------- //
 
 // -------------------------- PRIVATE KRAKEN API ENDPOINTS DATA -------------------------- //
@@ -86,15 +129,16 @@ type SendOrderResponse struct {
 }
 
 type SendOrderArguments struct {
-	OrderType     string  `json:"order_type" binding:"required"`
-	Symbol        string  `json:"symbol" binding:"required"`
-	Side          string  `json:"side" binding:"required"`
-	Size          uint    `json:"size" binding:"required"`
-	LimitPrice    float64 `json:"limit_price"`
-	StopPrice     float64 `json:"stop_price"`
-	TriggerSignal string  `json:"trigger_signal"`
-	CliOrderID    string  `json:"cli_order_id"`
-	ReduceOnly    bool    `json:"reduce_only"`
+	OrderType string `json:"order_type" binding:"required"`
+	Symbol    string `json:"symbol" binding:"required"`
+	Side      string `json:"side" binding:"required"`
+	// Size is number of contracts, it may be fractional if instrument allows it
+	Size          decimal.Decimal `json:"size" binding:"required,gt=0" swaggertype:"string"`
+	LimitPrice    decimal.Decimal `json:"limit_price" swaggertype:"string"`
+	StopPrice     decimal.Decimal `json:"stop_price" swaggertype:"string"`
+	TriggerSignal string          `json:"trigger_signal"`
+	CliOrderID    string          `json:"cli_order_id"`
+	ReduceOnly    bool            `json:"reduce_only"`
 }
 
 func (s *SendOrderArguments) ChangeToOpositeOrderSide() {
@@ -112,9 +156,9 @@ type EditOrderResponse struct {
 
 type EditOrderArguments struct {
 	OrderID    string
-	Size       uint
-	LimitPrice float64
-	StopPrice  float64
+	Size       decimal.Decimal
+	LimitPrice decimal.Decimal
+	StopPrice  decimal.Decimal
 	CliOrdID   string
 }
 
@@ -133,6 +177,98 @@ type CancelAllOrdersResponse struct {
 	CancelStatus CancelAllStatus `json:"cancelStatus,omitempty"`
 }
 
+type CancelAllOrdersAfterResponse struct {
+	KrakenErrorResponse
+	Status DeadManSwitchStatus `json:"status,omitempty"`
+}
+
+// DeadManSwitchStatus has zero trigger time when switch is disarmed
+type DeadManSwitchStatus struct {
+	CurrentTime string `json:"currentTime,omitempty"`
+	TriggerTime string `json:"triggerTime,omitempty"`
+}
+
+const (
+	BatchSend   = "send"
+	BatchEdit   = "edit"
+	BatchCancel = "cancel"
+)
+
+type BatchOrderResponse struct {
+	KrakenErrorResponse
+	BatchStatus []BatchStatus `json:"batchStatus,omitempty"`
+}
+
+type BatchOrderArguments struct {
+	Instructions []BatchInstruction `json:"instructions" binding:"required,min=1,dive"`
+}
+
+// BatchInstruction is one send, edit or cancel of batch order, edit and cancel select order by OrderID or CliOrdID
+type BatchInstruction struct {
+	Order         string          `json:"order" binding:"required,oneof=send edit cancel"`
+	OrderTag      string          `json:"order_tag"`
+	OrderType     string          `json:"order_type"`
+	Symbol        string          `json:"symbol"`
+	Side          string          `json:"side"`
+	Size          decimal.Decimal `json:"size" swaggertype:"string"`
+	LimitPrice    decimal.Decimal `json:"limit_price" swaggertype:"string"`
+	StopPrice     decimal.Decimal `json:"stop_price" swaggertype:"string"`
+	TriggerSignal string          `json:"trigger_signal"`
+	CliOrdID      string          `json:"cli_order_id"`
+	ReduceOnly    bool            `json:"reduce_only"`
+	OrderID       string          `json:"order_id"`
+}
+
+// batchInstruction is BatchInstruction as kraken expects it, numbers are kept exact and are sent as json numbers
+type batchInstruction struct {
+	Order         string      `json:"order"`
+	OrderTag      string      `json:"order_tag,omitempty"`
+	OrderType     string      `json:"orderType,omitempty"`
+	Symbol        string      `json:"symbol,omitempty"`
+	Side          string      `json:"side,omitempty"`
+	Size          json.Number `json:"size,omitempty"`
+	LimitPrice    json.Number `json:"limitPrice,omitempty"`
+	StopPrice     json.Number `json:"stopPrice,omitempty"`
+	TriggerSignal string      `json:"triggerSignal,omitempty"`
+	CliOrdID      string      `json:"cliOrdId,omitempty"`
+	ReduceOnly    bool        `json:"reduceOnly,omitempty"`
+	OrderID       string      `json:"order_id,omitempty"`
+}
+
+// jsonNumber returns empty number for zero, so it is omitted from request
+func jsonNumber(d decimal.Decimal) json.Number {
+	if d.IsZero() {
+		return ""
+	}
+	return json.Number(d.String())
+}
+
+// AccountsResponse wraps the Kraken API JSON Accounts method, accounts are keyed by name (cash, fi_xbtusd, flex...)
+type AccountsResponse struct {
+	KrakenErrorResponse
+	Accounts map[string]Account `json:"accounts,omitempty"`
+}
+
+type OpenPositionsResponse struct {
+	KrakenErrorResponse
+	OpenPositions []OpenPosition `json:"openPositions,omitempty"`
+}
+
+type OpenOrdersResponse struct {
+	KrakenErrorResponse
+	OpenOrders []OpenOrder `json:"openOrders,omitempty"`
+}
+
+type FillsResponse struct {
+	KrakenErrorResponse
+	Fills []Fill `json:"fills,omitempty"`
+}
+
+type RecentOrdersResponse struct {
+	KrakenErrorResponse
+	OrderEvents []RecentOrderEvent `json:"orderEvents,omitempty"`
+}
+
 // --------------------------------------------------------------------------------------- //
 
 type CancelStatus struct {
@@ -167,62 +303,203 @@ type CancelAllStatus struct {
 	OrderEvents     []OrderEvent          `json:"orderEvents,omitempty"`
 }
 
+type BatchStatus struct {
+	Status           BatchOrderStatus `json:"status"`
+	OrderTag         string           `json:"order_tag,omitempty"`
+	OrderID          string           `json:"order_id,omitempty"`
+	CliOrdID         string           `json:"cliOrdId,omitempty"`
+	DateTimeReceived string           `json:"dateTimeReceived,omitempty"`
+	OrderEvents      []OrderEvent     `json:"orderEvents,omitempty"`
+}
+
 type CanceledOrder struct {
 	OrderID  string `json:"order_id,omitempty"`
 	CliOrdID string `json:"cliOrdId,omitempty"`
 }
 
 type OrderEvent struct {
-	Type                string  `json:"type,omitempty"`
-	ReducedQuantity     int     `json:"reducedQuantity,omitempty"`
-	Order               Order   `json:"order,omitempty"`
-	UID                 string  `json:"uid,omitempty"`
-	Old                 Order   `json:"old,omitempty"`
-	New                 Order   `json:"new,omitempty"`
-	Reason              string  `json:"reason,omitempty"`
-	Amount              int     `json:"amount,omitempty"`
-	Price               float64 `json:"price,omitempty"`
-	ExecutionID         string  `json:"executionId,omitempty"`
-	TakeReducedQuantity int     `json:"takeReducedQuantity,omitempty"`
-	OrderPriorEdit      Order   `json:"orderPriorEdit,omitempty"`
-	OrderPriorExecution Order   `json:"orderPriorExecution,omitempty"`
+	Type                string          `json:"type,omitempty"`
+	ReducedQuantity     decimal.Decimal `json:"reducedQuantity,omitempty"`
+	Order               Order           `json:"order,omitempty"`
+	UID                 string          `json:"uid,omitempty"`
+	Old                 Order           `json:"old,omitempty"`
+	New                 Order           `json:"new,omitempty"`
+	Reason              string          `json:"reason,omitempty"`
+	Amount              decimal.Decimal `json:"amount,omitempty"`
+	Price               decimal.Decimal `json:"price,omitempty"`
+	ExecutionID         string          `json:"executionId,omitempty"`
+	TakeReducedQuantity decimal.Decimal `json:"takeReducedQuantity,omitempty"`
+	OrderPriorEdit      Order           `json:"orderPriorEdit,omitempty"`
+	OrderPriorExecution Order           `json:"orderPriorExecution,omitempty"`
 }
 
 type Order struct {
-	OrderID             string  `json:"orderId,omitempty"`
-	CliOrderID          string  `json:"cliOrdID,omitempty"`
-	ReduceOnly          bool    `json:"reduceOnly"`
-	Symbol              string  `json:"symbol,omitempty"`
-	Quantity            float64 `json:"quantity,omitempty"`
-	Side                string  `json:"side,omitempty"`
-	LimitPrice          float64 `json:"limitPrice,omitempty"`
-	StopPrice           float64 `json:"stopPrice,omitempty"`
-	Filled              float64 `json:"filled"`
-	Type                string  `json:"type,omitempty"`
-	Timestamp           string  `json:"timestamp,omitempty"`
-	LastUpdateTimestamp string  `json:"lastUpdateTimestamp,omitempty"`
+	OrderID             string          `json:"orderId,omitempty"`
+	CliOrderID          string          `json:"cliOrdID,omitempty"`
+	ReduceOnly          bool            `json:"reduceOnly"`
+	Symbol              string          `json:"symbol,omitempty"`
+	Quantity            decimal.Decimal `json:"quantity,omitempty"`
+	Side                string          `json:"side,omitempty"`
+	LimitPrice          decimal.Decimal `json:"limitPrice,omitempty"`
+	StopPrice           decimal.Decimal `json:"stopPrice,omitempty"`
+	Filled              decimal.Decimal `json:"filled"`
+	Type                string          `json:"type,omitempty"`
+	Timestamp           string          `json:"timestamp,omitempty"`
+	LastUpdateTimestamp string          `json:"lastUpdateTimestamp,omitempty"`
+}
+
+// Account is cash, margin or multi-collateral (flex) account, fields which are not used by account type are empty
+type Account struct {
+	Type               string             `json:"type"`
+	Currency           string             `json:"currency,omitempty"`
+	Balances           map[string]float64 `json:"balances,omitempty"`
+	Auxiliary          AccountAuxiliary   `json:"auxiliary,omitempty"`
+	MarginRequirements MarginRequirements `json:"marginRequirements,omitempty"`
+	TriggerEstimates   MarginRequirements `json:"triggerEstimates,omitempty"`
+	// fields of multi-collateral account
+	Currencies              map[string]FlexCurrency `json:"currencies,omitempty"`
+	InitialMargin           float64                 `json:"initialMargin,omitempty"`
+	MaintenanceMargin       float64                 `json:"maintenanceMargin,omitempty"`
+	BalanceValue            float64                 `json:"balanceValue,omitempty"`
+	PortfolioValue          float64                 `json:"portfolioValue,omitempty"`
+	CollateralValue         float64                 `json:"collateralValue,omitempty"`
+	PnL                     float64                 `json:"pnl,omitempty"`
+	UnrealizedFunding       float64                 `json:"unrealizedFunding,omitempty"`
+	TotalUnrealized         float64                 `json:"totalUnrealized,omitempty"`
+	AvailableMargin         float64                 `json:"availableMargin,omitempty"`
+	MarginEquity            float64                 `json:"marginEquity,omitempty"`
+	InitialMarginWithOrders float64                 `json:"initialMarginWithOrders,omitempty"`
+}
+
+type AccountAuxiliary struct {
+	USD     float64 `json:"usd"`
+	PV      float64 `json:"pv"`
+	PnL     float64 `json:"pnl"`
+	AF      float64 `json:"af"`
+	Funding float64 `json:"funding"`
+}
+
+// MarginRequirements are initial, maintenance, liquidation and termination thresholds of margin account
+type MarginRequirements struct {
+	IM float64 `json:"im"`
+	MM float64 `json:"mm"`
+	LT float64 `json:"lt"`
+	TT float64 `json:"tt"`
+}
+
+type FlexCurrency struct {
+	Quantity   float64 `json:"quantity"`
+	Value      float64 `json:"value"`
+	Collateral float64 `json:"collateral"`
+	Available  float64 `json:"available"`
+}
+
+type OpenPosition struct {
+	Side              string          `json:"side"`
+	Symbol            string          `json:"symbol"`
+	Price             decimal.Decimal `json:"price"`
+	FillTime          string          `json:"fillTime"`
+	Size              decimal.Decimal `json:"size"`
+	UnrealizedFunding float64         `json:"unrealizedFunding,omitempty"`
+	PnLCurrency       string          `json:"pnlCurrency,omitempty"`
+}
+
+type OpenOrder struct {
+	OrderID        string          `json:"order_id"`
+	CliOrdID       string          `json:"cliOrdId,omitempty"`
+	Status         string          `json:"status"`
+	Side           string          `json:"side"`
+	OrderType      string          `json:"orderType"`
+	Symbol         string          `json:"symbol"`
+	LimitPrice     decimal.Decimal `json:"limitPrice,omitempty"`
+	StopPrice      decimal.Decimal `json:"stopPrice,omitempty"`
+	FilledSize     decimal.Decimal `json:"filledSize"`
+	UnfilledSize   decimal.Decimal `json:"unfilledSize"`
+	ReduceOnly     bool            `json:"reduceOnly"`
+	TriggerSignal  string          `json:"triggerSignal,omitempty"`
+	ReceivedTime   string          `json:"receivedTime"`
+	LastUpdateTime string          `json:"lastUpdateTime"`
+}
+
+type Fill struct {
+	FillID   string          `json:"fill_id"`
+	Symbol   string          `json:"symbol"`
+	Side     string          `json:"side"`
+	OrderID  string          `json:"order_id"`
+	CliOrdID string          `json:"cliOrdId,omitempty"`
+	Size     decimal.Decimal `json:"size"`
+	Price    decimal.Decimal `json:"price"`
+	FillTime string          `json:"fillTime"`
+	FillType string          `json:"fillType"`
+}
+
+type RecentOrderEvent struct {
+	Timestamp string     `json:"timestamp"`
+	UID       string     `json:"uid"`
+	Event     OrderEvent `json:"event"`
+}
+
+type FundingRate struct {
+	Timestamp           string  `json:"timestamp"`
+	FundingRate         float64 `json:"fundingRate"`
+	RelativeFundingRate float64 `json:"relativeFundingRate"`
+}
+
+// ChartCandle is candle of kraken charts API, Time is open time in milliseconds.
+// Kraken sends prices as strings and volume as number, so both forms are accepted
+type ChartCandle struct {
+	Time   int64           `json:"time"`
+	Open   decimal.Decimal `json:"open"`
+	High   decimal.Decimal `json:"high"`
+	Low    decimal.Decimal `json:"low"`
+	Close  decimal.Decimal `json:"close"`
+	Volume decimal.Decimal `json:"volume"`
 }
 
 type Instrument struct {
-	Symbol          string        `json:"symbol"`
-	Type            string        `json:"type"`
-	Tradeable       bool          `json:"tradeable"`
-	Underlying      string        `json:"underlying,omitempty"`
-	LastTradingTime string        `json:"lastTradingTime,omitempty"`
-	TickSize        float64       `json:"tickSize,omitempty"`
-	ContractSize    int           `json:"contractSize,omitempty"`
-	MarginLevels    []MarginLevel `json:"marginLevels,omitempty"`
+	Symbol          string          `json:"symbol"`
+	Type            string          `json:"type"`
+	Tradeable       bool            `json:"tradeable"`
+	Underlying      string          `json:"underlying,omitempty"`
+	LastTradingTime string          `json:"lastTradingTime,omitempty"`
+	TickSize        decimal.Decimal `json:"tickSize,omitempty"`
+	ContractSize    decimal.Decimal `json:"contractSize,omitempty"`
+	// ContractValueTradePrecision is number of decimal places allowed in order size, negative for sizes
+	// which must be multiple of power of ten
+	ContractValueTradePrecision int             `json:"contractValueTradePrecision,omitempty"`
+	MaxPositionSize             decimal.Decimal `json:"maxPositionSize,omitempty"`
+	// PostOnly instrument accepts only orders which are never executed immediately
+	PostOnly     bool          `json:"postOnly,omitempty"`
+	MarginLevels []MarginLevel `json:"marginLevels,omitempty"`
+}
+
+// RoundPrice rounds price to the nearest multiple of instrument's tick size, price is unchanged if tick size is unknown
+func (i Instrument) RoundPrice(price decimal.Decimal) decimal.Decimal {
+	if !i.TickSize.IsPositive() {
+		return price
+	}
+	return price.DivRound(i.TickSize, 0).Mul(i.TickSize)
+}
+
+// SizeStep is the smallest order size of instrument and every order size must be its multiple
+func (i Instrument) SizeStep() decimal.Decimal {
+	return decimal.New(1, -int32(i.ContractValueTradePrecision))
+}
+
+// IsValidSize reports whether order of such size can be sent for instrument
+func (i Instrument) IsValidSize(size decimal.Decimal) bool {
+	return size.IsPositive() && size.Mod(i.SizeStep()).IsZero()
 }
 
 type MarginLevel struct {
-	Contracts         int     `json:"contracts"`
-	InitialMargin     float64 `json:"initialMargin"`
-	MaintenanceMargin float64 `json:"maintenanceMargin"`
+	Contracts         decimal.Decimal `json:"contracts"`
+	InitialMargin     float64         `json:"initialMargin"`
+	MaintenanceMargin float64         `json:"maintenanceMargin"`
 }
 
 type OrderBook struct {
-	Bids [][2]float64 `json:"bids"`
-	Asks [][2]float64 `json:"asks"`
+	Bids [][2]decimal.Decimal `json:"bids"`
+	Asks [][2]decimal.Decimal `json:"asks"`
 }
 
 type FeeSchedules struct {
@@ -238,21 +515,51 @@ type Tier struct {
 }
 
 type Ticker struct {
-	Tag                   string  `json:"tag,omitempty"`
-	Pair                  string  `json:"pair,omitempty"`
-	Symbol                string  `json:"symbol,omitempty"`
-	MarkPrice             float64 `json:"markPrice,omitempty"`
-	Bid                   float64 `json:"bid,omitempty"`
-	BidSize               int     `json:"bidSize,omitempty"`
-	Ask                   float64 `json:"ask,omitempty"`
-	AskSize               int     `json:"askSize,omitempty"`
-	Vol24h                int     `json:"vol24h,omitempty"`
-	OpenInterest          float64 `json:"openInterest,omitempty"`
-	Open24H               float64 `json:"open24h,omitempty"`
-	Last                  float64 `json:"last,omitempty"`
-	LastTime              string  `json:"lastTime,omitempty"`
-	LastSize              int     `json:"lastSize,omitempty"`
-	Suspended             bool    `json:"suspended,omitempty"`
-	FundingRate           float64 `json:"funding_rate,omitempty"`
-	FundingRatePrediction float64 `json:"funding_rate_prediction,omitempty"`
+	Tag                   string          `json:"tag,omitempty"`
+	Pair                  string          `json:"pair,omitempty"`
+	Symbol                string          `json:"symbol,omitempty"`
+	MarkPrice             decimal.Decimal `json:"markPrice,omitempty"`
+	Bid                   decimal.Decimal `json:"bid,omitempty"`
+	BidSize               decimal.Decimal `json:"bidSize,omitempty"`
+	Ask                   decimal.Decimal `json:"ask,omitempty"`
+	AskSize               decimal.Decimal `json:"askSize,omitempty"`
+	Vol24h                decimal.Decimal `json:"vol24h,omitempty"`
+	OpenInterest          decimal.Decimal `json:"openInterest,omitempty"`
+	Open24H               decimal.Decimal `json:"open24h,omitempty"`
+	Last                  decimal.Decimal `json:"last,omitempty"`
+	LastTime              string          `json:"lastTime,omitempty"`
+	LastSize              decimal.Decimal `json:"lastSize,omitempty"`
+	Suspended             bool            `json:"suspended,omitempty"`
+	FundingRate           float64         `json:"funding_rate,omitempty"`
+	FundingRatePrediction float64         `json:"funding_rate_prediction,omitempty"`
+}
+
+func (a CandlesArguments) validate() error {
+	switch a.TickType {
+	case krakenFuturesWSSDK.TradePriceSource, krakenFuturesWSSDK.MarkPriceSource, krakenFuturesWSSDK.SpotPriceSource:
+	default:
+		return fmt.Errorf("%s: unknown tick type %q", ErrInvalidCandlesArguments, a.TickType)
+	}
+	if _, ok := krakenFuturesWSSDK.ResolutionDuration(a.Resolution); !ok {
+		return fmt.Errorf("%s: unknown resolution %q", ErrInvalidCandlesArguments, a.Resolution)
+	}
+	if a.Symbol == "" {
+		return fmt.Errorf("%s: empty symbol", ErrInvalidCandlesArguments)
+	}
+	if !a.To.IsZero() && !a.From.Before(a.To) {
+		return fmt.Errorf("%s: from must be before to", ErrInvalidCandlesArguments)
+	}
+	return nil
+}
+
+// toCandle converts candle of charts API to candle of websocket feed, time is converted to seconds
+func (c ChartCandle) toCandle() krakenFuturesWSSDK.Candle {
+	return krakenFuturesWSSDK.Candle{
+		Time:   int(c.Time / 1000),
+		Open:   c.Open,
+		High:   c.High,
+		Low:    c.Low,
+		Close:  c.Close,
+		Volume: c.Volume,
+	}
 }
@@ -0,0 +1,78 @@
+package krakenFuturesSDK
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstrument_RoundPrice(t *testing.T) {
+	tests := []struct {
+		name     string
+		tickSize string
+		price    string
+		want     string
+	}{
+		{name: "Half dollar tick", tickSize: "0.5", price: "41234.26", want: "41234.5"},
+		{name: "Tick smaller than cent", tickSize: "0.0001", price: "0.123456", want: "0.1235"},
+		{name: "Price on tick", tickSize: "0.01", price: "2999.99", want: "2999.99"},
+		{name: "Unknown tick size", tickSize: "0", price: "1.23456", want: "1.23456"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			instrument := Instrument{TickSize: decimal.RequireFromString(test.tickSize)}
+			got := instrument.RoundPrice(decimal.RequireFromString(test.price))
+			assert.True(t, decimal.RequireFromString(test.want).Equal(got), "got %s", got)
+		})
+	}
+}
+
+func TestInstrument_IsValidSize(t *testing.T) {
+	tests := []struct {
+		name      string
+		precision int
+		size      string
+		want      bool
+	}{
+		{name: "Whole contracts", precision: 0, size: "3", want: true},
+		{name: "Fraction of whole contracts", precision: 0, size: "0.5", want: false},
+		{name: "Fractional contracts", precision: 4, size: "0.0015", want: true},
+		{name: "Too precise size", precision: 4, size: "0.00015", want: false},
+		{name: "Multiple of ten", precision: -1, size: "20", want: true},
+		{name: "Not multiple of ten", precision: -1, size: "25", want: false},
+		{name: "Zero size", precision: 4, size: "0", want: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			instrument := Instrument{ContractValueTradePrecision: test.precision}
+			assert.Equal(t, test.want, instrument.IsValidSize(decimal.RequireFromString(test.size)))
+		})
+	}
+}
+
+func TestOrderEvent_UnmarshalJSON(t *testing.T) {
+	var event OrderEvent
+	err := json.Unmarshal([]byte(`{"type":"EXECUTION","amount":0.0015,"price":41234.5,`+
+		`"orderPriorExecution":{"quantity":0.0015,"limitPrice":41234.5,"filled":0}}`), &event)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "0.0015", event.Amount.String())
+	assert.Equal(t, "41234.5", event.Price.String())
+	assert.Equal(t, "0.0015", event.OrderPriorExecution.Quantity.String())
+}
+
+func TestBatchInstruction_numbers(t *testing.T) {
+	data, err := json.Marshal(batchInstruction{
+		Order:      BatchSend,
+		Size:       jsonNumber(decimal.RequireFromString("0.0015")),
+		LimitPrice: jsonNumber(decimal.RequireFromString("41234.5")),
+		StopPrice:  jsonNumber(decimal.Zero),
+	})
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"order":"send","size":0.0015,"limitPrice":41234.5}`, string(data))
+}
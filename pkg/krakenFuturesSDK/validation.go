@@ -0,0 +1,115 @@
+package krakenFuturesSDK
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// codes of ValidationError which kraken does not have, rejections which kraken reports too use its status codes
+const (
+	CodeUnknownInstrument = "unknownInstrument"
+	CodeInvalidInstrument = "invalidInstrument"
+	CodeNoMarginLevel     = "noMarginLevel"
+)
+
+const (
+	postOrderType       = "post"
+	stopOrderType       = "stp"
+	takeProfitOrderType = "take_profit"
+)
+
+// ValidationError is order rejected locally before it was sent to kraken.
+// It is inspectable with errors.As like APIError
+type ValidationError struct {
+	Symbol string
+	Code   string
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid order for %s: %s: %s", e.Symbol, e.Code, e.Reason)
+}
+
+// Kind of ValidationError is kind of kraken status with the same code, own codes are invalid arguments
+func (e *ValidationError) Kind() ErrorKind {
+	if kind, ok := errorKinds[e.Code]; ok {
+		return kind
+	}
+	return KindInvalidArgument
+}
+
+func NewValidationError(symbol, code, format string, args ...interface{}) *ValidationError {
+	return &ValidationError{Symbol: symbol, Code: code, Reason: fmt.Sprintf(format, args...)}
+}
+
+// MarginLevel returns margin tier which applies to position of such size
+func (i Instrument) MarginLevel(size decimal.Decimal) (MarginLevel, bool) {
+	var (
+		level MarginLevel
+		found bool
+	)
+	for _, l := range i.MarginLevels {
+		if l.Contracts.GreaterThan(size.Abs()) {
+			break
+		}
+		level, found = l, true
+	}
+	return level, found
+}
+
+// ValidateOrder checks order against instrument and returns it with prices rounded to tick size.
+// Orders which kraken would reject for instrument are returned with ValidationError
+func (i Instrument) ValidateOrder(args SendOrderArguments, now time.Time) (SendOrderArguments, error) {
+	if !i.Tradeable {
+		return args, NewValidationError(i.Symbol, "marketSuspended", "instrument is not tradeable")
+	}
+
+	if i.LastTradingTime != "" {
+		lastTradingTime, err := time.Parse(time.RFC3339, i.LastTradingTime)
+		if err == nil && !now.Before(lastTradingTime) {
+			return args, NewValidationError(i.Symbol, "marketInactive", "last trading time was %s", i.LastTradingTime)
+		}
+	}
+
+	if !i.TickSize.IsPositive() || !i.ContractSize.IsPositive() {
+		return args, NewValidationError(i.Symbol, CodeInvalidInstrument, "tick size or contract size of instrument is unknown")
+	}
+
+	if !i.IsValidSize(args.Size) {
+		return args, NewValidationError(i.Symbol, "invalidSize", "size %s is not positive multiple of %s", args.Size, i.SizeStep())
+	}
+	if i.MaxPositionSize.IsPositive() && args.Size.GreaterThan(i.MaxPositionSize) {
+		return args, NewValidationError(i.Symbol, "maxPositionViolation", "size %s is greater than max position size %s",
+			args.Size, i.MaxPositionSize)
+	}
+	if _, ok := i.MarginLevel(args.Size); !ok {
+		return args, NewValidationError(i.Symbol, CodeNoMarginLevel, "instrument has no margin level for size %s", args.Size)
+	}
+
+	if i.PostOnly && args.OrderType != postOrderType {
+		return args, NewValidationError(i.Symbol, "invalidOrderType", "instrument accepts only %s orders", postOrderType)
+	}
+
+	args.LimitPrice = i.RoundPrice(args.LimitPrice)
+	args.StopPrice = i.RoundPrice(args.StopPrice)
+	if args.LimitPrice.IsNegative() || args.StopPrice.IsNegative() {
+		return args, NewValidationError(i.Symbol, "invalidPrice", "price is negative")
+	}
+
+	switch args.OrderType {
+	case "lmt", postOrderType, "ioc":
+		if args.LimitPrice.IsZero() {
+			return args, NewValidationError(i.Symbol, "invalidPrice", "%s order requires limit price of at least tick size %s",
+				args.OrderType, i.TickSize)
+		}
+	case stopOrderType, takeProfitOrderType:
+		if args.StopPrice.IsZero() {
+			return args, NewValidationError(i.Symbol, "invalidPrice", "%s order requires stop price of at least tick size %s",
+				args.OrderType, i.TickSize)
+		}
+	}
+
+	return args, nil
+}
@@ -0,0 +1,149 @@
+package krakenFuturesSDK
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstrument_ValidateOrder(t *testing.T) {
+	d := decimal.RequireFromString
+	now := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+	perpetual := Instrument{
+		Symbol:                      "pf_xbtusd",
+		Tradeable:                   true,
+		TickSize:                    d("0.5"),
+		ContractSize:                d("1"),
+		ContractValueTradePrecision: 4,
+		MaxPositionSize:             d("100"),
+		MarginLevels:                []MarginLevel{{Contracts: d("0")}, {Contracts: d("10")}},
+	}
+	with := func(change func(i *Instrument)) Instrument {
+		instrument := perpetual
+		change(&instrument)
+		return instrument
+	}
+
+	tests := []struct {
+		name           string
+		instrument     Instrument
+		args           SendOrderArguments
+		wantLimitPrice string
+		wantCode       string
+		wantKind       ErrorKind
+	}{
+		{
+			name:           "Limit price is rounded",
+			instrument:     perpetual,
+			args:           SendOrderArguments{OrderType: "lmt", Size: d("0.0015"), LimitPrice: d("41234.26")},
+			wantLimitPrice: "41234.5",
+		},
+		{
+			name:           "Market order without price",
+			instrument:     perpetual,
+			args:           SendOrderArguments{OrderType: "mkt", Size: d("20")},
+			wantLimitPrice: "0",
+		},
+		{
+			name:       "Not tradeable",
+			instrument: with(func(i *Instrument) { i.Tradeable = false }),
+			args:       SendOrderArguments{OrderType: "mkt", Size: d("1")},
+			wantCode:   "marketSuspended",
+			wantKind:   KindMarketClosed,
+		},
+		{
+			name:       "Expired",
+			instrument: with(func(i *Instrument) { i.LastTradingTime = "2022-04-29T16:00:00.000Z" }),
+			args:       SendOrderArguments{OrderType: "mkt", Size: d("1")},
+			wantCode:   "marketInactive",
+			wantKind:   KindMarketClosed,
+		},
+		{
+			name:       "Unknown contract size",
+			instrument: with(func(i *Instrument) { i.ContractSize = decimal.Decimal{} }),
+			args:       SendOrderArguments{OrderType: "mkt", Size: d("1")},
+			wantCode:   CodeInvalidInstrument,
+			wantKind:   KindInvalidArgument,
+		},
+		{
+			name:       "Too precise size",
+			instrument: perpetual,
+			args:       SendOrderArguments{OrderType: "mkt", Size: d("0.00015")},
+			wantCode:   "invalidSize",
+			wantKind:   KindInvalidArgument,
+		},
+		{
+			name:       "Size above max position",
+			instrument: perpetual,
+			args:       SendOrderArguments{OrderType: "mkt", Size: d("100.5")},
+			wantCode:   "maxPositionViolation",
+			wantKind:   KindInvalidArgument,
+		},
+		{
+			name:       "No margin levels",
+			instrument: with(func(i *Instrument) { i.MarginLevels = nil }),
+			args:       SendOrderArguments{OrderType: "mkt", Size: d("1")},
+			wantCode:   CodeNoMarginLevel,
+			wantKind:   KindInvalidArgument,
+		},
+		{
+			name:       "Limit price below tick",
+			instrument: perpetual,
+			args:       SendOrderArguments{OrderType: "lmt", Size: d("1"), LimitPrice: d("0.2")},
+			wantCode:   "invalidPrice",
+			wantKind:   KindInvalidArgument,
+		},
+		{
+			name:       "Stop order without stop price",
+			instrument: perpetual,
+			args:       SendOrderArguments{OrderType: "stp", Size: d("1"), LimitPrice: d("100")},
+			wantCode:   "invalidPrice",
+			wantKind:   KindInvalidArgument,
+		},
+		{
+			name:       "Post only instrument",
+			instrument: with(func(i *Instrument) { i.PostOnly = true }),
+			args:       SendOrderArguments{OrderType: "lmt", Size: d("1"), LimitPrice: d("100")},
+			wantCode:   "invalidOrderType",
+			wantKind:   KindInvalidArgument,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.instrument.ValidateOrder(test.args, now)
+			if test.wantCode != "" {
+				var validationErr *ValidationError
+				assert.True(t, errors.As(err, &validationErr))
+				assert.Equal(t, test.wantCode, validationErr.Code)
+				assert.Equal(t, test.wantKind, validationErr.Kind())
+				assert.Equal(t, "pf_xbtusd", validationErr.Symbol)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.wantLimitPrice, got.LimitPrice.String())
+		})
+	}
+}
+
+func TestInstrument_MarginLevel(t *testing.T) {
+	d := decimal.RequireFromString
+	instrument := Instrument{MarginLevels: []MarginLevel{
+		{Contracts: d("0"), InitialMargin: 0.02},
+		{Contracts: d("500"), InitialMargin: 0.04},
+	}}
+
+	level, ok := instrument.MarginLevel(d("499.9"))
+	assert.True(t, ok)
+	assert.Equal(t, 0.02, level.InitialMargin)
+
+	level, ok = instrument.MarginLevel(d("-500"))
+	assert.True(t, ok)
+	assert.Equal(t, 0.04, level.InitialMargin)
+
+	_, ok = Instrument{}.MarginLevel(d("1"))
+	assert.False(t, ok)
+}
@@ -0,0 +1,544 @@
+package krakenFuturesWSSDK
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	ErrSubscribe        = errors.New("subscribe to public feed")
+	ErrUnsubscribe      = errors.New("unsubscribe from public feed")
+	ErrSubscribeTimeout = errors.New("kraken did not confirm subscription in time")
+	ErrConnectionLost   = errors.New("connection to kraken websocket is lost")
+	ErrDecodeMessage    = errors.New("unable to decode message of feed")
+	ErrSlowConsumer     = errors.New("consumer does not keep up, message of feed is dropped")
+)
+
+const (
+	// subscriberBufferSize is number of messages kept for consumer, messages are dropped when it is full,
+	// so slow consumer never holds up the connection shared with others
+	subscriberBufferSize = 64
+	subscribeTimeout     = 10 * time.Second
+)
+
+// snapshotFeeds send snapshot to every new subscription, so their new consumer subscribes even to
+// already subscribed product, otherwise it would not get the snapshot to start from
+var snapshotFeeds = map[string]bool{
+	BookFeed: true,
+}
+
+type subscriptionKey struct {
+	feed string
+	// productID is upper case as kraken sends it, it is empty for feeds without products like heartbeat
+	productID string
+}
+
+type subscriber struct {
+	keys []subscriptionKey
+	typ  reflect.Type
+
+	// mu guards ch, so message is never sent to channel closed by remove
+	mu     sync.Mutex
+	ch     chan interface{}
+	closed bool
+}
+
+// send passes message to consumer without waiting, it returns false when message was dropped
+func (s *subscriber) send(val interface{}) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return true
+	}
+	select {
+	case s.ch <- val:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *subscriber) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+	s.closed = true
+	close(s.ch)
+}
+
+type pendingSubscription struct {
+	feed string
+	ack  chan KrakenSendMessageResponse
+}
+
+// confirmation is result of subscription sent for the first consumer of products. Consumers which come
+// before kraken confirms it wait for it too, so nobody is left registered to products kraken has refused
+type confirmation struct {
+	// keys are products which were registered by the subscription, they are rolled back when it fails
+	keys []subscriptionKey
+	ack  <-chan KrakenSendMessageResponse
+
+	done chan struct{}
+	err  error
+}
+
+func (c *confirmation) wait(ctx context.Context) error {
+	select {
+	case <-c.done:
+		return c.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// messageHeader is enough of any kraken message to route it to consumers
+type messageHeader struct {
+	KrakenSendMessageResponse
+	ProductID string `json:"product_id"`
+}
+
+// connectionManager shares one connection to public feeds among all of their consumers. Subscriptions are
+// reference counted per feed and product - kraken is subscribed with the first consumer of product and
+// unsubscribed when the last one cancels, connection is closed when nothing is subscribed anymore
+type connectionManager struct {
+	api *WSAPI
+
+	mu   sync.RWMutex
+	conn *websocket.Conn
+	// reconnecting is set while lost connection waits for the next connect, new subscribers
+	// are subscribed by it then
+	reconnecting bool
+	subscribers  map[subscriptionKey]map[*subscriber]struct{}
+	// confirming has subscriptions of products kraken has not confirmed yet
+	confirming map[subscriptionKey]*confirmation
+
+	// writeMu serializes writes to connection and keeps subscriptions waiting for kraken to confirm them
+	// in order they were sent
+	writeMu sync.Mutex
+	pending []pendingSubscription
+}
+
+func newConnectionManager(api *WSAPI) *connectionManager {
+	return &connectionManager{
+		api:         api,
+		subscribers: make(map[subscriptionKey]map[*subscriber]struct{}),
+		confirming:  make(map[subscriptionKey]*confirmation),
+	}
+}
+
+// subscribe sends every message of feed for products to returned channel, message is decoded to new value
+// of typ's type for every consumer. Channel is closed when ctx is done, lost connection is restored meanwhile
+func (m *connectionManager) subscribe(ctx context.Context, feed string, productIDs []string, typ interface{}) (<-chan interface{}, error) {
+	sub := &subscriber{
+		keys: subscriptionKeys(feed, productIDs),
+		typ:  reflect.TypeOf(typ).Elem(),
+		ch:   make(chan interface{}, subscriberBufferSize),
+	}
+
+	own, pending, err := m.add(sub)
+	if err == nil && own != nil {
+		err = waitForSubscription(ctx, own.ack)
+		m.confirmed(own, err)
+	}
+	for _, c := range pending {
+		if err == nil {
+			err = c.wait(ctx)
+		}
+	}
+	if err != nil {
+		m.remove(sub)
+		return nil, fmt.Errorf("%s: %w", ErrSubscribe, err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		m.remove(sub)
+	}()
+
+	return sub.ch, nil
+}
+
+// add registers subscriber and subscribes to its products which nobody has subscribed to yet. Returned
+// confirmation is of subscription sent for subscriber, it is nil when nothing had to be subscribed. Pending
+// are subscriptions of its products sent for earlier consumers which kraken has not confirmed yet
+func (m *connectionManager) add(sub *subscriber) (*confirmation, []*confirmation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// dial is made without lock, so it does not hold up consumers of the shared connection.
+	// Connection dialed by another consumer meanwhile is used instead
+	for m.conn == nil && !m.reconnecting {
+		m.mu.Unlock()
+		conn, err := m.api.establishConnect()
+		m.mu.Lock()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if m.conn != nil || m.reconnecting {
+			conn.Close()
+			continue
+		}
+		m.conn = conn
+		go m.read(conn)
+	}
+
+	var (
+		newKeys   []subscriptionKey
+		firstKeys []subscriptionKey
+		pending   []*confirmation
+	)
+	for _, key := range sub.keys {
+		subs, ok := m.subscribers[key]
+		if !ok {
+			subs = make(map[*subscriber]struct{})
+			m.subscribers[key] = subs
+		}
+		if c, ok := m.confirming[key]; ok {
+			pending = append(pending, c)
+		}
+		if len(subs) == 0 {
+			firstKeys = append(firstKeys, key)
+		}
+		if len(subs) == 0 || snapshotFeeds[key.feed] {
+			newKeys = append(newKeys, key)
+		}
+		subs[sub] = struct{}{}
+	}
+	if len(newKeys) == 0 || m.conn == nil {
+		return nil, pending, nil
+	}
+
+	ack, err := m.send(m.conn, KrakenSendMessageArguments{
+		Event:      "subscribe",
+		Feed:       newKeys[0].feed,
+		ProductIDs: productIDsOf(newKeys),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	own := &confirmation{keys: firstKeys, ack: ack, done: make(chan struct{})}
+	for _, key := range firstKeys {
+		m.confirming[key] = own
+	}
+	return own, pending, nil
+}
+
+// confirmed passes result of subscription to consumers which wait for it. Products of failed subscription are
+// unregistered with all their consumers, so the next consumer subscribes to them again
+func (m *connectionManager) confirmed(c *confirmation, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, key := range c.keys {
+		delete(m.confirming, key)
+		if err != nil {
+			delete(m.subscribers, key)
+		}
+	}
+	c.err = err
+	close(c.done)
+}
+
+// remove unregisters subscriber and closes its channel. Products nobody consumes anymore are unsubscribed
+func (m *connectionManager) remove(sub *subscriber) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// products of failed subscription may be already unregistered by confirmed
+	var unusedKeys []subscriptionKey
+	for _, key := range sub.keys {
+		subs := m.subscribers[key]
+		if _, ok := subs[sub]; !ok {
+			continue
+		}
+		delete(subs, sub)
+		if len(subs) == 0 {
+			delete(m.subscribers, key)
+			unusedKeys = append(unusedKeys, key)
+		}
+	}
+	sub.close()
+
+	if len(m.subscribers) == 0 {
+		m.closeConn()
+		return
+	}
+	if len(unusedKeys) == 0 || m.conn == nil {
+		return
+	}
+
+	args := KrakenSendMessageArguments{Event: "unsubscribe", Feed: unusedKeys[0].feed, ProductIDs: productIDsOf(unusedKeys)}
+	if err := m.write(m.conn, args); err != nil {
+		log.Warnf("%s: %s", ErrUnsubscribe, err)
+	}
+}
+
+// read routes messages of connection to consumers until connection is closed
+func (m *connectionManager) read(conn *websocket.Conn) {
+	stopKeepAlive := m.api.keepAlive(conn)
+	defer stopKeepAlive()
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			m.reconnect(conn, err)
+			return
+		}
+
+		var header messageHeader
+		if err := json.Unmarshal(message, &header); err != nil {
+			log.Warnf("%s: %s", ErrDecodeMessage, err)
+			continue
+		}
+
+		switch header.Event {
+		case "":
+			m.dispatch(header, message)
+		case "subscribed", "subscribed_failed", "error":
+			m.confirm(header.KrakenSendMessageResponse)
+		case "unsubscribed_failed":
+			log.Warnf("%s: %s %s", ErrUnsubscribe, header.Feed, header.Message)
+		}
+	}
+}
+
+// dispatch decodes message for every consumer of its feed and product
+func (m *connectionManager) dispatch(header messageHeader, message []byte) {
+	key := subscriptionKey{
+		feed:      strings.TrimSuffix(header.Feed, snapshotSuffix),
+		productID: strings.ToUpper(header.ProductID),
+	}
+
+	// subscribers are copied, so lock is not held while messages are decoded and sent
+	m.mu.RLock()
+	subs := make([]*subscriber, 0, len(m.subscribers[key]))
+	for sub := range m.subscribers[key] {
+		subs = append(subs, sub)
+	}
+	m.mu.RUnlock()
+
+	for _, sub := range subs {
+		val := reflect.New(sub.typ).Interface()
+		if err := json.Unmarshal(message, val); err != nil {
+			log.Warnf("%s: %s: %s", ErrDecodeMessage, header.Feed, err)
+			continue
+		}
+
+		if !sub.send(val) {
+			log.Warnf("%s: %s", ErrSlowConsumer, header.Feed)
+		}
+	}
+}
+
+// confirm passes response of kraken to the oldest subscription of its feed which waits for it.
+// Errors are not tied to feed, so they go to the oldest subscription of any feed
+func (m *connectionManager) confirm(response KrakenSendMessageResponse) {
+	m.writeMu.Lock()
+	defer m.writeMu.Unlock()
+
+	for i, pending := range m.pending {
+		if response.Feed == "" || pending.feed == response.Feed {
+			pending.ack <- response
+			m.pending = append(m.pending[:i], m.pending[i+1:]...)
+			return
+		}
+	}
+}
+
+// reconnect replaces lost connection with new one which is subscribed to everything consumers still use.
+// It waits longer before every next attempt and gives up only when nothing is subscribed anymore.
+// Lock is not held while connection waits and dials, so consumers may come and go meanwhile. Reconnecting is
+// reset under the same lock the new connection is swapped in, connection may be lost again right after it
+func (m *connectionManager) reconnect(conn *websocket.Conn, err error) {
+	m.mu.Lock()
+	// connection was closed because nothing is subscribed
+	if m.conn != conn {
+		m.mu.Unlock()
+		return
+	}
+	m.closeConn()
+	m.reconnecting = true
+	feeds := m.feeds()
+	m.mu.Unlock()
+
+	lostAt := time.Now()
+	log.Warnf("%s: %s", ErrConnectionLost, err)
+	m.api.events.publish(ConnectionEvent{State: ConnectionLost, Feeds: feeds, Err: err, LostAt: lostAt})
+
+	for attempt := 0; ; attempt++ {
+		time.Sleep(m.api.reconnect.backoff(attempt))
+
+		m.mu.Lock()
+		if len(m.subscribers) == 0 {
+			m.reconnecting = false
+			m.mu.Unlock()
+			return
+		}
+		m.mu.Unlock()
+
+		conn, err := m.api.establishConnect()
+		if err != nil {
+			log.Warnf("%s: %s", ErrConnectionLost, err)
+			continue
+		}
+		feeds, err := m.resubscribe(conn)
+		if err != nil {
+			log.Warnf("%s: %s", ErrConnectionLost, err)
+			continue
+		}
+		if feeds == nil {
+			return
+		}
+
+		m.api.events.publish(ConnectionEvent{State: ConnectionRestored, Feeds: feeds, LostAt: lostAt,
+			Attempts: attempt + 1})
+		return
+	}
+}
+
+// resubscribe swaps in new connection and subscribes it to all products of every feed, it returns subscribed feeds.
+// Connection is closed when nothing is subscribed anymore, feeds are nil then
+func (m *connectionManager) resubscribe(conn *websocket.Conn) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.subscribers) == 0 {
+		m.reconnecting = false
+		conn.Close()
+		return nil, nil
+	}
+
+	m.conn = conn
+	feeds := make(map[string][]subscriptionKey)
+	for key := range m.subscribers {
+		feeds[key.feed] = append(feeds[key.feed], key)
+	}
+	for feed, keys := range feeds {
+		if _, err := m.send(conn, KrakenSendMessageArguments{Event: "subscribe", Feed: feed, ProductIDs: productIDsOf(keys)}); err != nil {
+			m.closeConn()
+			return nil, err
+		}
+	}
+
+	m.reconnecting = false
+	go m.read(conn)
+	return m.feeds(), nil
+}
+
+// send writes subscription to connection and returns channel which gets confirmation of kraken
+func (m *connectionManager) send(conn *websocket.Conn, args KrakenSendMessageArguments) (<-chan KrakenSendMessageResponse, error) {
+	m.writeMu.Lock()
+	defer m.writeMu.Unlock()
+
+	if err := m.api.writeJSON(conn, args); err != nil {
+		return nil, fmt.Errorf("%s: %w", ErrUnableToWriteMessage, err)
+	}
+
+	ack := make(chan KrakenSendMessageResponse, 1)
+	m.pending = append(m.pending, pendingSubscription{feed: args.Feed, ack: ack})
+	return ack, nil
+}
+
+func (m *connectionManager) write(conn *websocket.Conn, args KrakenSendMessageArguments) error {
+	m.writeMu.Lock()
+	defer m.writeMu.Unlock()
+
+	if err := m.api.writeJSON(conn, args); err != nil {
+		return fmt.Errorf("%s: %w", ErrUnableToWriteMessage, err)
+	}
+	return nil
+}
+
+// closeConn closes connection, subscriptions which wait for confirmation from it are failed
+func (m *connectionManager) closeConn() {
+	if m.conn == nil {
+		return
+	}
+	m.conn.Close()
+	m.conn = nil
+
+	m.writeMu.Lock()
+	defer m.writeMu.Unlock()
+	for _, pending := range m.pending {
+		close(pending.ack)
+	}
+	m.pending = nil
+}
+
+// feeds returns every subscribed feed once
+func (m *connectionManager) feeds() []string {
+	seen := make(map[string]bool)
+	var feeds []string
+	for key := range m.subscribers {
+		if !seen[key.feed] {
+			seen[key.feed] = true
+			feeds = append(feeds, key.feed)
+		}
+	}
+	sort.Strings(feeds)
+	return feeds
+}
+
+func waitForSubscription(ctx context.Context, ack <-chan KrakenSendMessageResponse) error {
+	timer := time.NewTimer(subscribeTimeout)
+	defer timer.Stop()
+
+	select {
+	case response, ok := <-ack:
+		if !ok {
+			return ErrConnectionLost
+		}
+		if response.Event != "subscribed" {
+			return fmt.Errorf("%w: %s %s", ErrCouldNotSubscribeToFeed, response.Event, response.Message)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return ErrSubscribeTimeout
+	}
+}
+
+func subscriptionKeys(feed string, productIDs []string) []subscriptionKey {
+	if len(productIDs) == 0 {
+		return []subscriptionKey{{feed: feed}}
+	}
+
+	keys := make([]subscriptionKey, 0, len(productIDs))
+	seen := make(map[string]bool, len(productIDs))
+	for _, productID := range productIDs {
+		productID = strings.ToUpper(productID)
+		if !seen[productID] {
+			seen[productID] = true
+			keys = append(keys, subscriptionKey{feed: feed, productID: productID})
+		}
+	}
+	return keys
+}
+
+// productIDsOf returns products of keys, it is nil for feeds without products
+func productIDsOf(keys []subscriptionKey) []string {
+	var productIDs []string
+	for _, key := range keys {
+		if key.productID != "" {
+			productIDs = append(productIDs, key.productID)
+		}
+	}
+	return productIDs
+}
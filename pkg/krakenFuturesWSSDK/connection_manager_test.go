@@ -0,0 +1,381 @@
+package krakenFuturesWSSDK
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+// dropConnection makes test server close connection without close message like broken network does
+const dropConnection = "drop"
+
+// newMultiplexTestServer confirms every subscription, passes events it receives to returned channel
+// and sends messages of send channel to the last connection
+func newMultiplexTestServer(t *testing.T) (*httptest.Server, <-chan KrakenSendMessageArguments, chan<- string, *int32) {
+	var connected int32
+	events := make(chan KrakenSendMessageArguments, 16)
+	send := make(chan string)
+	upgrader := websocket.Upgrader{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		atomic.AddInt32(&connected, 1)
+
+		var writeMu sync.Mutex
+		_ = conn.WriteJSON(map[string]string{"event": "info", "version": "1"})
+
+		closed := make(chan struct{})
+		go func() {
+			defer close(closed)
+			for {
+				var event KrakenSendMessageArguments
+				if err := conn.ReadJSON(&event); err != nil {
+					return
+				}
+				events <- event
+
+				writeMu.Lock()
+				_ = conn.WriteJSON(map[string]interface{}{"event": event.Event + "d", "feed": event.Feed,
+					"product_ids": event.ProductIDs})
+				writeMu.Unlock()
+			}
+		}()
+
+		for {
+			select {
+			case <-closed:
+				return
+			case message := <-send:
+				if message == dropConnection {
+					return
+				}
+				writeMu.Lock()
+				_ = conn.WriteMessage(websocket.TextMessage, []byte(message))
+				writeMu.Unlock()
+			}
+		}
+	}))
+	return server, events, send, &connected
+}
+
+func TestWSAPI_SharedConnection(t *testing.T) {
+	const (
+		xbtCandle = `{"feed":"candles_trade_1m","product_id":"PI_XBTUSD","candle":{"time":60000,"close":"100"}}`
+		ethCandle = `{"feed":"candles_trade_1m","product_id":"PI_ETHUSD","candle":{"time":60000,"close":"10"}}`
+	)
+	server, events, send, connected := newMultiplexTestServer(t)
+	defer server.Close()
+	api := newTestWSAPI(server)
+
+	ctxXBT, cancelXBT := context.WithCancel(context.Background())
+	defer cancelXBT()
+	xbt, err := api.CandlesTrade(ctxXBT, OneMinuteCandlesFeed, []string{"PI_XBTUSD"})
+	assert.NoError(t, err)
+	assert.Equal(t, KrakenSendMessageArguments{Event: "subscribe", Feed: OneMinuteCandlesFeed,
+		ProductIDs: []string{"PI_XBTUSD"}}, <-events)
+
+	// product which is already subscribed is not subscribed again
+	ctxBoth, cancelBoth := context.WithCancel(context.Background())
+	defer cancelBoth()
+	both, err := api.CandlesTrade(ctxBoth, OneMinuteCandlesFeed, []string{"pi_xbtusd", "PI_ETHUSD"})
+	assert.NoError(t, err)
+	assert.Equal(t, KrakenSendMessageArguments{Event: "subscribe", Feed: OneMinuteCandlesFeed,
+		ProductIDs: []string{"PI_ETHUSD"}}, <-events)
+
+	send <- xbtCandle
+	send <- ethCandle
+	assert.Equal(t, "100", (<-xbt).Candle.Close.String())
+	fromBoth := []string{(<-both).Candle.Close.String(), (<-both).Candle.Close.String()}
+	assert.ElementsMatch(t, []string{"100", "10"}, fromBoth)
+
+	// the last consumer of product unsubscribes it, other products are still sent
+	cancelBoth()
+	for range both {
+	}
+	assert.Equal(t, KrakenSendMessageArguments{Event: "unsubscribe", Feed: OneMinuteCandlesFeed,
+		ProductIDs: []string{"PI_ETHUSD"}}, <-events)
+
+	send <- xbtCandle
+	candle := <-xbt
+	assert.Equal(t, "PI_XBTUSD", candle.ProductID)
+	assert.Equal(t, int32(1), atomic.LoadInt32(connected))
+
+	cancelXBT()
+	for range xbt {
+	}
+}
+
+func TestWSAPI_SlowConsumer(t *testing.T) {
+	server, events, send, _ := newMultiplexTestServer(t)
+	defer server.Close()
+	api := newTestWSAPI(server)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	slow, err := api.CandlesTrade(ctx, OneMinuteCandlesFeed, []string{"PI_XBTUSD"})
+	assert.NoError(t, err)
+	<-events
+	fast, err := api.CandlesTrade(ctx, OneMinuteCandlesFeed, []string{"PI_XBTUSD"})
+	assert.NoError(t, err)
+
+	// consumer which does not read does not hold up the others, its messages are dropped
+	for i := 1; i <= subscriberBufferSize*2; i++ {
+		send <- fmt.Sprintf(`{"feed":"candles_trade_1m","product_id":"PI_XBTUSD","candle":{"time":%d}}`, i)
+		assert.Equal(t, i, (<-fast).Candle.Time)
+	}
+
+	cancel()
+	for range fast {
+	}
+	for range slow {
+	}
+}
+
+func TestWSAPI_SubscribeFailed(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+
+		_ = conn.WriteJSON(map[string]string{"event": "info", "version": "1"})
+		var subscription KrakenSendMessageArguments
+		_ = conn.ReadJSON(&subscription)
+		_ = conn.WriteJSON(map[string]string{"event": "error", "message": "Invalid product id"})
+		_, _, _ = conn.ReadMessage()
+	}))
+	defer server.Close()
+	api := newTestWSAPI(server)
+
+	_, err := api.CandlesTrade(context.Background(), OneMinuteCandlesFeed, []string{"PI_UNKNOWN"})
+	assert.ErrorIs(t, err, ErrCouldNotSubscribeToFeed)
+	assert.Contains(t, err.Error(), "Invalid product id")
+
+	// failed subscription is not kept, so connection is closed
+	api.public.mu.RLock()
+	defer api.public.mu.RUnlock()
+	assert.Empty(t, api.public.subscribers)
+	assert.Nil(t, api.public.conn)
+}
+
+func TestWSAPI_SubscribeFailedForWaitingConsumer(t *testing.T) {
+	refuse := make(chan struct{})
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+
+		_ = conn.WriteJSON(map[string]string{"event": "info", "version": "1"})
+		var subscription KrakenSendMessageArguments
+		_ = conn.ReadJSON(&subscription)
+		<-refuse
+		_ = conn.WriteJSON(map[string]string{"event": "error", "message": "Invalid product id"})
+		_, _, _ = conn.ReadMessage()
+	}))
+	defer server.Close()
+	api := newTestWSAPI(server)
+
+	errs := make(chan error, 2)
+	subscribe := func() {
+		_, err := api.CandlesTrade(context.Background(), OneMinuteCandlesFeed, []string{"PI_UNKNOWN"})
+		errs <- err
+	}
+	go subscribe()
+	assert.Eventually(t, func() bool { return subscribersOf(api, OneMinuteCandlesFeed, "PI_UNKNOWN") == 1 },
+		time.Second, time.Millisecond)
+
+	// the second consumer comes while kraken has not answered the first one, it is not subscribed again
+	go subscribe()
+	assert.Eventually(t, func() bool { return subscribersOf(api, OneMinuteCandlesFeed, "PI_UNKNOWN") == 2 },
+		time.Second, time.Millisecond)
+	close(refuse)
+
+	assert.ErrorIs(t, <-errs, ErrCouldNotSubscribeToFeed)
+	assert.ErrorIs(t, <-errs, ErrCouldNotSubscribeToFeed)
+
+	// product is unregistered with both consumers, so connection is closed
+	api.public.mu.RLock()
+	defer api.public.mu.RUnlock()
+	assert.Empty(t, api.public.subscribers)
+	assert.Empty(t, api.public.confirming)
+	assert.Nil(t, api.public.conn)
+}
+
+func TestWSAPI_UnsubscribeWhileReconnecting(t *testing.T) {
+	var connections int32
+	drop := make(chan struct{})
+	dialing := make(chan struct{}, 1)
+	dialed := make(chan struct{})
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// dial of reconnect hangs until test lets it fail
+		if atomic.AddInt32(&connections, 1) > 1 {
+			select {
+			case dialing <- struct{}{}:
+			default:
+			}
+			<-dialed
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+
+		_ = conn.WriteJSON(map[string]string{"event": "info", "version": "1"})
+		var subscription KrakenSendMessageArguments
+		_ = conn.ReadJSON(&subscription)
+		_ = conn.WriteJSON(map[string]interface{}{"event": "subscribed", "feed": subscription.Feed,
+			"product_ids": subscription.ProductIDs})
+		<-drop
+	}))
+	defer server.Close()
+	defer close(dialed)
+	api := newTestWSAPI(server)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	xbt, err := api.CandlesTrade(ctx, OneMinuteCandlesFeed, []string{"PI_XBTUSD"})
+	assert.NoError(t, err)
+
+	close(drop)
+	<-dialing
+
+	// consumer is removed while connection is being dialed
+	cancel()
+	removed := make(chan struct{})
+	go func() {
+		defer close(removed)
+		for range xbt {
+		}
+	}()
+	select {
+	case <-removed:
+	case <-time.After(time.Second):
+		t.Fatal("unsubscribe waits for dial")
+	}
+}
+
+func TestWSAPI_Reconnect(t *testing.T) {
+	const xbtCandle = `{"feed":"candles_trade_1m","product_id":"PI_XBTUSD","candle":{"time":60000,"close":"100"}}`
+	server, events, send, connected := newMultiplexTestServer(t)
+	defer server.Close()
+	api := newTestWSAPI(server)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	states := api.ConnectionEvents(ctx)
+	xbt, err := api.CandlesTrade(ctx, OneMinuteCandlesFeed, []string{"PI_XBTUSD"})
+	assert.NoError(t, err)
+	heartbeat, err := api.Heartbeat(ctx)
+	assert.NoError(t, err)
+	<-events
+	<-events
+
+	send <- dropConnection
+	lost := <-states
+	assert.Equal(t, ConnectionLost, lost.State)
+	assert.Equal(t, []string{OneMinuteCandlesFeed, "heartbeat"}, lost.Feeds)
+	assert.Error(t, lost.Err)
+
+	// new connection is subscribed to every feed which is still consumed
+	resubscribed := []KrakenSendMessageArguments{<-events, <-events}
+	assert.ElementsMatch(t, []KrakenSendMessageArguments{
+		{Event: "subscribe", Feed: OneMinuteCandlesFeed, ProductIDs: []string{"PI_XBTUSD"}},
+		{Event: "subscribe", Feed: "heartbeat"},
+	}, resubscribed)
+
+	restored := <-states
+	assert.Equal(t, ConnectionRestored, restored.State)
+	assert.Equal(t, lost.LostAt, restored.LostAt)
+	assert.Equal(t, int32(2), atomic.LoadInt32(connected))
+
+	send <- xbtCandle
+	send <- `{"feed":"heartbeat","time":1650000000000}`
+	assert.Equal(t, "100", (<-xbt).Candle.Close.String())
+	assert.Equal(t, 1650000000000, (<-heartbeat).Time)
+
+	cancel()
+	for range xbt {
+	}
+	for range heartbeat {
+	}
+}
+
+func TestReconnectPolicy_backoff(t *testing.T) {
+	policy := reconnectPolicy{baseDelay: 100 * time.Millisecond, maxDelay: time.Second}
+
+	for attempt, max := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond,
+		400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second} {
+		delay := policy.backoff(attempt)
+		assert.GreaterOrEqual(t, delay, max/2)
+		assert.LessOrEqual(t, delay, max)
+	}
+	// shift of base delay overflows for late attempts
+	assert.LessOrEqual(t, policy.backoff(100), time.Second)
+}
+
+func TestConnectionEvents_PublishToDrainingListener(t *testing.T) {
+	events := newConnectionEvents()
+	ctx, cancel := context.WithCancel(context.Background())
+	listener := events.listen(ctx)
+
+	// listener drains concurrently while its buffer is full, publish must never block on it
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range listener {
+		}
+	}()
+
+	published := make(chan struct{})
+	go func() {
+		defer close(published)
+		for i := 0; i < 100*connectionEventsBufferSize; i++ {
+			events.publish(ConnectionEvent{State: ConnectionLost, Attempts: i})
+		}
+	}()
+
+	select {
+	case <-published:
+	case <-time.After(5 * time.Second):
+		t.Fatal("publish is blocked")
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("listener is not closed")
+	}
+}
+
+func subscribersOf(api *WSAPI, feed, productID string) int {
+	api.public.mu.RLock()
+	defer api.public.mu.RUnlock()
+	return len(api.public.subscribers[subscriptionKey{feed: feed, productID: productID}])
+}
@@ -2,8 +2,13 @@ package krakenFuturesWSSDK
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
 	"fmt"
 	"net/http"
+	"reflect"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -22,75 +27,356 @@ var (
 	ErrCouldNotSubscribeToFeed  = errors.New("could not subscribe to feed")
 	ErrConnect                  = errors.New("connect to ws")
 	ErrLoopOverWS               = errors.New("loop over ws")
+	ErrRequestChallenge         = errors.New("request challenge")
+	ErrSignChallenge            = errors.New("sign challenge")
+	ErrDecodePrivateKey         = errors.New("unable to decode private api key")
 )
 
 const (
 	maxEstablishConnectCounter = 10
 )
 
+// WSAPI subscribes to kraken futures feeds. Public feeds of all consumers share one connection,
+// private feeds are served by own connection for every subscription
 type WSAPI struct {
 	ws             *websocket.Dialer
 	wsAPIURL       string
 	requestsConfig configs.KrakenWSAPIRequestsConfiguration
+	reconnect      reconnectPolicy
+	public         *connectionManager
+	events         *connectionEvents
 }
 
 func NewWSAPI(config configs.KrakenWSConfiguration) *WSAPI {
-	return &WSAPI{
+	api := &WSAPI{
 		ws:             websocket.DefaultDialer,
 		wsAPIURL:       config.Kraken.WSAPIURL,
 		requestsConfig: config.Requests,
+		reconnect:      defaultReconnectPolicy,
+		events:         newConnectionEvents(),
 	}
+	api.public = newConnectionManager(api)
+	return api
 }
 
 // -------------------------- PUBLIC KRAKEN WEBSOCKET API ENDPOINTS -------------------------- //
 
 func (a *WSAPI) Heartbeat(ctx context.Context) (<-chan *HeartbeatSubscriptionData, error) {
 	heartbeatCh := make(chan *HeartbeatSubscriptionData)
-	hearbeatArgs := KrakenSendMessageArguments{
-		Event: "subscribe",
-		Feed:  "heartbeat",
-	}
 
-	dataCh, errCh, err := a.serveWS(ctx, hearbeatArgs, &HeartbeatSubscriptionData{})
+	dataCh, err := a.public.subscribe(ctx, "heartbeat", nil, &HeartbeatSubscriptionData{})
 	if err != nil {
 		return nil, err
 	}
 
-	go logErrors(errCh)
 	go func() {
 		defer close(heartbeatCh)
 		for val := range dataCh {
-			heartbeatCh <- val.(*HeartbeatSubscriptionData)
+			select {
+			case heartbeatCh <- val.(*HeartbeatSubscriptionData):
+			case <-ctx.Done():
+				return
+			}
 		}
 	}()
 
 	return heartbeatCh, nil
 }
 
+// CandlesTrade sends candles of feed which is any candles feed - trade, mark or spot one of any resolution
 func (a *WSAPI) CandlesTrade(ctx context.Context, feed string, productIDs []string) (<-chan *CandlesTradeData, error) {
-	candlesTradeCh := make(chan *CandlesTradeData)
-	candlesArgs := KrakenSendMessageArguments{
-		Event:      "subscribe",
-		Feed:       feed,
-		ProductIDs: productIDs,
+	if _, _, err := ParseCandlesFeed(feed); err != nil {
+		return nil, err
 	}
+	candlesTradeCh := make(chan *CandlesTradeData)
 
-	dataCh, errCh, err := a.serveWS(ctx, candlesArgs, &CandlesTradeData{})
+	dataCh, err := a.public.subscribe(ctx, feed, productIDs, &CandlesTradeData{})
 	if err != nil {
 		return nil, err
 	}
 
-	go logErrors(errCh)
 	go func() {
 		defer close(candlesTradeCh)
 		for val := range dataCh {
-			candlesTradeCh <- val.(*CandlesTradeData)
+			select {
+			case candlesTradeCh <- val.(*CandlesTradeData):
+			case <-ctx.Done():
+				return
+			}
 		}
 	}()
 
 	return candlesTradeCh, nil
 }
 
+// Book sends snapshot of order book of every product and then changes of its levels
+func (a *WSAPI) Book(ctx context.Context, productIDs []string) (<-chan *BookData, error) {
+	bookCh := make(chan *BookData)
+
+	dataCh, err := a.public.subscribe(ctx, BookFeed, productIDs, &BookData{})
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		defer close(bookCh)
+		for val := range dataCh {
+			select {
+			case bookCh <- val.(*BookData):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return bookCh, nil
+}
+
+// Trade sends snapshot of recent trades of every product and then every new trade
+func (a *WSAPI) Trade(ctx context.Context, productIDs []string) (<-chan *TradeData, error) {
+	tradeCh := make(chan *TradeData)
+
+	dataCh, err := a.public.subscribe(ctx, TradeFeed, productIDs, &TradeData{})
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		defer close(tradeCh)
+		for val := range dataCh {
+			select {
+			case tradeCh <- val.(*TradeData):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return tradeCh, nil
+}
+
+func (a *WSAPI) Ticker(ctx context.Context, productIDs []string) (<-chan *TickerData, error) {
+	tickerCh := make(chan *TickerData)
+
+	dataCh, err := a.public.subscribe(ctx, TickerFeed, productIDs, &TickerData{})
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		defer close(tickerCh)
+		for val := range dataCh {
+			select {
+			case tickerCh <- val.(*TickerData):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return tickerCh, nil
+}
+
+func (a *WSAPI) TickerLite(ctx context.Context, productIDs []string) (<-chan *TickerLiteData, error) {
+	tickerLiteCh := make(chan *TickerLiteData)
+
+	dataCh, err := a.public.subscribe(ctx, TickerLiteFeed, productIDs, &TickerLiteData{})
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		defer close(tickerLiteCh)
+		for val := range dataCh {
+			select {
+			case tickerLiteCh <- val.(*TickerLiteData):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return tickerLiteCh, nil
+}
+
+// ------------------------------------------------------------------------------------------- //
+
+// -------------------------- PRIVATE KRAKEN WEBSOCKET API ENDPOINTS -------------------------- //
+
+// PrivateWSAPI subscribes to private feeds of account. Every connection is authenticated with
+// challenge which kraken sends for api key and which is signed with private api key
+type PrivateWSAPI struct {
+	*WSAPI
+	apiPublicKey  string
+	apiPrivateKey string
+}
+
+func (a *WSAPI) Private(apiPublicKey, apiPrivateKey string) *PrivateWSAPI {
+	return &PrivateWSAPI{WSAPI: a, apiPublicKey: apiPublicKey, apiPrivateKey: apiPrivateKey}
+}
+
+// OpenOrders sends snapshot of open orders and then every change of them
+func (a *PrivateWSAPI) OpenOrders(ctx context.Context) (<-chan *OpenOrdersData, error) {
+	openOrdersCh := make(chan *OpenOrdersData)
+
+	dataCh, errCh, err := a.serveWS(ctx, KrakenSendMessageArguments{Event: "subscribe", Feed: OpenOrdersFeed},
+		a.signChallenge, &OpenOrdersData{})
+	if err != nil {
+		return nil, err
+	}
+
+	go logErrors(errCh)
+	go func() {
+		defer close(openOrdersCh)
+		for val := range dataCh {
+			select {
+			case openOrdersCh <- val.(*OpenOrdersData):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return openOrdersCh, nil
+}
+
+// Fills sends snapshot of recent fills and then every new fill, liquidations included
+func (a *PrivateWSAPI) Fills(ctx context.Context) (<-chan *FillsData, error) {
+	fillsCh := make(chan *FillsData)
+
+	dataCh, errCh, err := a.serveWS(ctx, KrakenSendMessageArguments{Event: "subscribe", Feed: FillsFeed},
+		a.signChallenge, &FillsData{})
+	if err != nil {
+		return nil, err
+	}
+
+	go logErrors(errCh)
+	go func() {
+		defer close(fillsCh)
+		for val := range dataCh {
+			select {
+			case fillsCh <- val.(*FillsData):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return fillsCh, nil
+}
+
+// OpenPositions sends all open positions every time one of them changes
+func (a *PrivateWSAPI) OpenPositions(ctx context.Context) (<-chan *OpenPositionsData, error) {
+	openPositionsCh := make(chan *OpenPositionsData)
+
+	dataCh, errCh, err := a.serveWS(ctx, KrakenSendMessageArguments{Event: "subscribe", Feed: OpenPositionsFeed},
+		a.signChallenge, &OpenPositionsData{})
+	if err != nil {
+		return nil, err
+	}
+
+	go logErrors(errCh)
+	go func() {
+		defer close(openPositionsCh)
+		for val := range dataCh {
+			select {
+			case openPositionsCh <- val.(*OpenPositionsData):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return openPositionsCh, nil
+}
+
+func (a *PrivateWSAPI) AccountBalancesAndMargins(ctx context.Context) (<-chan *AccountBalancesAndMarginsData, error) {
+	balancesCh := make(chan *AccountBalancesAndMarginsData)
+
+	dataCh, errCh, err := a.serveWS(ctx, KrakenSendMessageArguments{Event: "subscribe", Feed: AccountBalancesAndMarginsFeed},
+		a.signChallenge, &AccountBalancesAndMarginsData{})
+	if err != nil {
+		return nil, err
+	}
+
+	go logErrors(errCh)
+	go func() {
+		defer close(balancesCh)
+		for val := range dataCh {
+			select {
+			case balancesCh <- val.(*AccountBalancesAndMarginsData):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return balancesCh, nil
+}
+
+// NotificationsAuth sends notifications of kraken for account like maintenance or settlement
+func (a *PrivateWSAPI) NotificationsAuth(ctx context.Context) (<-chan *NotificationsAuthData, error) {
+	notificationsCh := make(chan *NotificationsAuthData)
+
+	dataCh, errCh, err := a.serveWS(ctx, KrakenSendMessageArguments{Event: "subscribe", Feed: NotificationsAuthFeed},
+		a.signChallenge, &NotificationsAuthData{})
+	if err != nil {
+		return nil, err
+	}
+
+	go logErrors(errCh)
+	go func() {
+		defer close(notificationsCh)
+		for val := range dataCh {
+			select {
+			case notificationsCh <- val.(*NotificationsAuthData):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return notificationsCh, nil
+}
+
+// signChallenge requests challenge for api key on connection and adds it to subscription with its signature
+func (a *PrivateWSAPI) signChallenge(conn *websocket.Conn, args KrakenSendMessageArguments) (KrakenSendMessageArguments, error) {
+	if err := a.writeJSON(conn, challengeRequest{Event: "challenge", APIKey: a.apiPublicKey}); err != nil {
+		return args, fmt.Errorf("%s: %s: %w", ErrRequestChallenge, ErrUnableToWriteMessage, err)
+	}
+
+	var response KrakenSendMessageResponse
+	if err := conn.ReadJSON(&response); err != nil {
+		return args, fmt.Errorf("%s: %s: %w", ErrRequestChallenge, ErrUnableToReadMessage, err)
+	}
+	if response.Event != "challenge" || response.Message == "" {
+		return args, fmt.Errorf("%s: unexpected response: %s %s", ErrRequestChallenge, response.Event, response.Message)
+	}
+
+	signed, err := signChallenge(response.Message, a.apiPrivateKey)
+	if err != nil {
+		return args, err
+	}
+
+	args.APIKey = a.apiPublicKey
+	args.OriginalChallenge = response.Message
+	args.SignedChallenge = signed
+	return args, nil
+}
+
+// signChallenge hashes challenge with sha256 and signs the hash with hmac-sha512 keyed by base64 decoded private api key
+func signChallenge(challenge, apiPrivateKey string) (string, error) {
+	key, err := base64.StdEncoding.DecodeString(apiPrivateKey)
+	if err != nil {
+		return "", fmt.Errorf("%s: %s: %w", ErrSignChallenge, ErrDecodePrivateKey, err)
+	}
+
+	sha := sha256.Sum256([]byte(challenge))
+	mac := hmac.New(sha512.New, key)
+	mac.Write(sha[:])
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
 // ------------------------------------------------------------------------------------------- //
 
 func logErrors(errCh <-chan error) {
@@ -99,13 +385,16 @@ func logErrors(errCh <-chan error) {
 	}
 }
 
-func (a *WSAPI) serveWS(ctx context.Context, args KrakenSendMessageArguments, typ interface{}) (<-chan interface{}, <-chan error, error) {
-	conn, err := a.connect(args)
+// signer authenticates subscription to private feed on new connection, it is nil for public feeds
+type signer func(conn *websocket.Conn, args KrakenSendMessageArguments) (KrakenSendMessageArguments, error)
+
+func (a *WSAPI) serveWS(ctx context.Context, args KrakenSendMessageArguments, sign signer, typ interface{}) (<-chan interface{}, <-chan error, error) {
+	conn, err := a.connect(args, sign)
 	if err != nil {
 		return nil, nil, fmt.Errorf("%s: %w", ErrServeWS, err)
 	}
 
-	dataCh, errCh := a.loopOverWS(ctx, conn, args, typ)
+	dataCh, errCh := a.loopOverWS(ctx, conn, args, sign, typ)
 	return dataCh, errCh, nil
 }
 
@@ -134,7 +423,7 @@ func (a *WSAPI) establishConnect() (*websocket.Conn, error) {
 }
 
 func (a *WSAPI) sendEvent(conn *websocket.Conn, args KrakenSendMessageArguments) (KrakenSendMessageResponse, error) {
-	if err := conn.WriteJSON(args); err != nil {
+	if err := a.writeJSON(conn, args); err != nil {
 		return KrakenSendMessageResponse{}, fmt.Errorf("%s: %s: %w", ErrSubscribeToFeed, ErrUnableToWriteMessage, err)
 	}
 
@@ -143,57 +432,110 @@ func (a *WSAPI) sendEvent(conn *websocket.Conn, args KrakenSendMessageArguments)
 	if err != nil {
 		return response, fmt.Errorf("%s: %s: %w", ErrSubscribeToFeed, ErrUnableToReadMessage, err)
 	} else if response.Event != "subscribed" {
-		return response, fmt.Errorf("%s: %s", ErrSubscribeToFeed, ErrCouldNotSubscribeToFeed)
+		return response, fmt.Errorf("%s: %s: %s %s", ErrSubscribeToFeed, ErrCouldNotSubscribeToFeed, response.Event, response.Message)
 	}
 
 	return response, nil
 }
 
-func (a *WSAPI) loopOverWS(ctx context.Context, conn *websocket.Conn, args KrakenSendMessageArguments, typ interface{}) (<-chan interface{}, <-chan error) {
+// loopOverWS decodes every message to new value of typ's type, so values which were sent are never overwritten.
+// Connection is kept alive with pings and is reconnected with backoff after any error until ctx is done
+func (a *WSAPI) loopOverWS(ctx context.Context, conn *websocket.Conn, args KrakenSendMessageArguments, sign signer,
+	typ interface{}) (<-chan interface{}, <-chan error) {
 	loopChan := make(chan interface{})
 	errChan := make(chan error, 1)
 
-	conn.SetReadLimit(int64(a.requestsConfig.MaxMessageSize))
-	conn.SetPongHandler(func(string) error {
-		return conn.SetReadDeadline(time.Now().Add(time.Second * time.Duration(a.requestsConfig.PongWaitInSeconds)))
-	})
-
-	go func() {
-		<-ctx.Done()
-		conn.Close()
-	}()
-
 	go func() {
 		defer close(loopChan)
 		defer close(errChan)
 
 		for {
-			err := conn.ReadJSON(&typ)
-			if err != nil {
-				if websocket.IsCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-					conn, err = a.connect(args)
-					if err != nil {
-						errChan <- fmt.Errorf("%s: %w", ErrLoopOverWS, err)
-						break
-					}
-					continue
-				}
-				break
+			err := a.readWS(ctx, conn, typ, loopChan)
+			if ctx.Err() != nil {
+				return
+			}
+
+			lostAt := time.Now()
+			a.events.publish(ConnectionEvent{State: ConnectionLost, Feeds: []string{args.Feed}, Err: err, LostAt: lostAt})
+			select {
+			case errChan <- fmt.Errorf("%s: %s: %w", ErrLoopOverWS, ErrConnectionLost, err):
+			case <-ctx.Done():
+				return
+			}
+
+			var attempts int
+			if conn, attempts, err = a.reconnectWS(ctx, args, sign); err != nil {
+				return
 			}
-			loopChan <- typ
+			a.events.publish(ConnectionEvent{State: ConnectionRestored, Feeds: []string{args.Feed}, LostAt: lostAt,
+				Attempts: attempts})
 		}
 	}()
 
 	return loopChan, errChan
 }
 
-func (a *WSAPI) connect(args KrakenSendMessageArguments) (*websocket.Conn, error) {
+// readWS sends messages of connection to loopChan until connection fails or ctx is done, connection is closed then
+func (a *WSAPI) readWS(ctx context.Context, conn *websocket.Conn, typ interface{}, loopChan chan<- interface{}) error {
+	stopKeepAlive := a.keepAlive(conn)
+	defer stopKeepAlive()
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		conn.Close()
+	}()
+
+	for {
+		val := reflect.New(reflect.TypeOf(typ).Elem()).Interface()
+		if err := conn.ReadJSON(val); err != nil {
+			return err
+		}
+
+		select {
+		case loopChan <- val:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// reconnectWS connects and subscribes again, it waits longer before every next attempt
+func (a *WSAPI) reconnectWS(ctx context.Context, args KrakenSendMessageArguments, sign signer) (*websocket.Conn, int, error) {
+	for attempt := 0; ; attempt++ {
+		select {
+		case <-ctx.Done():
+			return nil, attempt, ctx.Err()
+		case <-time.After(a.reconnect.backoff(attempt)):
+		}
+
+		conn, err := a.connect(args, sign)
+		if err == nil {
+			return conn, attempt + 1, nil
+		}
+		log.Warnf("%s: %s", ErrLoopOverWS, err)
+	}
+}
+
+func (a *WSAPI) connect(args KrakenSendMessageArguments, sign signer) (*websocket.Conn, error) {
 	conn, err := a.establishConnect()
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", ErrConnect, err)
 	}
 
+	if sign != nil {
+		if args, err = sign(conn, args); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("%s: %w", ErrConnect, err)
+		}
+	}
+
 	if _, err := a.sendEvent(conn, args); err != nil {
+		conn.Close()
 		return nil, fmt.Errorf("%s: %w", ErrConnect, err)
 	}
 
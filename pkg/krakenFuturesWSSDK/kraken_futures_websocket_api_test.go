@@ -0,0 +1,218 @@
+package krakenFuturesWSSDK
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testPrivateKey = "a3Jha2VuLWZ1dHVyZXMtdGVzdC1zZWNyZXQta2V5LTAxMjM0NTY3ODk="
+	testChallenge  = "c100b894-1729-464d-ace1-52dbce11db42"
+	// golden signature is produced by independent implementation of kraken futures challenge signing
+	testSignedChallenge = "oASEJRFmetDZteEMFzHbhzNLfpE/PUkb0p1R3MQo0lY58pS83I+ZjOj99db8ZMmCDDpBa6sB1iJnoDRSKzKvRg=="
+)
+
+func TestSignChallenge(t *testing.T) {
+	signed, err := signChallenge(testChallenge, testPrivateKey)
+	assert.NoError(t, err)
+	assert.Equal(t, testSignedChallenge, signed)
+
+	_, err = signChallenge(testChallenge, "not base64!")
+	assert.Contains(t, err.Error(), ErrDecodePrivateKey.Error())
+}
+
+// newTestServer serves kraken websocket handshake and sends messages after subscription to feed
+func newTestServer(t *testing.T, feed string, messages ...string) *httptest.Server {
+	upgrader := websocket.Upgrader{}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+
+		_ = conn.WriteJSON(map[string]string{"event": "info", "version": "1"})
+
+		var challenge challengeRequest
+		assert.NoError(t, conn.ReadJSON(&challenge))
+		assert.Equal(t, "challenge", challenge.Event)
+		assert.Equal(t, "public", challenge.APIKey)
+		_ = conn.WriteJSON(map[string]string{"event": "challenge", "message": testChallenge})
+
+		var subscription KrakenSendMessageArguments
+		assert.NoError(t, conn.ReadJSON(&subscription))
+		assert.Equal(t, KrakenSendMessageArguments{Event: "subscribe", Feed: feed, APIKey: "public",
+			OriginalChallenge: testChallenge, SignedChallenge: testSignedChallenge}, subscription)
+		_ = conn.WriteJSON(map[string]string{"event": "subscribed", "feed": feed})
+
+		for _, message := range messages {
+			_ = conn.WriteMessage(websocket.TextMessage, []byte(message))
+		}
+		_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	}))
+}
+
+func newTestPrivateWSAPI(server *httptest.Server) *PrivateWSAPI {
+	return newTestWSAPI(server).Private("public", testPrivateKey)
+}
+
+func TestPrivateWSAPI_OpenOrders(t *testing.T) {
+	server := newTestServer(t, OpenOrdersFeed,
+		`{"feed":"open_orders_snapshot","account":"acc","orders":[{"instrument":"PF_XBTUSD","time":1650000000000,`+
+			`"last_update_time":1650000000000,"qty":0.0015,"filled":0,"limit_price":41234.5,"stop_price":0,"type":"limit",`+
+			`"order_id":"1","direction":1,"reduce_only":false}]}`,
+		`{"feed":"open_orders","order_id":"1","is_cancel":true,"reason":"cancelled_by_user"}`)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	orders, err := newTestPrivateWSAPI(server).OpenOrders(ctx)
+	assert.NoError(t, err)
+
+	snapshot := <-orders
+	assert.True(t, snapshot.IsSnapshot())
+	assert.Len(t, snapshot.Orders, 1)
+	assert.Equal(t, "0.0015", snapshot.Orders[0].Qty.String())
+	assert.Equal(t, "41234.5", snapshot.Orders[0].LimitPrice.String())
+	assert.Equal(t, "sell", snapshot.Orders[0].Side())
+
+	cancelled := <-orders
+	assert.False(t, cancelled.IsSnapshot())
+	assert.True(t, cancelled.IsCancel)
+	assert.Equal(t, "1", cancelled.OrderID)
+	assert.Nil(t, cancelled.Order)
+	// snapshot is not overwritten by the next message
+	assert.Len(t, snapshot.Orders, 1)
+}
+
+func TestPrivateWSAPI_Fills(t *testing.T) {
+	server := newTestServer(t, FillsFeed,
+		`{"feed":"fills","username":"user","fills":[{"instrument":"PF_XBTUSD","time":1650000000000,"price":39000,`+
+			`"seq":2,"buy":false,"qty":0.0015,"order_id":"2","fill_id":"f2","fill_type":"liquidation",`+
+			`"fee_paid":0.03,"fee_currency":"USD","order_type":"liquidation"}]}`)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fills, err := newTestPrivateWSAPI(server).Fills(ctx)
+	assert.NoError(t, err)
+
+	data := <-fills
+	assert.Len(t, data.Fills, 1)
+	assert.True(t, data.Fills[0].IsLiquidation())
+	assert.Equal(t, "0.03", data.Fills[0].FeePaid.String())
+}
+
+func TestPrivateWSAPI_Reconnect(t *testing.T) {
+	// server closes connection after every message, so every message is sent by new connection
+	server := newTestServer(t, OpenPositionsFeed, `{"feed":"open_positions","account":"acc","positions":[]}`)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	api := newTestPrivateWSAPI(server)
+	events := api.ConnectionEvents(ctx)
+	positions, err := api.OpenPositions(ctx)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "acc", (<-positions).Account)
+	lost := <-events
+	assert.Equal(t, ConnectionLost, lost.State)
+	assert.Equal(t, []string{OpenPositionsFeed}, lost.Feeds)
+	assert.Error(t, lost.Err)
+
+	restored := <-events
+	assert.Equal(t, ConnectionRestored, restored.State)
+	assert.Equal(t, lost.LostAt, restored.LostAt)
+	assert.Equal(t, 1, restored.Attempts)
+	assert.Equal(t, "acc", (<-positions).Account)
+
+	cancel()
+	for range positions {
+	}
+}
+
+func TestParseCandlesFeed(t *testing.T) {
+	tests := []struct {
+		feed            string
+		wantPriceSource string
+		wantResolution  string
+		wantErr         bool
+	}{
+		{feed: OneMinuteCandlesFeed, wantPriceSource: TradePriceSource, wantResolution: OneMinuteResolution},
+		{feed: TwelveHoursMarkCandlesFeed, wantPriceSource: MarkPriceSource, wantResolution: TwelveHoursResolution},
+		{feed: OneWeekSpotCandlesFeed, wantPriceSource: SpotPriceSource, wantResolution: OneWeekResolution},
+		{feed: "candles_trade_2m", wantErr: true},
+		{feed: "candles_last_1m", wantErr: true},
+		{feed: TickerFeed, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.feed, func(t *testing.T) {
+			priceSource, resolution, err := ParseCandlesFeed(test.feed)
+			if test.wantErr {
+				assert.ErrorIs(t, err, ErrUnknownCandlesFeed)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.wantPriceSource, priceSource)
+			assert.Equal(t, test.wantResolution, resolution)
+			assert.Equal(t, test.feed, CandlesFeed(priceSource, resolution))
+		})
+	}
+}
+
+func TestWSAPI_TradeAndTicker(t *testing.T) {
+	server, events, send, _ := newMultiplexTestServer(t)
+	defer server.Close()
+	api := newTestWSAPI(server)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	trades, err := api.Trade(ctx, []string{"PI_XBTUSD"})
+	assert.NoError(t, err)
+	<-events
+	tickers, err := api.TickerLite(ctx, []string{"PI_XBTUSD"})
+	assert.NoError(t, err)
+	<-events
+
+	send <- `{"feed":"trade_snapshot","product_id":"PI_XBTUSD","trades":[{"feed":"trade","product_id":"PI_XBTUSD",` +
+		`"uid":"u1","side":"sell","type":"fill","seq":1,"time":1650000000000,"qty":0.5,"price":40000.5}]}`
+	send <- `{"feed":"trade","product_id":"PI_XBTUSD","uid":"u2","side":"buy","type":"liquidation","seq":2,` +
+		`"time":1650000001000,"qty":1,"price":40001}`
+	send <- `{"feed":"ticker_lite","product_id":"PI_XBTUSD","bid":40000.5,"ask":40001,"change":1.5,"premium":0.1,` +
+		`"volume":1000,"tag":"perpetual","pair":"XBT:USD","dtm":0,"maturityTime":0,"volumeQuote":40000000}`
+
+	snapshot := <-trades
+	assert.True(t, snapshot.IsSnapshot())
+	assert.Len(t, snapshot.Trades, 1)
+	assert.Equal(t, "40000.5", snapshot.Trades[0].Price.String())
+
+	trade := <-trades
+	assert.False(t, trade.IsSnapshot())
+	assert.Equal(t, "liquidation", trade.Type)
+	assert.Equal(t, "1", trade.Qty.String())
+
+	ticker := <-tickers
+	assert.Equal(t, "40001", ticker.Ask.String())
+	assert.Equal(t, "perpetual", ticker.Tag)
+
+	_, err = api.CandlesTrade(ctx, "candles_trade_2m", []string{"PI_XBTUSD"})
+	assert.ErrorIs(t, err, ErrUnknownCandlesFeed)
+
+	cancel()
+	for range trades {
+	}
+	for range tickers {
+	}
+}
@@ -0,0 +1,361 @@
+package krakenFuturesWSSDK
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	ErrSequenceGap     = errors.New("gap in sequence of book feed")
+	ErrMaintainBooks   = errors.New("maintain order books")
+	ErrUnknownBookSide = errors.New("unknown side of book level")
+)
+
+const (
+	bidSide = "buy"
+	askSide = "sell"
+
+	// topOfBookBufferSize is number of top of book changes per product kept for slow consumer
+	topOfBookBufferSize = 16
+)
+
+// TopOfBook is the best bid and ask of product, level is zero when its side of book is empty
+type TopOfBook struct {
+	ProductID string
+	Bid       BookLevel
+	Ask       BookLevel
+	Seq       int64
+	Timestamp int64
+}
+
+// OrderBook is L2 order book of product built from snapshot and changes of book feed.
+// It is safe for concurrent use and is not ready until snapshot is applied
+type OrderBook struct {
+	ProductID string
+
+	mu        sync.RWMutex
+	ready     bool
+	seq       int64
+	timestamp int64
+	// bids are sorted by price descending and asks ascending, so the best level is the first one
+	bids []BookLevel
+	asks []BookLevel
+}
+
+func NewOrderBook(productID string) *OrderBook {
+	return &OrderBook{ProductID: productID}
+}
+
+// Apply applies snapshot or change of book feed. Change which does not follow the last applied message
+// makes book not ready and is reported with ErrSequenceGap, book has to be rebuilt from new snapshot then
+func (b *OrderBook) Apply(data *BookData) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if data.IsSnapshot() {
+		b.bids = sortLevels(data.Bids, true)
+		b.asks = sortLevels(data.Asks, false)
+		b.seq, b.timestamp, b.ready = data.Seq, data.Timestamp, true
+		return nil
+	}
+
+	if !b.ready || data.Seq <= b.seq {
+		return nil
+	}
+	if data.Seq != b.seq+1 {
+		b.ready = false
+		return fmt.Errorf("%w: %s expected %d, got %d", ErrSequenceGap, b.ProductID, b.seq+1, data.Seq)
+	}
+
+	level := BookLevel{Price: data.Price, Qty: data.Qty}
+	switch data.Side {
+	case bidSide:
+		b.bids = setLevel(b.bids, level, true)
+	case askSide:
+		b.asks = setLevel(b.asks, level, false)
+	default:
+		return fmt.Errorf("%s: %s", ErrUnknownBookSide, data.Side)
+	}
+	b.seq, b.timestamp = data.Seq, data.Timestamp
+	return nil
+}
+
+// Reset makes book not ready until the next snapshot
+func (b *OrderBook) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.ready, b.bids, b.asks = false, nil, nil
+}
+
+func (b *OrderBook) Ready() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.ready
+}
+
+func (b *OrderBook) BestBid() (BookLevel, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if !b.ready || len(b.bids) == 0 {
+		return BookLevel{}, false
+	}
+	return b.bids[0], true
+}
+
+func (b *OrderBook) BestAsk() (BookLevel, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if !b.ready || len(b.asks) == 0 {
+		return BookLevel{}, false
+	}
+	return b.asks[0], true
+}
+
+// Spread is difference between the best ask and the best bid
+func (b *OrderBook) Spread() (decimal.Decimal, bool) {
+	bid, okBid := b.BestBid()
+	ask, okAsk := b.BestAsk()
+	if !okBid || !okAsk {
+		return decimal.Zero, false
+	}
+	return ask.Price.Sub(bid.Price), true
+}
+
+// Depth returns copies of up to levels best bids and asks
+func (b *OrderBook) Depth(levels int) ([]BookLevel, []BookLevel) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if !b.ready {
+		return nil, nil
+	}
+	return copyLevels(b.bids, levels), copyLevels(b.asks, levels)
+}
+
+// FillPrice estimates average price of market order of side and size which takes liquidity from the best levels.
+// It is false when book is not ready or has not enough liquidity for size
+func (b *OrderBook) FillPrice(side string, size decimal.Decimal) (decimal.Decimal, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	levels := b.asks
+	if side == askSide {
+		levels = b.bids
+	}
+	if !b.ready || !size.IsPositive() {
+		return decimal.Zero, false
+	}
+
+	left, notional := size, decimal.Zero
+	for _, level := range levels {
+		qty := decimal.Min(left, level.Qty)
+		notional = notional.Add(qty.Mul(level.Price))
+		left = left.Sub(qty)
+		if left.IsZero() {
+			return notional.Div(size), true
+		}
+	}
+	return decimal.Zero, false
+}
+
+func (b *OrderBook) top() TopOfBook {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	top := TopOfBook{ProductID: b.ProductID, Seq: b.seq, Timestamp: b.timestamp}
+	if len(b.bids) > 0 {
+		top.Bid = b.bids[0]
+	}
+	if len(b.asks) > 0 {
+		top.Ask = b.asks[0]
+	}
+	return top
+}
+
+// OrderBooks maintains order books of products from one subscription to book feed.
+// Books are rebuilt from new snapshot when gap in sequence of any product is detected
+type OrderBooks struct {
+	api        *WSAPI
+	productIDs []string
+	books      map[string]*OrderBook
+	tops       chan TopOfBook
+}
+
+// OrderBooks subscribes to book feed of products and maintains their books until ctx is done.
+// Product IDs are case insensitive, books are kept under upper case IDs kraken sends
+func (a *WSAPI) OrderBooks(ctx context.Context, productIDs []string) (*OrderBooks, error) {
+	books := &OrderBooks{
+		api:   a,
+		books: make(map[string]*OrderBook, len(productIDs)),
+	}
+	for _, productID := range productIDs {
+		productID = strings.ToUpper(productID)
+		if _, ok := books.books[productID]; ok {
+			continue
+		}
+		books.books[productID] = NewOrderBook(productID)
+		books.productIDs = append(books.productIDs, productID)
+	}
+	books.tops = make(chan TopOfBook, topOfBookBufferSize*len(books.productIDs))
+
+	subCtx, cancel := context.WithCancel(ctx)
+	bookCh, err := a.Book(subCtx, books.productIDs)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("%s: %w", ErrMaintainBooks, err)
+	}
+
+	go books.maintain(ctx, bookCh, cancel)
+	return books, nil
+}
+
+// Book returns book of product, it is updated in background
+func (o *OrderBooks) Book(productID string) (*OrderBook, bool) {
+	book, ok := o.books[strings.ToUpper(productID)]
+	return book, ok
+}
+
+// TopOfBook sends the best bid and ask of product every time one of them changes. The oldest changes
+// are dropped if consumer does not keep up. Channel is closed when books are not maintained anymore
+func (o *OrderBooks) TopOfBook() <-chan TopOfBook {
+	return o.tops
+}
+
+func (o *OrderBooks) maintain(ctx context.Context, bookCh <-chan *BookData, cancel context.CancelFunc) {
+	defer close(o.tops)
+
+	lastTops := make(map[string]TopOfBook, len(o.books))
+	for {
+		gap := o.apply(bookCh, lastTops)
+
+		// subscription is closed and its channel is drained, so its goroutines are not left blocked
+		cancel()
+		for range bookCh {
+		}
+		if !gap || ctx.Err() != nil {
+			return
+		}
+
+		for _, book := range o.books {
+			book.Reset()
+		}
+
+		var subCtx context.Context
+		subCtx, cancel = context.WithCancel(ctx)
+		var err error
+		if bookCh, err = o.api.Book(subCtx, o.productIDs); err != nil {
+			cancel()
+			log.Errorf("%s: %s", ErrMaintainBooks, err)
+			return
+		}
+	}
+}
+
+// apply applies messages to books until feed is closed or gap is detected
+func (o *OrderBooks) apply(bookCh <-chan *BookData, lastTops map[string]TopOfBook) bool {
+	for data := range bookCh {
+		book, ok := o.books[strings.ToUpper(data.ProductID)]
+		if !ok || data.Feed != BookFeed && !data.IsSnapshot() {
+			continue
+		}
+
+		if err := book.Apply(data); err != nil {
+			log.Warnf("%s: %s", ErrMaintainBooks, err)
+			if errors.Is(err, ErrSequenceGap) {
+				return true
+			}
+			continue
+		}
+
+		top := book.top()
+		last := lastTops[book.ProductID]
+		if sameLevel(top.Bid, last.Bid) && sameLevel(top.Ask, last.Ask) {
+			continue
+		}
+		lastTops[book.ProductID] = top
+		o.publish(top)
+	}
+	return false
+}
+
+func (o *OrderBooks) publish(top TopOfBook) {
+	select {
+	case o.tops <- top:
+		return
+	default:
+	}
+
+	// the oldest top is dropped, consumer may drain it concurrently, so neither step blocks
+	select {
+	case <-o.tops:
+	default:
+	}
+	select {
+	case o.tops <- top:
+	default:
+	}
+}
+
+func sortLevels(levels []BookLevel, descending bool) []BookLevel {
+	sorted := make([]BookLevel, 0, len(levels))
+	for _, level := range levels {
+		if level.Qty.IsPositive() {
+			sorted = append(sorted, level)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if descending {
+			return sorted[i].Price.GreaterThan(sorted[j].Price)
+		}
+		return sorted[i].Price.LessThan(sorted[j].Price)
+	})
+	return sorted
+}
+
+// setLevel replaces level with the same price, inserts new one or removes it if its Qty is zero
+func setLevel(levels []BookLevel, level BookLevel, descending bool) []BookLevel {
+	i := sort.Search(len(levels), func(i int) bool {
+		if descending {
+			return levels[i].Price.LessThanOrEqual(level.Price)
+		}
+		return levels[i].Price.GreaterThanOrEqual(level.Price)
+	})
+
+	exists := i < len(levels) && levels[i].Price.Equal(level.Price)
+	switch {
+	case !level.Qty.IsPositive() && exists:
+		return append(levels[:i], levels[i+1:]...)
+	case !level.Qty.IsPositive():
+		return levels
+	case exists:
+		levels[i] = level
+		return levels
+	default:
+		levels = append(levels, BookLevel{})
+		copy(levels[i+1:], levels[i:])
+		levels[i] = level
+		return levels
+	}
+}
+
+func copyLevels(levels []BookLevel, n int) []BookLevel {
+	if n > len(levels) || n <= 0 {
+		n = len(levels)
+	}
+	return append([]BookLevel(nil), levels[:n]...)
+}
+
+func sameLevel(a, b BookLevel) bool {
+	return a.Price.Equal(b.Price) && a.Qty.Equal(b.Qty)
+}
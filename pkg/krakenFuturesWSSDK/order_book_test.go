@@ -0,0 +1,224 @@
+package krakenFuturesWSSDK
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"trade-bot/configs"
+)
+
+func level(price, qty string) BookLevel {
+	return BookLevel{Price: decimal.RequireFromString(price), Qty: decimal.RequireFromString(qty)}
+}
+
+func delta(seq int64, side, price, qty string) *BookData {
+	l := level(price, qty)
+	return &BookData{Feed: BookFeed, ProductID: "PI_XBTUSD", Seq: seq, Side: side, Price: l.Price, Qty: l.Qty}
+}
+
+func assertLevels(t *testing.T, want []BookLevel, got []BookLevel) {
+	assert.Len(t, got, len(want))
+	for i := range want {
+		if i < len(got) {
+			assert.True(t, sameLevel(want[i], got[i]), "level %d: want %v, got %v", i, want[i], got[i])
+		}
+	}
+}
+
+func TestOrderBook_Apply(t *testing.T) {
+	book := NewOrderBook("PI_XBTUSD")
+
+	// changes before snapshot are ignored
+	assert.NoError(t, book.Apply(delta(1, "buy", "100", "1")))
+	_, ok := book.BestBid()
+	assert.False(t, ok)
+
+	assert.NoError(t, book.Apply(&BookData{Feed: "book_snapshot", ProductID: "PI_XBTUSD", Seq: 10,
+		Bids: []BookLevel{level("99", "2"), level("100", "1")},
+		Asks: []BookLevel{level("102", "3"), level("101", "1")},
+	}))
+
+	for _, change := range []*BookData{
+		delta(11, "buy", "100.5", "4"), // new best bid
+		delta(12, "sell", "101", "0"),  // best ask removed
+		delta(13, "buy", "99", "5"),    // level replaced
+		delta(12, "buy", "98", "5"),    // stale change is ignored
+	} {
+		assert.NoError(t, book.Apply(change))
+	}
+
+	bids, asks := book.Depth(0)
+	assertLevels(t, []BookLevel{level("100.5", "4"), level("100", "1"), level("99", "5")}, bids)
+	assertLevels(t, []BookLevel{level("102", "3")}, asks)
+
+	spread, ok := book.Spread()
+	assert.True(t, ok)
+	assert.Equal(t, "1.5", spread.String())
+
+	bids, _ = book.Depth(1)
+	assertLevels(t, []BookLevel{level("100.5", "4")}, bids)
+
+	err := book.Apply(delta(15, "buy", "100", "1"))
+	assert.True(t, errors.Is(err, ErrSequenceGap))
+	assert.False(t, book.Ready())
+	_, ok = book.BestAsk()
+	assert.False(t, ok)
+}
+
+func TestOrderBook_FillPrice(t *testing.T) {
+	book := NewOrderBook("PI_XBTUSD")
+	assert.NoError(t, book.Apply(&BookData{Feed: "book_snapshot", Seq: 1,
+		Bids: []BookLevel{level("100", "1"), level("99", "3")},
+		Asks: []BookLevel{level("101", "2"), level("102", "2")},
+	}))
+
+	tests := []struct {
+		name   string
+		side   string
+		size   string
+		want   string
+		wantOk bool
+	}{
+		{name: "Buy within the best ask", side: "buy", size: "1", want: "101", wantOk: true},
+		{name: "Buy through two levels", side: "buy", size: "4", want: "101.5", wantOk: true},
+		{name: "Sell through two levels", side: "sell", size: "2", want: "99.5", wantOk: true},
+		{name: "Not enough liquidity", side: "sell", size: "5"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, ok := book.FillPrice(test.side, decimal.RequireFromString(test.size))
+			assert.Equal(t, test.wantOk, ok)
+			if test.wantOk {
+				assert.Equal(t, test.want, got.String())
+			}
+		})
+	}
+}
+
+// newPublicTestServer subscribes every connection to feed and sends it messages of connection with the same index
+func newPublicTestServer(t *testing.T, feed string, connections ...[]string) (*httptest.Server, *int32) {
+	var connected int32
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		i := atomic.AddInt32(&connected, 1) - 1
+
+		_ = conn.WriteJSON(map[string]string{"event": "info", "version": "1"})
+
+		var subscription KrakenSendMessageArguments
+		assert.NoError(t, conn.ReadJSON(&subscription))
+		assert.Equal(t, feed, subscription.Feed)
+		_ = conn.WriteJSON(map[string]string{"event": "subscribed", "feed": feed})
+
+		if int(i) < len(connections) {
+			for _, message := range connections[i] {
+				_ = conn.WriteMessage(websocket.TextMessage, []byte(message))
+			}
+		}
+		// connection is kept until client closes it
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	return server, &connected
+}
+
+func newTestWSAPI(server *httptest.Server) *WSAPI {
+	api := NewWSAPI(configs.KrakenWSConfiguration{
+		Requests: configs.KrakenWSAPIRequestsConfiguration{MaxMessageSize: 4096, PongWaitInSeconds: 60},
+		Kraken:   configs.KrakenWSAPIConfiguration{WSAPIURL: "ws" + strings.TrimPrefix(server.URL, "http")},
+	})
+	api.reconnect = reconnectPolicy{baseDelay: time.Millisecond, maxDelay: 10 * time.Millisecond}
+	return api
+}
+
+func TestWSAPI_OrderBooks(t *testing.T) {
+	server, connected := newPublicTestServer(t, BookFeed,
+		[]string{
+			`{"feed":"book_snapshot","product_id":"PI_XBTUSD","seq":1,"bids":[{"price":100,"qty":1}],"asks":[{"price":101,"qty":1}]}`,
+			`{"feed":"book","product_id":"PI_XBTUSD","seq":2,"side":"buy","price":100.5,"qty":2}`,
+			`{"feed":"book","product_id":"PI_XBTUSD","seq":4,"side":"buy","price":100.7,"qty":2}`,
+		},
+		[]string{
+			`{"feed":"book_snapshot","product_id":"PI_XBTUSD","seq":10,"bids":[{"price":100.6,"qty":3}],"asks":[{"price":101,"qty":1}]}`,
+		})
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// product IDs are case insensitive
+	books, err := newTestWSAPI(server).OrderBooks(ctx, []string{"pi_xbtusd"})
+	assert.NoError(t, err)
+
+	var bids []string
+	for top := range books.TopOfBook() {
+		bids = append(bids, top.Bid.Price.String())
+		if top.Seq == 10 {
+			break
+		}
+	}
+	// gap made books resubscribe and rebuild from the new snapshot
+	assert.Equal(t, []string{"100", "100.5", "100.6"}, bids)
+	assert.Equal(t, int32(2), atomic.LoadInt32(connected))
+
+	book, ok := books.Book("Pi_XbtUsd")
+	assert.True(t, ok)
+	assert.Equal(t, "PI_XBTUSD", book.ProductID)
+	bid, ok := book.BestBid()
+	assert.True(t, ok)
+	assert.Equal(t, "3", bid.Qty.String())
+
+	cancel()
+	for range books.TopOfBook() {
+	}
+}
+
+func TestOrderBooks_PublishToDrainingConsumer(t *testing.T) {
+	books := &OrderBooks{tops: make(chan TopOfBook, topOfBookBufferSize)}
+
+	// consumer drains concurrently while buffer is full, publish must never block on it
+	stop := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-books.tops:
+			case <-stop:
+				return
+			}
+		}
+	}()
+	defer close(stop)
+
+	published := make(chan struct{})
+	go func() {
+		defer close(published)
+		for seq := int64(0); seq < 100*topOfBookBufferSize; seq++ {
+			books.publish(TopOfBook{ProductID: "PI_XBTUSD", Seq: seq})
+		}
+	}()
+
+	select {
+	case <-published:
+	case <-time.After(5 * time.Second):
+		t.Fatal("publish is blocked")
+	}
+}
@@ -0,0 +1,177 @@
+package krakenFuturesWSSDK
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+type ConnectionState string
+
+const (
+	// ConnectionLost is sent when connection fails, messages of its feeds are missed until it is restored
+	ConnectionLost ConnectionState = "lost"
+	// ConnectionRestored is sent when new connection is subscribed to all feeds of the lost one
+	ConnectionRestored ConnectionState = "restored"
+)
+
+// connectionEventsBufferSize is number of events kept for slow listener, the oldest events are dropped
+const connectionEventsBufferSize = 16
+
+// ConnectionEvent tells listener that connection to kraken has changed its state
+type ConnectionEvent struct {
+	State ConnectionState
+	// Feeds are served by connection
+	Feeds []string
+	// Err is why connection was lost, it is nil when connection is restored
+	Err error
+	// LostAt is when connection was lost, so listener knows which period it has missed
+	LostAt time.Time
+	// Attempts is number of connects it took to restore connection
+	Attempts int
+	Time     time.Time
+}
+
+type connectionEvents struct {
+	mu        sync.Mutex
+	listeners map[chan ConnectionEvent]struct{}
+}
+
+func newConnectionEvents() *connectionEvents {
+	return &connectionEvents{listeners: make(map[chan ConnectionEvent]struct{})}
+}
+
+// ConnectionEvents sends state changes of every connection of api, private ones included, until ctx is done.
+// The oldest events are dropped if listener does not keep up
+func (a *WSAPI) ConnectionEvents(ctx context.Context) <-chan ConnectionEvent {
+	return a.events.listen(ctx)
+}
+
+func (e *connectionEvents) listen(ctx context.Context) <-chan ConnectionEvent {
+	listener := make(chan ConnectionEvent, connectionEventsBufferSize)
+
+	e.mu.Lock()
+	e.listeners[listener] = struct{}{}
+	e.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		delete(e.listeners, listener)
+		close(listener)
+	}()
+
+	return listener
+}
+
+func (e *connectionEvents) publish(event ConnectionEvent) {
+	event.Time = time.Now()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for listener := range e.listeners {
+		select {
+		case listener <- event:
+			continue
+		default:
+		}
+
+		// buffer is full, the oldest event is dropped, listener may drain it concurrently,
+		// so neither step blocks while lock is held
+		select {
+		case <-listener:
+		default:
+		}
+		select {
+		case listener <- event:
+		default:
+		}
+	}
+}
+
+// reconnectPolicy sets how long lost connection waits before every connect, delay grows exponentially
+// from baseDelay up to maxDelay and is randomized, so clients don't reconnect in lockstep
+type reconnectPolicy struct {
+	baseDelay time.Duration
+	maxDelay  time.Duration
+}
+
+var defaultReconnectPolicy = reconnectPolicy{
+	baseDelay: time.Second,
+	maxDelay:  time.Minute,
+}
+
+// backoff returns delay before connect which follows attempt, it is random value in [delay/2, delay]
+func (p reconnectPolicy) backoff(attempt int) time.Duration {
+	delay := p.baseDelay << attempt
+	if delay > p.maxDelay || delay <= 0 {
+		delay = p.maxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// keepAlive pings kraken every ping period, connection which does not answer with pong within pong wait
+// fails its read, so it is reconnected. Returned func stops pinging
+func (a *WSAPI) keepAlive(conn *websocket.Conn) func() {
+	conn.SetReadLimit(int64(a.requestsConfig.MaxMessageSize))
+
+	pingPeriod := time.Second * time.Duration(a.requestsConfig.PingPeriodInSeconds)
+	pongWait := time.Second * time.Duration(a.requestsConfig.PongWaitInSeconds)
+	stop := make(chan struct{})
+	var once sync.Once
+	stopFunc := func() { once.Do(func() { close(stop) }) }
+	if pingPeriod <= 0 {
+		return stopFunc
+	}
+
+	if pongWait > 0 {
+		_ = conn.SetReadDeadline(time.Now().Add(pongWait))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(pongWait))
+		})
+	}
+
+	go func() {
+		ticker := time.NewTicker(pingPeriod)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				// write of control message is safe concurrently with other writes
+				if err := conn.WriteControl(websocket.PingMessage, nil, a.writeDeadline()); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	return stopFunc
+}
+
+// writeJSON writes message which has to be written within write wait
+func (a *WSAPI) writeJSON(conn *websocket.Conn, v interface{}) error {
+	if err := conn.SetWriteDeadline(a.writeDeadline()); err != nil {
+		return err
+	}
+	return conn.WriteJSON(v)
+}
+
+// writeDeadline is zero, so it does not limit writes, when write wait is not configured
+func (a *WSAPI) writeDeadline() time.Time {
+	if a.requestsConfig.WriteWaitInSeconds <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(time.Second * time.Duration(a.requestsConfig.WriteWaitInSeconds))
+}
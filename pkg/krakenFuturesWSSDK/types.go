@@ -1,6 +1,141 @@
 package krakenFuturesWSSDK
 
-const OneMinuteCandlesFeed = "candles_trade_1m"
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+)
+
+var ErrUnknownCandlesFeed = errors.New("unknown candles feed")
+
+// price sources of candles, trade candles are built from trades of product, mark ones from its mark price
+// and spot ones from index price of its underlying
+const (
+	TradePriceSource = "trade"
+	MarkPriceSource  = "mark"
+	SpotPriceSource  = "spot"
+)
+
+// resolutions of candles
+const (
+	OneMinuteResolution      = "1m"
+	FiveMinutesResolution    = "5m"
+	FifteenMinutesResolution = "15m"
+	ThirtyMinutesResolution  = "30m"
+	OneHourResolution        = "1h"
+	FourHoursResolution      = "4h"
+	TwelveHoursResolution    = "12h"
+	OneDayResolution         = "1d"
+	OneWeekResolution        = "1w"
+)
+
+// candles feeds of every price source and resolution
+const (
+	OneMinuteCandlesFeed      = "candles_trade_1m"
+	FiveMinutesCandlesFeed    = "candles_trade_5m"
+	FifteenMinutesCandlesFeed = "candles_trade_15m"
+	ThirtyMinutesCandlesFeed  = "candles_trade_30m"
+	OneHourCandlesFeed        = "candles_trade_1h"
+	FourHoursCandlesFeed      = "candles_trade_4h"
+	TwelveHoursCandlesFeed    = "candles_trade_12h"
+	OneDayCandlesFeed         = "candles_trade_1d"
+	OneWeekCandlesFeed        = "candles_trade_1w"
+
+	OneMinuteMarkCandlesFeed      = "candles_mark_1m"
+	FiveMinutesMarkCandlesFeed    = "candles_mark_5m"
+	FifteenMinutesMarkCandlesFeed = "candles_mark_15m"
+	ThirtyMinutesMarkCandlesFeed  = "candles_mark_30m"
+	OneHourMarkCandlesFeed        = "candles_mark_1h"
+	FourHoursMarkCandlesFeed      = "candles_mark_4h"
+	TwelveHoursMarkCandlesFeed    = "candles_mark_12h"
+	OneDayMarkCandlesFeed         = "candles_mark_1d"
+	OneWeekMarkCandlesFeed        = "candles_mark_1w"
+
+	OneMinuteSpotCandlesFeed      = "candles_spot_1m"
+	FiveMinutesSpotCandlesFeed    = "candles_spot_5m"
+	FifteenMinutesSpotCandlesFeed = "candles_spot_15m"
+	ThirtyMinutesSpotCandlesFeed  = "candles_spot_30m"
+	OneHourSpotCandlesFeed        = "candles_spot_1h"
+	FourHoursSpotCandlesFeed      = "candles_spot_4h"
+	TwelveHoursSpotCandlesFeed    = "candles_spot_12h"
+	OneDaySpotCandlesFeed         = "candles_spot_1d"
+	OneWeekSpotCandlesFeed        = "candles_spot_1w"
+
+	candlesFeedPrefix = "candles_"
+)
+
+// public feeds, snapshot of book and trade feeds is sent with _snapshot suffix
+const (
+	BookFeed       = "book"
+	TradeFeed      = "trade"
+	TickerFeed     = "ticker"
+	TickerLiteFeed = "ticker_lite"
+)
+
+var (
+	PriceSources = []string{TradePriceSource, MarkPriceSource, SpotPriceSource}
+	Resolutions  = []string{OneMinuteResolution, FiveMinutesResolution, FifteenMinutesResolution, ThirtyMinutesResolution,
+		OneHourResolution, FourHoursResolution, TwelveHoursResolution, OneDayResolution, OneWeekResolution}
+)
+
+var resolutionDurations = map[string]time.Duration{
+	OneMinuteResolution:      time.Minute,
+	FiveMinutesResolution:    5 * time.Minute,
+	FifteenMinutesResolution: 15 * time.Minute,
+	ThirtyMinutesResolution:  30 * time.Minute,
+	OneHourResolution:        time.Hour,
+	FourHoursResolution:      4 * time.Hour,
+	TwelveHoursResolution:    12 * time.Hour,
+	OneDayResolution:         24 * time.Hour,
+	OneWeekResolution:        7 * 24 * time.Hour,
+}
+
+// ResolutionDuration returns period of candles of resolution, ok is false when resolution is unknown
+func ResolutionDuration(resolution string) (period time.Duration, ok bool) {
+	period, ok = resolutionDurations[resolution]
+	return period, ok
+}
+
+// CandlesFeed returns feed of candles of price source and resolution, e.g. candles_mark_5m
+func CandlesFeed(priceSource, resolution string) string {
+	return candlesFeedPrefix + priceSource + "_" + resolution
+}
+
+// ParseCandlesFeed splits feed like candles_trade_1m to its price source and resolution
+func ParseCandlesFeed(feed string) (priceSource, resolution string, err error) {
+	parts := strings.SplitN(strings.TrimPrefix(feed, candlesFeedPrefix), "_", 2)
+	if !strings.HasPrefix(feed, candlesFeedPrefix) || len(parts) != 2 || !contains(PriceSources, parts[0]) ||
+		!contains(Resolutions, parts[1]) {
+		return "", "", fmt.Errorf("%w: %s", ErrUnknownCandlesFeed, feed)
+	}
+	return parts[0], parts[1], nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// private feeds, snapshot of feed is sent with _snapshot suffix
+const (
+	OpenOrdersFeed                = "open_orders"
+	FillsFeed                     = "fills"
+	OpenPositionsFeed             = "open_positions"
+	AccountBalancesAndMarginsFeed = "account_balances_and_margins"
+	NotificationsAuthFeed         = "notifications_auth"
+
+	snapshotSuffix = "_snapshot"
+)
+
+// FillTypeLiquidation is type of fill which closed position of account because of insufficient margin
+const FillTypeLiquidation = "liquidation"
 
 // -------------------------- PUBLIC KRAKEN WEBSOCKET API DATA -------------------------- //
 
@@ -8,6 +143,10 @@ type KrakenSendMessageArguments struct {
 	Event      string   `json:"event"`
 	Feed       string   `json:"feed"`
 	ProductIDs []string `json:"product_ids"`
+	// fields of subscription to private feed
+	APIKey            string `json:"api_key,omitempty"`
+	OriginalChallenge string `json:"original_challenge,omitempty"`
+	SignedChallenge   string `json:"signed_challenge,omitempty"`
 }
 
 type KrakenSendMessageResponse struct {
@@ -22,19 +161,264 @@ type HeartbeatSubscriptionData struct {
 	Time int    `json:"time"`
 }
 
+// CandlesTradeData is candle of any candles feed, trade, mark or spot one. Snapshot of the last candles
+// is sent on subscription
 type CandlesTradeData struct {
+	Feed      string   `json:"feed"`
+	Candle    Candle   `json:"candle,omitempty"`
+	Candles   []Candle `json:"candles,omitempty"`
+	ProductID string   `json:"product_id"`
+}
+
+func (d CandlesTradeData) IsSnapshot() bool {
+	return strings.HasSuffix(d.Feed, snapshotSuffix)
+}
+
+// TradeData is snapshot of recent trades of product or the new trade
+type TradeData struct {
+	Feed      string `json:"feed"`
+	ProductID string `json:"product_id"`
+	// Trades are sent in snapshot, new trade is sent in the fields of embedded Trade
+	Trades []Trade `json:"trades,omitempty"`
+	Trade
+}
+
+func (d TradeData) IsSnapshot() bool {
+	return d.Feed == TradeFeed+snapshotSuffix
+}
+
+type Trade struct {
+	UID   string          `json:"uid"`
+	Side  string          `json:"side"`
+	Type  string          `json:"type"`
+	Seq   int64           `json:"seq"`
+	Time  int64           `json:"time"`
+	Qty   decimal.Decimal `json:"qty"`
+	Price decimal.Decimal `json:"price"`
+}
+
+// TickerData is sent on every change of ticker of product, but not more often than once per second
+type TickerData struct {
+	Feed                  string          `json:"feed"`
+	ProductID             string          `json:"product_id"`
+	Time                  int64           `json:"time"`
+	Bid                   decimal.Decimal `json:"bid"`
+	Ask                   decimal.Decimal `json:"ask"`
+	BidSize               decimal.Decimal `json:"bid_size"`
+	AskSize               decimal.Decimal `json:"ask_size"`
+	Last                  decimal.Decimal `json:"last"`
+	MarkPrice             decimal.Decimal `json:"markPrice"`
+	Index                 decimal.Decimal `json:"index"`
+	Volume                decimal.Decimal `json:"volume"`
+	VolumeQuote           decimal.Decimal `json:"volumeQuote"`
+	OpenInterest          decimal.Decimal `json:"openInterest"`
+	Change                decimal.Decimal `json:"change"`
+	Premium               decimal.Decimal `json:"premium"`
+	FundingRate           decimal.Decimal `json:"funding_rate,omitempty"`
+	FundingRatePrediction decimal.Decimal `json:"funding_rate_prediction,omitempty"`
+	RelativeFundingRate   decimal.Decimal `json:"relative_funding_rate,omitempty"`
+	NextFundingRateTime   int64           `json:"next_funding_rate_time,omitempty"`
+	Leverage              string          `json:"leverage"`
+	Tag                   string          `json:"tag"`
+	Pair                  string          `json:"pair"`
+	DTM                   int64           `json:"dtm"`
+	MaturityTime          int64           `json:"maturityTime"`
+	Suspended             bool            `json:"suspended"`
+	PostOnly              bool            `json:"post_only"`
+}
+
+// TickerLiteData is shorter ticker of product
+type TickerLiteData struct {
+	Feed         string          `json:"feed"`
+	ProductID    string          `json:"product_id"`
+	Bid          decimal.Decimal `json:"bid"`
+	Ask          decimal.Decimal `json:"ask"`
+	Change       decimal.Decimal `json:"change"`
+	Premium      decimal.Decimal `json:"premium"`
+	Volume       decimal.Decimal `json:"volume"`
+	VolumeQuote  decimal.Decimal `json:"volumeQuote"`
+	Tag          string          `json:"tag"`
+	Pair         string          `json:"pair"`
+	DTM          int64           `json:"dtm"`
+	MaturityTime int64           `json:"maturityTime"`
+}
+
+// BookData is snapshot of order book of product or change of one price level. Level with zero Qty is removed
+type BookData struct {
 	Feed      string `json:"feed"`
-	Candle    Candle `json:"candle,omitempty"`
 	ProductID string `json:"product_id"`
+	// Seq of product is incremented by one with every message, so missed message is detected by the gap
+	Seq       int64       `json:"seq"`
+	Timestamp int64       `json:"timestamp"`
+	Bids      []BookLevel `json:"bids,omitempty"`
+	Asks      []BookLevel `json:"asks,omitempty"`
+	// Side, Price and Qty are sent in change of level, side is buy for bids and sell for asks
+	Side  string          `json:"side,omitempty"`
+	Price decimal.Decimal `json:"price"`
+	Qty   decimal.Decimal `json:"qty"`
+}
+
+func (d BookData) IsSnapshot() bool {
+	return d.Feed == BookFeed+snapshotSuffix
+}
+
+type BookLevel struct {
+	Price decimal.Decimal `json:"price"`
+	Qty   decimal.Decimal `json:"qty"`
+}
+
+// -------------------------------------------------------------------------------------- //
+
+// -------------------------- PRIVATE KRAKEN WEBSOCKET API DATA -------------------------- //
+
+type challengeRequest struct {
+	Event  string `json:"event"`
+	APIKey string `json:"api_key"`
+}
+
+// OpenOrdersData is snapshot of open orders or change of one of them
+type OpenOrdersData struct {
+	Feed    string `json:"feed"`
+	Account string `json:"account,omitempty"`
+	// Orders are sent in snapshot
+	Orders []OpenOrder `json:"orders,omitempty"`
+	// Order is sent when order is placed or changed, only OrderID is sent when it is cancelled or filled
+	Order    *OpenOrder `json:"order,omitempty"`
+	OrderID  string     `json:"order_id,omitempty"`
+	IsCancel bool       `json:"is_cancel,omitempty"`
+	Reason   string     `json:"reason,omitempty"`
+}
+
+func (d OpenOrdersData) IsSnapshot() bool {
+	return d.Feed == OpenOrdersFeed+snapshotSuffix
+}
+
+type OpenOrder struct {
+	Instrument     string          `json:"instrument"`
+	Time           int64           `json:"time"`
+	LastUpdateTime int64           `json:"last_update_time"`
+	Qty            decimal.Decimal `json:"qty"`
+	Filled         decimal.Decimal `json:"filled"`
+	LimitPrice     decimal.Decimal `json:"limit_price"`
+	StopPrice      decimal.Decimal `json:"stop_price"`
+	Type           string          `json:"type"`
+	OrderID        string          `json:"order_id"`
+	CliOrdID       string          `json:"cli_ord_id,omitempty"`
+	// Direction is 0 for buy order and 1 for sell one
+	Direction     int    `json:"direction"`
+	ReduceOnly    bool   `json:"reduce_only"`
+	TriggerSignal string `json:"triggerSignal,omitempty"`
+}
+
+func (o OpenOrder) Side() string {
+	if o.Direction == 0 {
+		return "buy"
+	}
+	return "sell"
+}
+
+// FillsData is snapshot of recent fills or the new ones
+type FillsData struct {
+	Feed     string `json:"feed"`
+	Account  string `json:"account,omitempty"`
+	Username string `json:"username,omitempty"`
+	Fills    []Fill `json:"fills"`
+}
+
+func (d FillsData) IsSnapshot() bool {
+	return d.Feed == FillsFeed+snapshotSuffix
+}
+
+type Fill struct {
+	Instrument     string          `json:"instrument"`
+	Time           int64           `json:"time"`
+	Price          decimal.Decimal `json:"price"`
+	Seq            int64           `json:"seq"`
+	Buy            bool            `json:"buy"`
+	Qty            decimal.Decimal `json:"qty"`
+	OrderID        string          `json:"order_id"`
+	CliOrdID       string          `json:"cli_ord_id,omitempty"`
+	FillID         string          `json:"fill_id"`
+	FillType       string          `json:"fill_type"`
+	FeePaid        decimal.Decimal `json:"fee_paid"`
+	FeeCurrency    string          `json:"fee_currency"`
+	TakerOrderType string          `json:"taker_order_type,omitempty"`
+	OrderType      string          `json:"order_type"`
+}
+
+func (f Fill) IsLiquidation() bool {
+	return f.FillType == FillTypeLiquidation
+}
+
+// OpenPositionsData has all open positions of account, it is sent on subscription and on every change
+type OpenPositionsData struct {
+	Feed      string         `json:"feed"`
+	Account   string         `json:"account,omitempty"`
+	Positions []OpenPosition `json:"positions"`
+	Seq       int64          `json:"seq,omitempty"`
+	Timestamp int64          `json:"timestamp,omitempty"`
+}
+
+type OpenPosition struct {
+	Instrument string `json:"instrument"`
+	// Balance is size of position, negative for short one
+	Balance              decimal.Decimal `json:"balance"`
+	EntryPrice           decimal.Decimal `json:"entry_price"`
+	MarkPrice            decimal.Decimal `json:"mark_price"`
+	IndexPrice           decimal.Decimal `json:"index_price"`
+	PnL                  decimal.Decimal `json:"pnl"`
+	LiquidationThreshold decimal.Decimal `json:"liquidation_threshold"`
+	ReturnOnEquity       decimal.Decimal `json:"return_on_equity"`
+	EffectiveLeverage    decimal.Decimal `json:"effective_leverage"`
+	UnrealizedFunding    decimal.Decimal `json:"unrealized_funding,omitempty"`
+	InitialMargin        decimal.Decimal `json:"initial_margin,omitempty"`
+	MaintenanceMargin    decimal.Decimal `json:"maintenance_margin,omitempty"`
+}
+
+type AccountBalancesAndMarginsData struct {
+	Feed           string          `json:"feed"`
+	Account        string          `json:"account,omitempty"`
+	MarginAccounts []MarginAccount `json:"margin_accounts"`
+	Seq            int64           `json:"seq,omitempty"`
+}
+
+func (d AccountBalancesAndMarginsData) IsSnapshot() bool {
+	return d.Feed == AccountBalancesAndMarginsFeed+snapshotSuffix
+}
+
+// MarginAccount has balances of margin account in its currency
+type MarginAccount struct {
+	Name    string          `json:"name"`
+	PV      decimal.Decimal `json:"pv"`
+	Balance decimal.Decimal `json:"balance"`
+	Funding decimal.Decimal `json:"funding"`
+	// MM, IM and AM are maintenance, initial and available margin
+	MM  decimal.Decimal `json:"mm"`
+	PnL decimal.Decimal `json:"pnl"`
+	IM  decimal.Decimal `json:"im"`
+	AM  decimal.Decimal `json:"am"`
+}
+
+type NotificationsAuthData struct {
+	Feed          string         `json:"feed"`
+	Notifications []Notification `json:"notifications"`
+}
+
+type Notification struct {
+	ID            int64  `json:"id"`
+	Type          string `json:"type"`
+	Priority      string `json:"priority"`
+	Note          string `json:"note"`
+	EffectiveTime int64  `json:"effective_time"`
 }
 
 // -------------------------------------------------------------------------------------- //
 
 type Candle struct {
-	Time   int    `json:"time"`
-	Open   string `json:"open"`
-	High   string `json:"high"`
-	Low    string `json:"low"`
-	Close  string `json:"close"`
-	Volume int    `json:"volume"`
+	Time   int             `json:"time"`
+	Open   decimal.Decimal `json:"open"`
+	High   decimal.Decimal `json:"high"`
+	Low    decimal.Decimal `json:"low"`
+	Close  decimal.Decimal `json:"close"`
+	Volume decimal.Decimal `json:"volume"`
 }
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/shopspring/decimal"
 	log "github.com/sirupsen/logrus"
 
 	"trade-bot/pkg/client/models"
@@ -258,8 +259,14 @@ func (b *BotMan) executeStartTrading(chatID int64, updates tgbotapi.UpdatesChann
 
 	go func(chatID int64) {
 		for val := range startTradingResp {
-			message := tgbotapi.NewMessage(chatID, fmt.Sprintf("%s\nOrder: %s", utils.StartTradingSuccessMessage, val.String()))
-			b.sendMessage(chatID, message)
+			switch {
+			case val.Message != "" || val.Session.State == models.TradingSessionFailedState:
+				message := tgbotapi.NewMessage(chatID, fmt.Sprintf("%s: %s", utils.StartTradingErrMessage, val.String()))
+				b.sendMessage(chatID, message)
+			case val.Event == models.TradingSessionExitOrderEvent:
+				message := tgbotapi.NewMessage(chatID, fmt.Sprintf("%s\nOrder: %s", utils.StartTradingSuccessMessage, val.String()))
+				b.sendMessage(chatID, message)
+			}
 		}
 	}(chatID)
 
@@ -283,8 +290,8 @@ func (b *BotMan) getStartTradingInput(updates tgbotapi.UpdatesChannel) (models.S
 			if inputValues[1] != "buy" && inputValues[1] != "sell" {
 				return models.StartTradingInput{}, fmt.Errorf("invalid strat trading Side argument")
 			}
-			amount, err := strconv.ParseUint(inputValues[2], 10, 64)
-			if err != nil {
+			amount, err := decimal.NewFromString(inputValues[2])
+			if err != nil || !amount.IsPositive() {
 				return models.StartTradingInput{}, fmt.Errorf("invalid start trading Size argument")
 			}
 			stopLoss, err := strconv.ParseFloat(inputValues[3], 64)
@@ -303,10 +310,13 @@ func (b *BotMan) getStartTradingInput(updates tgbotapi.UpdatesChannel) (models.S
 						OrderType: "mkt",
 						Symbol:    inputValues[0],
 						Side:      inputValues[1],
-						Size:      uint(amount),
+						Size:      amount,
+					},
+					Strategy: models.StopLossTakeProfitStrategy,
+					Params: map[string]interface{}{
+						"stop_loss_border":   stopLoss,
+						"take_profit_border": takeProfit,
 					},
-					StopLossBorder:   uint(stopLoss),
-					TakeProfitBorder: uint(takeProfit),
 				},
 			}, nil
 		}
@@ -347,15 +357,15 @@ func (b *BotMan) getSendOrderInput(updates tgbotapi.UpdatesChannel) (models.Send
 			if inputValues[1] != "buy" && inputValues[1] != "sell" {
 				return models.SendOrderInput{}, fmt.Errorf("invalid send order Side argument")
 			}
-			amount, err := strconv.ParseUint(inputValues[2], 10, 64)
-			if err != nil {
+			amount, err := decimal.NewFromString(inputValues[2])
+			if err != nil || !amount.IsPositive() {
 				return models.SendOrderInput{}, fmt.Errorf("invalid send order Size argument")
 			}
 			return models.SendOrderInput{
 				OrderType: "mkt",
 				Symbol:    inputValues[0],
 				Side:      inputValues[1],
-				Size:      uint(amount),
+				Size:      amount,
 			}, nil
 		}
 	}
@@ -0,0 +1,19 @@
+CREATE TABLE trading_sessions
+(
+    id                 varchar(255)                                not null unique,
+    user_id            int references users (id) on delete cascade not null,
+    order_type         varchar(255)                                not null,
+    symbol             varchar(255)                                not null,
+    side               varchar(255)                                not null,
+    size               integer                                     not null,
+    stop_loss_border   float8                                      not null,
+    take_profit_border float8                                      not null,
+    entry_order_id     varchar(255)                                not null default '',
+    entry_price        float8                                      not null default 0,
+    entry_time         timestamp                                   not null,
+    exit_order_id      varchar(255)                                not null default '',
+    state              varchar(255)                                not null,
+    error              text                                        not null default '',
+    created_at         timestamp                                   not null,
+    updated_at         timestamp                                   not null
+);
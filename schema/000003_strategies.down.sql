@@ -0,0 +1,11 @@
+ALTER TABLE trading_sessions
+    ADD COLUMN stop_loss_border   float8 not null default 0,
+    ADD COLUMN take_profit_border float8 not null default 0;
+
+UPDATE trading_sessions
+SET stop_loss_border   = coalesce((params ->> 'stop_loss_border')::float8, 0),
+    take_profit_border = coalesce((params ->> 'take_profit_border')::float8, 0);
+
+ALTER TABLE trading_sessions
+    DROP COLUMN strategy,
+    DROP COLUMN params;
@@ -0,0 +1,10 @@
+ALTER TABLE trading_sessions
+    ADD COLUMN strategy varchar(255) not null default 'stop_loss_take_profit',
+    ADD COLUMN params   jsonb        not null default '{}';
+
+UPDATE trading_sessions
+SET params = jsonb_build_object('stop_loss_border', stop_loss_border, 'take_profit_border', take_profit_border);
+
+ALTER TABLE trading_sessions
+    DROP COLUMN stop_loss_border,
+    DROP COLUMN take_profit_border;
@@ -0,0 +1,5 @@
+ALTER TABLE trading_sessions
+    DROP COLUMN paper;
+
+ALTER TABLE orders
+    DROP COLUMN paper;
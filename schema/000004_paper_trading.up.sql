@@ -0,0 +1,5 @@
+ALTER TABLE orders
+    ADD COLUMN paper boolean not null default false;
+
+ALTER TABLE trading_sessions
+    ADD COLUMN paper boolean not null default false;
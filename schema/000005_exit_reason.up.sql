@@ -0,0 +1,2 @@
+ALTER TABLE orders
+    ADD COLUMN exit_reason varchar(255) not null default '';
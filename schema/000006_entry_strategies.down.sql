@@ -0,0 +1,4 @@
+ALTER TABLE trading_sessions
+    DROP COLUMN entry_strategy,
+    DROP COLUMN entry_params,
+    DROP COLUMN round_trips;
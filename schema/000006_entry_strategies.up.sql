@@ -0,0 +1,4 @@
+ALTER TABLE trading_sessions
+    ADD COLUMN entry_strategy varchar(255) not null default '',
+    ADD COLUMN entry_params   jsonb        not null default '{}',
+    ADD COLUMN round_trips    int          not null default 0;
@@ -0,0 +1,8 @@
+ALTER TABLE orders
+    ALTER COLUMN quantity TYPE float8,
+    ALTER COLUMN filled TYPE float8,
+    ALTER COLUMN price TYPE float8;
+
+ALTER TABLE trading_sessions
+    ALTER COLUMN size TYPE integer USING round(size),
+    ALTER COLUMN entry_price TYPE float8;
@@ -0,0 +1,8 @@
+ALTER TABLE orders
+    ALTER COLUMN quantity TYPE numeric,
+    ALTER COLUMN filled TYPE numeric,
+    ALTER COLUMN price TYPE numeric;
+
+ALTER TABLE trading_sessions
+    ALTER COLUMN size TYPE numeric,
+    ALTER COLUMN entry_price TYPE numeric;